@@ -0,0 +1,203 @@
+package events
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dokku/dokku/plugins/common"
+)
+
+// maxEventRecords is the number of events kept in the event audit log
+const maxEventRecords = 10000
+
+var (
+	// DefaultProperties is a map of all valid events properties with corresponding default property values
+	DefaultProperties = map[string]string{
+		"webhook-url": "",
+	}
+
+	// GlobalProperties is a map of all valid global events properties
+	GlobalProperties = map[string]bool{
+		"webhook-url": true,
+	}
+)
+
+// Event is a single entry in the event audit log
+type Event struct {
+	// ID uniquely identifies the event
+	ID string `json:"id"`
+
+	// Name is the name of the event, such as app.created or deploy.succeeded
+	Name string `json:"name"`
+
+	// App is the app the event occurred for, if any
+	App string `json:"app,omitempty"`
+
+	// Actor is the ssh key name or user that caused the event
+	Actor string `json:"actor"`
+
+	// Timestamp is the time the event occurred
+	Timestamp time.Time `json:"timestamp"`
+
+	// Params contains additional details about the event
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// EventQuery filters the events read from the event audit log
+type EventQuery struct {
+	// App only matches events for this app
+	App string
+
+	// Since only matches events that occurred at or after this time
+	Since time.Time
+}
+
+// Matches returns whether an event matches the query
+func (q EventQuery) Matches(event Event) bool {
+	if q.App != "" && event.App != q.App {
+		return false
+	}
+
+	if !q.Since.IsZero() && event.Timestamp.Before(q.Since) {
+		return false
+	}
+
+	return true
+}
+
+// NewEventQuery creates a query from the flag values passed to events:list
+func NewEventQuery(appName string, since string) (EventQuery, error) {
+	query := EventQuery{App: appName}
+	if since == "" {
+		return query, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		query.Since = t
+		return query, nil
+	}
+
+	now := time.Now()
+	if days, found := strings.CutSuffix(since, "d"); found {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			query.Since = now.AddDate(0, 0, -n)
+			return query, nil
+		}
+	}
+
+	duration, err := time.ParseDuration(since)
+	if err != nil || duration < 0 {
+		return query, fmt.Errorf("Invalid --since value, must be an RFC3339 timestamp or a duration such as 30m, 12h or 7d: %s", since)
+	}
+
+	query.Since = now.Add(-duration)
+	return query, nil
+}
+
+// isEventLoggerEnabled returns whether the events logger has been enabled via events:on
+func isEventLoggerEnabled() bool {
+	return os.Getenv("DOKKU_EVENTS") != ""
+}
+
+// getEventLogPath returns the path to the event audit log
+func getEventLogPath() string {
+	return filepath.Join(common.GetDataDirectory("events"), "events.jsonl")
+}
+
+// getActor returns the ssh key name or user running the current command
+func getActor() string {
+	if name := os.Getenv("NAME"); name != "" {
+		return name
+	}
+
+	if user := os.Getenv("SSH_USER"); user != "" {
+		return user
+	}
+
+	return os.Getenv("USER")
+}
+
+// newEventID returns a unique id for an event
+func newEventID(timestamp time.Time) string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(timestamp.UnixNano(), 10)
+	}
+
+	return fmt.Sprintf("%d-%s", timestamp.UnixNano(), hex.EncodeToString(b))
+}
+
+// RecordEvent appends an event to the event audit log when the events logger is enabled,
+// and queues it for delivery when a webhook is configured
+func RecordEvent(name string, appName string, params map[string]string) error {
+	recordEnabled := isEventLoggerEnabled()
+	webhookURL := getWebhookURL()
+	if !recordEnabled && webhookURL == "" {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	event := Event{
+		ID:        newEventID(now),
+		Name:      name,
+		App:       appName,
+		Actor:     getActor(),
+		Timestamp: now,
+		Params:    params,
+	}
+
+	if err := common.CreateDataDirectory("events"); err != nil {
+		return fmt.Errorf("Unable to create events data directory: %w", err)
+	}
+
+	if recordEnabled {
+		if err := common.AppendJSONLine(getEventLogPath(), event, maxEventRecords); err != nil {
+			return fmt.Errorf("Unable to write event: %w", err)
+		}
+	}
+
+	if webhookURL != "" {
+		if err := queueEvent(webhookURL, event); err != nil {
+			return fmt.Errorf("Unable to queue event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// FetchEvents returns the events in the event audit log that match the query, oldest first
+func FetchEvents(query EventQuery) ([]Event, error) {
+	events := []Event{}
+	f, err := os.Open(getEventLogPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return events, nil
+	}
+	if err != nil {
+		return events, fmt.Errorf("Unable to open event log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+
+		if query.Matches(event) {
+			events = append(events, event)
+		}
+	}
+
+	return events, scanner.Err()
+}
@@ -0,0 +1,85 @@
+package events
+
+import (
+	"strings"
+)
+
+// RecordedTriggers maps each plugin trigger that is recorded in the event audit log to the name of its event
+var RecordedTriggers = map[string]string{
+	"deploy-failed":       "deploy.failed",
+	"post-app-clone":      "app.cloned",
+	"post-app-rename":     "app.renamed",
+	"post-certs-remove":   "certs.removed",
+	"post-certs-update":   "certs.updated",
+	"post-config-update":  "config.updated",
+	"post-create":         "app.created",
+	"post-delete":         "app.destroyed",
+	"post-deploy":         "deploy.succeeded",
+	"post-domains-update": "domains.updated",
+	"post-ps-scale":       "ps.scaled",
+	"proxy-build-config":  "proxy.rebuilt",
+	"scheduler-deploy":    "deploy.started",
+}
+
+// arg returns the argument at the specified index, or an empty string if there is none
+func arg(args []string, index int) string {
+	if index < len(args) {
+		return args[index]
+	}
+
+	return ""
+}
+
+// RecordTrigger records the event for a plugin trigger invocation in the event audit log
+func RecordTrigger(trigger string, args []string) error {
+	name, ok := RecordedTriggers[trigger]
+	if !ok {
+		return nil
+	}
+
+	appName := arg(args, 0)
+	params := map[string]string{}
+	switch trigger {
+	case "deploy-failed":
+		params["image_tag"] = arg(args, 1)
+		params["phase"] = arg(args, 2)
+	case "post-app-clone":
+		appName = arg(args, 1)
+		params["source_app"] = arg(args, 0)
+	case "post-app-rename":
+		appName = arg(args, 1)
+		params["old_app"] = arg(args, 0)
+	case "post-config-update":
+		// only the keys are passed to the trigger, so values are never recorded
+		params["action"] = arg(args, 1)
+		if len(args) > 2 {
+			params["keys"] = strings.Join(args[2:], ",")
+		}
+	case "post-delete":
+		params["image_tag"] = arg(args, 1)
+	case "post-deploy":
+		params["image_tag"] = arg(args, 3)
+	case "post-domains-update":
+		params["action"] = arg(args, 1)
+		if len(args) > 2 {
+			params["domains"] = strings.Join(args[2:], ",")
+		}
+	case "post-ps-scale":
+		if len(args) > 1 {
+			params["formation"] = strings.Join(args[1:], ",")
+		}
+	case "scheduler-deploy":
+		appName = arg(args, 1)
+		params["scheduler"] = arg(args, 0)
+		params["image_tag"] = arg(args, 2)
+		params["process_type"] = arg(args, 3)
+	}
+
+	for key, value := range params {
+		if value == "" {
+			delete(params, key)
+		}
+	}
+
+	return RecordEvent(name, appName, params)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	events "github.com/dokku/dokku/plugins/20_events"
+	"github.com/dokku/dokku/plugins/common"
+
+	flag "github.com/spf13/pflag"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		common.LogFail("No command specified")
+	}
+
+	var err error
+	cmd := os.Args[1]
+	switch cmd {
+	case "deliver":
+		err = events.CommandDeliver()
+	case "list":
+		args := flag.NewFlagSet("events:list", flag.ExitOnError)
+		appName := args.String("app", "", "--app: only display events for the given app")
+		since := args.String("since", "", "--since: only display events that occurred at or after this time")
+		format := args.String("format", "stdout", "--format: [ stdout | json ]")
+		args.Parse(os.Args[2:])
+		err = events.CommandList(*appName, *since, *format)
+	case "record":
+		// failing to record an event must not fail the plugin trigger that caused it
+		if len(os.Args) > 2 {
+			if err := events.RecordTrigger(os.Args[2], os.Args[3:]); err != nil {
+				common.LogWarn(fmt.Sprintf("Unable to record event: %s", err.Error()))
+			}
+		}
+	case "set":
+		args := flag.NewFlagSet("events:set", flag.ExitOnError)
+		global := args.Bool("global", false, "--global: set a global property")
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		property := args.Arg(1)
+		value := args.Arg(2)
+		if *global {
+			appName = "--global"
+			property = args.Arg(0)
+			value = args.Arg(1)
+		}
+		err = events.CommandSet(appName, property, value)
+	default:
+		err = fmt.Errorf("Invalid events command call: %s", cmd)
+	}
+
+	if err != nil {
+		common.LogFailWithError(err)
+	}
+}
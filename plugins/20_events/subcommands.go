@@ -0,0 +1,122 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dokku/dokku/plugins/common"
+	"github.com/ryanuber/columnize"
+)
+
+// CommandDeliver delivers queued events to the webhook
+func CommandDeliver() error {
+	if getWebhookURL() == "" {
+		common.LogWarn("No webhook-url is set, skipping delivery of queued events")
+		return nil
+	}
+
+	return DeliverQueuedEvents()
+}
+
+// CommandList displays the events in the event audit log
+func CommandList(appName string, since string, format string) error {
+	if format == "" {
+		format = "stdout"
+	}
+
+	if format != "stdout" && format != "json" {
+		return fmt.Errorf("Invalid format specified, supported formats: json, stdout")
+	}
+
+	query, err := NewEventQuery(appName, since)
+	if err != nil {
+		return err
+	}
+
+	events, err := FetchEvents(query)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		b, err := json.Marshal(events)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(b))
+		return nil
+	}
+
+	output := []string{"Timestamp | Event | App | Actor | Params"}
+	for _, event := range events {
+		output = append(output, fmt.Sprintf("%s | %s | %s | %s | %s",
+			event.Timestamp.Local().Format(time.RFC3339),
+			event.Name,
+			event.App,
+			event.Actor,
+			formatParams(event.Params),
+		))
+	}
+
+	result := columnize.SimpleFormat(output)
+	fmt.Println(result)
+	return nil
+}
+
+// CommandSet sets or clears an events property
+func CommandSet(appName string, property string, value string) error {
+	if appName != "--global" {
+		return errors.New("Events properties can only be set globally via --global")
+	}
+
+	if property == "webhook-url" && value != "" {
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("Invalid webhook-url, must be an http or https url: %s", value)
+		}
+	}
+
+	common.CommandPropertySet("events", appName, property, value, DefaultProperties, GlobalProperties)
+
+	if property == "webhook-url" {
+		// queued events are posted to the url set when they were recorded
+		if value == "" {
+			if err := clearQueuedEvents(); err != nil {
+				return err
+			}
+		}
+
+		// queued events are delivered by an injected cron task
+		if _, err := common.CallPlugnTrigger(common.PlugnTriggerInput{
+			Trigger:     "scheduler-cron-write",
+			Args:        []string{"docker-local"},
+			StreamStdio: true,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// formatParams renders event params as sorted key=value pairs
+func formatParams(params map[string]string) string {
+	keys := []string{}
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := []string{}
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, params[key]))
+	}
+
+	return strings.Join(pairs, " ")
+}
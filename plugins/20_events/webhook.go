@@ -0,0 +1,53 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/dokku/dokku/plugins/common"
+)
+
+func getWebhookURL() string {
+	return common.PropertyGet("events", "--global", "webhook-url")
+}
+
+// getDeliveryQueue returns the queue of events waiting to be delivered to the webhook
+func getDeliveryQueue() common.DeliveryQueue {
+	return common.DeliveryQueue{
+		Directory: filepath.Join(common.GetDataDirectory("events"), "queue"),
+		// events are delivered in order, so later events wait for earlier ones
+		Ordered: true,
+	}
+}
+
+// queueEvent queues an event for delivery to the webhook by the events:deliver cron task
+func queueEvent(url string, event Event) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return getDeliveryQueue().Enqueue(common.QueuedDelivery{
+		ID:          event.ID,
+		Description: fmt.Sprintf("%s event %s", event.Name, event.ID),
+		Request: common.DeliveryRequest{
+			URL:     url,
+			Headers: map[string]string{"User-Agent": "dokku-events"},
+			Body:    b,
+		},
+		NextAttemptAt: event.Timestamp,
+	})
+}
+
+// DeliverQueuedEvents delivers queued events to the webhook in order, stopping at the first failure
+func DeliverQueuedEvents() error {
+	return getDeliveryQueue().Flush()
+}
+
+// clearQueuedEvents removes all events waiting to be delivered
+func clearQueuedEvents() error {
+	return getDeliveryQueue().Remove(func(delivery common.QueuedDelivery) bool {
+		return true
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/dokku/dokku/plugins/common"
@@ -15,20 +16,33 @@ import (
 var (
 	// DefaultProperties is a map of all valid app-json properties with corresponding default property values
 	DefaultProperties = map[string]string{
-		"appjson-path": "",
+		"appjson-path":   "",
+		"script-retries": "",
+		"script-timeout": "",
 	}
 
 	// GlobalProperties is a map of all valid global app-json properties
 	GlobalProperties = map[string]bool{
-		"appjson-path": true,
+		"appjson-path":   true,
+		"script-retries": true,
+		"script-timeout": true,
 	}
 )
 
 // AppJSON is a struct that represents an app.json file as understood by Dokku
 type AppJSON struct {
+	// Addons is a list of addons the app depends on
+	Addons []Addon `json:"addons,omitempty"`
+
+	// Buildpacks is a list of buildpacks to build the app with
+	Buildpacks []Buildpack `json:"buildpacks,omitempty"`
+
 	// Cron is a list of cron tasks to execute
 	Cron []CronTask `json:"cron"`
 
+	// Env is a map of environment variables to seed the app config with
+	Env map[string]EnvVar `json:"env,omitempty"`
+
 	// Formation is a map of process types to scale
 	Formation map[string]Formation `json:"formation"`
 
@@ -39,11 +53,20 @@ type AppJSON struct {
 	Scripts struct {
 		// Dokku is a map of scripts to execute for Dokku-specific events
 		Dokku struct {
-			// Predeploy is a script to execute before a deploy
-			Predeploy string `json:"predeploy"`
+			// Prebuild is a list of scripts to execute before an app is built
+			Prebuild DeployScripts `json:"prebuild,omitempty"`
+
+			// Postbuild is a list of scripts to execute after an app is built
+			Postbuild DeployScripts `json:"postbuild,omitempty"`
+
+			// Prerelease is a list of scripts to execute before an app is released
+			Prerelease DeployScripts `json:"prerelease,omitempty"`
 
-			// Postdeploy is a script to execute after a deploy
-			Postdeploy string `json:"postdeploy"`
+			// Predeploy is a list of scripts to execute before a deploy
+			Predeploy DeployScripts `json:"predeploy,omitempty"`
+
+			// Postdeploy is a list of scripts to execute after a deploy
+			Postdeploy DeployScripts `json:"postdeploy,omitempty"`
 		} `json:"dokku"`
 
 		// Postdeploy is a script to execute after a deploy
@@ -51,6 +74,42 @@ type AppJSON struct {
 	} `json:"scripts"`
 }
 
+// Addon is a struct that represents a single addon from an app.json file
+type Addon struct {
+	// Plan is the addon plan, in the form service:plan
+	Plan string `json:"plan"`
+
+	// As is the name of the addon attachment
+	As string `json:"as,omitempty"`
+
+	// Options is a map of provider-specific options for the addon
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// UnmarshalJSON decodes an addon from either a plan string or an object
+func (a *Addon) UnmarshalJSON(b []byte) error {
+	var plan string
+	if err := json.Unmarshal(b, &plan); err == nil {
+		a.Plan = plan
+		return nil
+	}
+
+	type addon Addon
+	var value addon
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+
+	*a = Addon(value)
+	return nil
+}
+
+// Buildpack is a struct that represents a single buildpack from an app.json file
+type Buildpack struct {
+	// URL is the url or reference of the buildpack
+	URL string `json:"url"`
+}
+
 // CronTask is a struct that represents a single cron task from an app.json file
 type CronTask struct {
 	// Command is the command to execute
@@ -64,6 +123,127 @@ type CronTask struct {
 
 	// ConcurrencyPolicy is the concurrency policy for the cron command
 	ConcurrencyPolicy string `json:"concurrency_policy"`
+
+	// MissedRunPolicy is what to do with runs missed while the cron daemon was not running
+	MissedRunPolicy string `json:"missed_run_policy,omitempty"`
+
+	// JitterSeconds is the maximum random delay in seconds to add before each run
+	JitterSeconds int `json:"jitter_seconds,omitempty"`
+
+	// Timezone is the timezone to evaluate the schedule in
+	Timezone string `json:"timezone,omitempty"`
+
+	// Name is an optional name other cron tasks can reference in after
+	Name string `json:"name,omitempty"`
+
+	// After is the name or id of the cron task this task runs after, instead of on a schedule
+	After string `json:"after,omitempty"`
+}
+
+// DeployScript is a struct that represents a single deployment task from an app.json file
+type DeployScript struct {
+	// Command is the command to execute
+	Command string `json:"command"`
+
+	// DockerOptionsPhase is the docker-options phase to use when creating the script container
+	DockerOptionsPhase string `json:"docker_options_phase,omitempty"`
+
+	// Name is the name of the deployment task
+	Name string `json:"name,omitempty"`
+
+	// ProcessType is the process type whose docker options are used when creating the script container
+	ProcessType string `json:"process_type,omitempty"`
+
+	// Retries is the number of times to retry the deployment task on failure, or nil to use the script-retries property
+	Retries *int `json:"retries,omitempty"`
+
+	// Timeout is the number of seconds to wait before considering the deployment task failed, or nil to use the script-timeout property
+	Timeout *int `json:"timeout,omitempty"`
+}
+
+// UnmarshalJSON decodes a deployment task from either a command string or an object
+func (d *DeployScript) UnmarshalJSON(b []byte) error {
+	var command string
+	if err := json.Unmarshal(b, &command); err == nil {
+		d.Command = command
+		return nil
+	}
+
+	type deployScript DeployScript
+	var value deployScript
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+
+	*d = DeployScript(value)
+	return nil
+}
+
+// DeployScripts is a list of deployment tasks for a single phase
+type DeployScripts []DeployScript
+
+// UnmarshalJSON decodes a list of deployment tasks from a command string, an object, or a list of either
+func (d *DeployScripts) UnmarshalJSON(b []byte) error {
+	var scripts []DeployScript
+	if err := json.Unmarshal(b, &scripts); err == nil {
+		*d = DeployScripts(scripts)
+		return nil
+	}
+
+	var script DeployScript
+	if err := json.Unmarshal(b, &script); err != nil {
+		return err
+	}
+
+	if script.Command == "" && script.Name == "" {
+		*d = DeployScripts{}
+		return nil
+	}
+
+	*d = DeployScripts{script}
+	return nil
+}
+
+// EnvVar is a struct that represents a single environment variable from an app.json file
+type EnvVar struct {
+	// Description is a human-readable description of the environment variable
+	Description string `json:"description,omitempty"`
+
+	// Generator is the name of a generator to create the value with
+	Generator string `json:"generator,omitempty"`
+
+	// Pattern is a regular expression the value must fully match when the type is regex
+	Pattern string `json:"pattern,omitempty"`
+
+	// Required is whether or not the environment variable must be set for a deploy to succeed
+	Required bool `json:"required,omitempty"`
+
+	// Type is the type of the value, used to validate the value when it is set
+	Type string `json:"type,omitempty"`
+
+	// Value is the default value of the environment variable
+	Value string `json:"value,omitempty"`
+
+	// Values are the allowed values when the type is enum
+	Values []string `json:"values,omitempty"`
+}
+
+// UnmarshalJSON decodes an environment variable from either a value string or an object
+func (e *EnvVar) UnmarshalJSON(b []byte) error {
+	var value string
+	if err := json.Unmarshal(b, &value); err == nil {
+		e.Value = value
+		return nil
+	}
+
+	type envVar EnvVar
+	var v envVar
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	*e = EnvVar(v)
+	return nil
 }
 
 // Formation is a struct that represents the scale for a process from an app.json file
@@ -77,11 +257,34 @@ type Formation struct {
 	// MaxParallel is the maximum number of processes to start in parallel
 	MaxParallel *int `json:"max_parallel"`
 
+	// MinAvailable is the number or percentage of processes that must remain available during voluntary disruptions
+	MinAvailable FormationMinAvailable `json:"min_available,omitempty"`
+
 	// Service is a struct that represents how to expose the process to the network
 	// This only applies to non-web processes
 	Service *FormationService `json:"service"`
 }
 
+// FormationMinAvailable is either an integer or a percentage of processes
+type FormationMinAvailable string
+
+// UnmarshalJSON decodes the minimum available processes from either an integer or a percentage string
+func (m *FormationMinAvailable) UnmarshalJSON(b []byte) error {
+	var quantity int
+	if err := json.Unmarshal(b, &quantity); err == nil {
+		*m = FormationMinAvailable(strconv.Itoa(quantity))
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+
+	*m = FormationMinAvailable(value)
+	return nil
+}
+
 // FormationService is a struct that represents how to expose a process to the network
 type FormationService struct {
 	// Exposed is whether or not the process is exposed as a service
@@ -0,0 +1,113 @@
+package appjson
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dokku/dokku/plugins/common"
+	"github.com/dokku/dokku/plugins/config"
+)
+
+// generateSecret returns a random 64 character hex string, matching the heroku secret generator
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("Unable to generate secret: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// seedEnv sets any missing config vars declared in the app.json env section on the first deploy of an app.
+// Required config vars are checked by the config plugin against the app's config schema.
+func seedEnv(appName string, appJSON AppJSON) error {
+	if len(appJSON.Env) == 0 || common.PropertyGet("common", appName, "deployed") == "true" {
+		return nil
+	}
+
+	keys := []string{}
+	for key := range appJSON.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	env, err := config.LoadMergedAppEnv(appName)
+	if err != nil {
+		return err
+	}
+
+	entries := map[string]string{}
+	for _, key := range keys {
+		if _, ok := env.Get(key); ok {
+			continue
+		}
+
+		envVar := appJSON.Env[key]
+		if envVar.Generator == "secret" {
+			value, err := generateSecret()
+			if err != nil {
+				return err
+			}
+
+			entries[key] = value
+			continue
+		}
+
+		if envVar.Generator != "" {
+			common.LogWarn(fmt.Sprintf("Unsupported generator for config var %s, skipping: %s", key, envVar.Generator))
+			continue
+		}
+
+		if envVar.Value != "" {
+			entries[key] = envVar.Value
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	common.LogInfo1(fmt.Sprintf("Seeding config vars from app.json for %s", appName))
+	return config.SetMany(appName, entries, false, false)
+}
+
+// getEnvSchema returns the config schema rules declared in the app.json env section.
+// Values are only seeded on the first deploy, so they are not used as schema defaults.
+func getEnvSchema(appJSON AppJSON) config.Schema {
+	schema := config.Schema{}
+	for key, envVar := range appJSON.Env {
+		if envVar.Type == "" && !envVar.Required {
+			continue
+		}
+
+		schema[key] = config.SchemaRule{
+			Pattern:  envVar.Pattern,
+			Required: envVar.Required,
+			Type:     envVar.Type,
+			Values:   envVar.Values,
+		}
+	}
+
+	return schema
+}
+
+// reportAddons warns about addons declared in the app.json that dokku does not provision
+func reportAddons(appName string, appJSON AppJSON) {
+	if len(appJSON.Addons) == 0 || common.PropertyGet("common", appName, "deployed") == "true" {
+		return
+	}
+
+	plans := []string{}
+	for _, addon := range appJSON.Addons {
+		plan := addon.Plan
+		if addon.As != "" {
+			plan = fmt.Sprintf("%s (as %s)", addon.Plan, addon.As)
+		}
+		plans = append(plans, plan)
+	}
+
+	common.LogWarn(fmt.Sprintf("Addons are not provisioned automatically, please create and link the following services manually: %s", strings.Join(plans, ", ")))
+}
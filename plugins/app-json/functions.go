@@ -1,18 +1,25 @@
 package appjson
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/dokku/dokku/plugins/common"
 	shellquote "github.com/kballard/go-shellquote"
 )
 
+// errScriptCancelled is returned when a deployment task is cancelled by a signal
+var errScriptCancelled = errors.New("Cancelled")
+
 func constructScript(command string, shell string, isHerokuishImage bool, isCnbImage bool, dockerfileEntrypoint string) []string {
 	nonSkippableEntrypoints := map[string]bool{
 		"ENTRYPOINT [\"/tini\",\"--\"]":               true,
@@ -63,6 +70,36 @@ func constructScript(command string, shell string, isHerokuishImage bool, isCnbI
 	return []string{shell, "-c", strings.Join(script, " ")}
 }
 
+// getComputedProperty returns the app value for a property, falling back to the global value
+func getComputedProperty(appName string, property string) string {
+	value := common.PropertyGet("app-json", appName, property)
+	if value == "" {
+		value = common.PropertyGet("app-json", "--global", property)
+	}
+
+	return value
+}
+
+// getScriptRetries returns the default number of retries for deployment tasks of an app
+func getScriptRetries(appName string) int {
+	retries, err := strconv.Atoi(getComputedProperty(appName, "script-retries"))
+	if err != nil || retries < 0 {
+		return 0
+	}
+
+	return retries
+}
+
+// getScriptTimeout returns the default timeout in seconds for deployment tasks of an app
+func getScriptTimeout(appName string) int {
+	timeout, err := strconv.Atoi(getComputedProperty(appName, "script-timeout"))
+	if err != nil || timeout < 0 {
+		return 0
+	}
+
+	return timeout
+}
+
 func getAppJSONPath(appName string) string {
 	directory := filepath.Join(common.MustGetEnv("DOKKU_LIB_ROOT"), "data", "app-json", appName)
 	return filepath.Join(directory, "app.json")
@@ -78,18 +115,26 @@ func getProcessSpecificAppJSONPath(appName string) string {
 	return existingAppJSON
 }
 
-// getPhaseScript extracts app.json from app image and returns the appropriate json key/value
-func getPhaseScript(appName string, phase string) (string, error) {
+// getPhaseScripts extracts app.json from app image and returns the deployment tasks for a given phase
+func getPhaseScripts(appName string, phase string) (DeployScripts, error) {
 	appJSON, err := GetAppJSON(appName)
 	if err != nil {
-		return "", err
+		return DeployScripts{}, err
 	}
 
-	if phase == "heroku.postdeploy" {
-		return appJSON.Scripts.Postdeploy, nil
-	}
-
-	if phase == "predeploy" {
+	switch phase {
+	case "heroku.postdeploy":
+		if appJSON.Scripts.Postdeploy == "" {
+			return DeployScripts{}, nil
+		}
+		return DeployScripts{{Command: appJSON.Scripts.Postdeploy}}, nil
+	case "prebuild":
+		return appJSON.Scripts.Dokku.Prebuild, nil
+	case "postbuild":
+		return appJSON.Scripts.Dokku.Postbuild, nil
+	case "prerelease":
+		return appJSON.Scripts.Dokku.Prerelease, nil
+	case "predeploy":
 		return appJSON.Scripts.Dokku.Predeploy, nil
 	}
 
@@ -120,8 +165,20 @@ func hasAppJSON(appName string) bool {
 	return common.FileExists(appJSONPath)
 }
 
+// isCommittingPhase returns whether the changes made by a deployment task in a given phase are committed to the app image
+func isCommittingPhase(phase string) bool {
+	committingPhases := map[string]bool{
+		"prebuild":   true,
+		"postbuild":  true,
+		"prerelease": true,
+		"predeploy":  true,
+	}
+
+	return committingPhases[phase]
+}
+
 func cleanupDeploymentContainer(containerID string, phase string) error {
-	if phase != "predeploy" {
+	if !isCommittingPhase(phase) {
 		os.Setenv("DOKKU_SKIP_IMAGE_RETIRE", "true")
 	}
 
@@ -132,30 +189,89 @@ func cleanupDeploymentContainer(containerID string, phase string) error {
 	return nil
 }
 
+// clearScriptResults removes the recorded results of all deployment tasks for a given phase
+func clearScriptResults(appName string, phase string) error {
+	results, err := common.PropertyGetAllByPrefix("app-json", appName, fmt.Sprintf("script-result.%s.", phase))
+	if err != nil {
+		return err
+	}
+
+	for property := range results {
+		if err := common.PropertyDelete("app-json", appName, property); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recordScriptResult stores the exit code, duration and number of attempts of a deployment task
+func recordScriptResult(appName string, phase string, name string, exitCode int, duration time.Duration, attempts int) {
+	value := fmt.Sprintf("%d %.2f %d %d", exitCode, duration.Seconds(), time.Now().Unix(), attempts)
+	if err := common.PropertyWrite("app-json", appName, fmt.Sprintf("script-result.%s.%s", phase, name), value); err != nil {
+		common.LogWarn(fmt.Sprintf("Unable to record result of %s task: %s", phase, err.Error()))
+	}
+}
+
 func executeScript(appName string, image string, imageTag string, phase string) error {
 	common.LogInfo1(fmt.Sprintf("Checking for %s task", phase))
-	command := ""
+	scripts := DeployScripts{}
 	phaseSource := ""
 	if phase == "release" {
-		command = getReleaseCommand(appName)
+		if command := getReleaseCommand(appName); command != "" {
+			scripts = DeployScripts{{Command: command}}
+		}
 		phaseSource = "Procfile"
 	} else {
 		var err error
 		phaseSource = "app.json"
-		if command, err = getPhaseScript(appName, phase); err != nil {
+		if scripts, err = getPhaseScripts(appName, phase); err != nil {
 			common.LogExclaim(err.Error())
 		}
 	}
 
-	if command == "" {
+	if err := clearScriptResults(appName, phase); err != nil {
+		common.LogWarn(fmt.Sprintf("Unable to clear previous %s task results: %s", phase, err.Error()))
+	}
+
+	if len(scripts) == 0 {
 		common.LogVerbose(fmt.Sprintf("No %s task found, skipping", phase))
 		return nil
 	}
 
-	if phase == "predeploy" {
-		common.LogVerbose(fmt.Sprintf("Executing %s task from %s: %s", phase, phaseSource, command))
+	for i, script := range scripts {
+		name := script.Name
+		if name == "" {
+			name = phase
+			if len(scripts) > 1 {
+				name = fmt.Sprintf("%s-%d", phase, i+1)
+			}
+		}
+
+		if err := executeDeployScript(appName, image, imageTag, phase, phaseSource, name, script); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func executeDeployScript(appName string, image string, imageTag string, phase string, phaseSource string, name string, deployScript DeployScript) error {
+	command := deployScript.Command
+	if command == "" {
+		common.LogVerbose(fmt.Sprintf("No command specified for %s task %s, skipping", phase, name))
+		return nil
+	}
+
+	label := phase
+	if deployScript.Name != "" {
+		label = fmt.Sprintf("%s (%s)", phase, deployScript.Name)
+	}
+
+	if isCommittingPhase(phase) {
+		common.LogVerbose(fmt.Sprintf("Executing %s task from %s: %s", label, phaseSource, command))
 	} else {
-		common.LogVerbose(fmt.Sprintf("Executing %s task from %s in ephemeral container: %s", phase, phaseSource, command))
+		common.LogVerbose(fmt.Sprintf("Executing %s task from %s in ephemeral container: %s", label, phaseSource, command))
 	}
 
 	isHerokuishImage := common.IsImageHerokuishBased(image, appName)
@@ -177,74 +293,18 @@ func executeScript(appName string, image string, imageTag string, phase string)
 		imageSourceType = "pack"
 	}
 
-	var dockerArgs []string
-	results, err := common.CallPlugnTrigger(common.PlugnTriggerInput{
-		Trigger: "docker-args-deploy",
-		Args:    []string{appName, imageTag},
-		Stdin:   strings.NewReader(""),
-	})
-	if err == nil {
-		words, err := shellquote.Split(results.StdoutContents())
-		if err != nil {
-			return err
-		}
-
-		dockerArgs = append(dockerArgs, words...)
-	}
-
-	results, err = common.CallPlugnTrigger(common.PlugnTriggerInput{
-		Trigger: "docker-args-process-deploy",
-		Args:    []string{appName, imageSourceType, imageTag},
-		Stdin:   strings.NewReader(""),
-	})
-	if err == nil {
-		words, err := shellquote.Split(results.StdoutContents())
-		if err != nil {
-			return err
-		}
-
-		dockerArgs = append(dockerArgs, words...)
+	dockerArgs, err := getDeployScriptDockerArgs(appName, imageSourceType, imageTag, deployScript)
+	if err != nil {
+		return err
 	}
 
-	filteredArgs := []string{
-		"--cpus",
-		"--gpus",
-		"--memory",
-		"--memory-reservation",
-		"--memory-swap",
-		"--publish",
-		"--publish-all",
-		"--restart",
-		"-p",
-		"-P",
+	dockerArgs = append(dockerArgs, "--label=dokku_phase_script="+phase)
+	if deployScript.Name != "" {
+		dockerArgs = append(dockerArgs, "--label=dokku_phase_script_name="+deployScript.Name)
 	}
-	for _, filteredArg := range filteredArgs {
-		// re := regexp.MustCompile("--" + filteredArg + "=[0-9A-Za-z!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~]+ ")
-
-		skipNext := false
-		var filteredDockerArgs []string
-		for _, dockerArg := range dockerArgs {
-			if skipNext {
-				skipNext = false
-				continue
-			}
-
-			if strings.HasPrefix(dockerArg, filteredArg+"=") {
-				continue
-			}
-
-			if dockerArg == filteredArg {
-				skipNext = true
-				continue
-			}
-
-			filteredDockerArgs = append(filteredDockerArgs, dockerArg)
-		}
-
-		dockerArgs = filteredDockerArgs
+	if deployScript.ProcessType != "" {
+		dockerArgs = append(dockerArgs, "--label=com.dokku.process-type="+deployScript.ProcessType)
 	}
-
-	dockerArgs = append(dockerArgs, "--label=dokku_phase_script="+phase)
 	if isHerokuishImage && !isCnbImage {
 		dockerArgs = append(dockerArgs, "-v", fmt.Sprintf("cache-%s:/tmp/cache", appName))
 	}
@@ -259,25 +319,42 @@ func executeScript(appName string, image string, imageTag string, phase string)
 		dockerArgs = append(dockerArgs, "--entrypoint=/cnb/lifecycle/launcher")
 	}
 
-	containerID, err := createdContainerID(appName, dockerArgs, image, script, phase)
-	if err != nil {
-		return fmt.Errorf("Failed to create %s execution container: %s", phase, err.Error())
+	// an explicit 0 on the task overrides the app and global properties
+	timeout := getScriptTimeout(appName)
+	if deployScript.Timeout != nil && *deployScript.Timeout >= 0 {
+		timeout = *deployScript.Timeout
 	}
 
-	defer cleanupDeploymentContainer(containerID, phase)
+	retries := getScriptRetries(appName)
+	if deployScript.Retries != nil && *deployScript.Retries >= 0 {
+		retries = *deployScript.Retries
+	}
+
+	containerID := ""
+	startedAt := time.Now()
+	for attempt := 1; attempt <= retries+1; attempt++ {
+		if attempt > 1 {
+			common.LogWarn(fmt.Sprintf("Retrying %s task, attempt %d of %d", label, attempt, retries+1))
+		}
+
+		var exitCode int
+		containerID, exitCode, err = runDeploymentContainer(appName, image, phase, label, dockerArgs, script, timeout)
+		if err == nil {
+			recordScriptResult(appName, phase, name, exitCode, time.Since(startedAt), attempt)
+			break
+		}
+
+		if errors.Is(err, errScriptCancelled) || attempt > retries {
+			recordScriptResult(appName, phase, name, exitCode, time.Since(startedAt), attempt)
+			return fmt.Errorf("Execution of %s task failed: %s: %s", label, err.Error(), command)
+		}
 
-	if !waitForExecution(containerID) {
-		common.LogInfo2Quiet(fmt.Sprintf("Start of %s %s task (%s) output", appName, phase, containerID[0:9]))
-		common.LogVerboseQuietContainerLogs(containerID)
-		common.LogInfo2Quiet(fmt.Sprintf("End of %s %s task (%s) output", appName, phase, containerID[0:9]))
-		return fmt.Errorf("Execution of %s task failed: %s", phase, command)
+		common.LogWarn(fmt.Sprintf("Execution of %s task failed: %s", label, err.Error()))
 	}
 
-	common.LogInfo2Quiet(fmt.Sprintf("Start of %s %s task (%s) output", appName, phase, containerID[0:9]))
-	common.LogVerboseQuietContainerLogs(containerID)
-	common.LogInfo2Quiet(fmt.Sprintf("End of %s %s task (%s) output", appName, phase, containerID[0:9]))
+	defer removeDeploymentContainer(containerID, phase)
 
-	if phase != "predeploy" {
+	if !isCommittingPhase(phase) {
 		return nil
 	}
 
@@ -309,16 +386,103 @@ func executeScript(appName string, image string, imageTag string, phase string)
 		StreamStderr: true,
 	})
 	if err != nil {
-		return fmt.Errorf("Committing of '%s' to image failed: %w", phase, err)
+		return fmt.Errorf("Committing of '%s' to image failed: %w", label, err)
 	}
 
 	if result.ExitCode != 0 {
-		return fmt.Errorf("Committing of '%s' to image failed: %s", phase, command)
+		return fmt.Errorf("Committing of '%s' to image failed: %s", label, command)
 	}
 
 	return nil
 }
 
+// getDeployScriptDockerArgs fetches the docker options for the phase a deployment task is configured to use
+func getDeployScriptDockerArgs(appName string, imageSourceType string, imageTag string, deployScript DeployScript) ([]string, error) {
+	triggers := []common.PlugnTriggerInput{}
+	switch deployScript.DockerOptionsPhase {
+	case "build":
+		triggers = append(triggers,
+			common.PlugnTriggerInput{Trigger: "docker-args-build", Args: []string{appName, imageSourceType}},
+			common.PlugnTriggerInput{Trigger: "docker-args-process-build", Args: []string{appName, imageSourceType}},
+		)
+	case "run":
+		triggers = append(triggers,
+			common.PlugnTriggerInput{Trigger: "docker-args-run", Args: []string{appName, imageTag}},
+			common.PlugnTriggerInput{Trigger: "docker-args-process-run", Args: []string{appName, imageSourceType, imageTag}},
+		)
+	case "", "deploy":
+		deployArgs := []string{appName, imageTag}
+		processDeployArgs := []string{appName, imageSourceType, imageTag}
+		if deployScript.ProcessType != "" {
+			deployArgs = append(deployArgs, deployScript.ProcessType, "0")
+			processDeployArgs = append(processDeployArgs, deployScript.ProcessType, "0")
+		}
+
+		triggers = append(triggers,
+			common.PlugnTriggerInput{Trigger: "docker-args-deploy", Args: deployArgs},
+			common.PlugnTriggerInput{Trigger: "docker-args-process-deploy", Args: processDeployArgs},
+		)
+	default:
+		return []string{}, fmt.Errorf("Invalid docker_options_phase for deployment task: %s", deployScript.DockerOptionsPhase)
+	}
+
+	var dockerArgs []string
+	for _, trigger := range triggers {
+		trigger.Stdin = strings.NewReader("")
+		results, err := common.CallPlugnTrigger(trigger)
+		if err != nil {
+			continue
+		}
+
+		words, err := shellquote.Split(results.StdoutContents())
+		if err != nil {
+			return []string{}, err
+		}
+
+		dockerArgs = append(dockerArgs, words...)
+	}
+
+	filteredArgs := []string{
+		"--cpus",
+		"--gpus",
+		"--memory",
+		"--memory-reservation",
+		"--memory-swap",
+		"--publish",
+		"--publish-all",
+		"--restart",
+		"-p",
+		"-P",
+	}
+	for _, filteredArg := range filteredArgs {
+		// re := regexp.MustCompile("--" + filteredArg + "=[0-9A-Za-z!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~]+ ")
+
+		skipNext := false
+		var filteredDockerArgs []string
+		for _, dockerArg := range dockerArgs {
+			if skipNext {
+				skipNext = false
+				continue
+			}
+
+			if strings.HasPrefix(dockerArg, filteredArg+"=") {
+				continue
+			}
+
+			if dockerArg == filteredArg {
+				skipNext = true
+				continue
+			}
+
+			filteredDockerArgs = append(filteredDockerArgs, dockerArg)
+		}
+
+		dockerArgs = filteredDockerArgs
+	}
+
+	return dockerArgs, nil
+}
+
 func getEntrypointFromImage(image string) (string, error) {
 	output, err := common.DockerInspect(image, "{{json .Config.Entrypoint}}")
 	if err != nil {
@@ -363,12 +527,111 @@ func getCommandFromImage(image string) (string, error) {
 	return fmt.Sprintf("CMD %s", string(serializedEntrypoint)), err
 }
 
-func waitForExecution(containerID string) bool {
+// runDeploymentContainer creates and runs a single attempt of a deployment task, returning the
+// id of the exited container on success. The container is removed on every failure path.
+func runDeploymentContainer(appName string, image string, phase string, label string, dockerArgs []string, script []string, timeout int) (string, int, error) {
+	containerID, err := createdContainerID(appName, dockerArgs, image, script, phase)
+	if err != nil {
+		if containerID != "" {
+			removeDeploymentContainer(containerID, phase)
+		}
+		return "", 1, fmt.Errorf("Failed to create %s execution container: %s", label, err.Error())
+	}
+
+	exitCode, err := waitForExecution(containerID, timeout)
+
+	common.LogInfo2Quiet(fmt.Sprintf("Start of %s %s task (%s) output", appName, label, containerID[0:9]))
+	common.LogVerboseQuietContainerLogs(containerID)
+	common.LogInfo2Quiet(fmt.Sprintf("End of %s %s task (%s) output", appName, label, containerID[0:9]))
+
+	if err == nil && exitCode != 0 {
+		err = fmt.Errorf("Exited with code %d", exitCode)
+	}
+
+	if err != nil {
+		removeDeploymentContainer(containerID, phase)
+		return "", exitCode, err
+	}
+
+	return containerID, exitCode, nil
+}
+
+// removeDeploymentContainer removes a deployment task container, logging any failure
+func removeDeploymentContainer(containerID string, phase string) {
+	if err := cleanupDeploymentContainer(containerID, phase); err != nil {
+		common.LogWarn(err.Error())
+	}
+}
+
+// waitForExecution starts a deployment task container and waits for it to exit,
+// stopping the container if it runs for longer than timeout seconds. SIGINT and
+// SIGTERM received while waiting are forwarded to the container.
+func waitForExecution(containerID string, timeout int) (int, error) {
 	if !common.ContainerStart(containerID) {
-		return false
+		return 1, errors.New("Unable to start container")
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		defer cancel()
+	}
+
+	result, err := common.CallExecCommandWithContext(ctx, common.ExecCommandInput{
+		Command:      common.DockerBin(),
+		Args:         []string{"container", "wait", containerID},
+		StreamStderr: true,
+	})
+	if ctx.Err() == context.DeadlineExceeded {
+		common.CallExecCommand(common.ExecCommandInput{
+			Command: common.DockerBin(),
+			Args:    []string{"container", "stop", containerID},
+		})
+		return 124, fmt.Errorf("Timed out after %d seconds", timeout)
+	}
+	if result.Cancelled {
+		sig := <-signals
+		return 130, forwardSignal(containerID, sig)
+	}
+	if err != nil {
+		return 1, err
 	}
 
-	return common.ContainerWait(containerID)
+	exitCode, err := strconv.Atoi(result.StdoutContents())
+	if err != nil {
+		return 1, err
+	}
+
+	return exitCode, nil
+}
+
+// forwardSignal sends a signal received by dokku to a deployment task container,
+// giving the container a short grace period to exit before it is removed
+func forwardSignal(containerID string, sig os.Signal) error {
+	signalName := "SIGTERM"
+	if sig == syscall.SIGINT {
+		signalName = "SIGINT"
+	}
+
+	common.LogWarn(fmt.Sprintf("Received %s, forwarding to container %s", signalName, containerID[0:9]))
+	common.CallExecCommand(common.ExecCommandInput{
+		Command: common.DockerBin(),
+		Args:    []string{"container", "kill", "--signal", signalName, containerID},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	common.CallExecCommandWithContext(ctx, common.ExecCommandInput{
+		Command: common.DockerBin(),
+		Args:    []string{"container", "wait", containerID},
+	})
+
+	return fmt.Errorf("%w by %s", errScriptCancelled, signalName)
 }
 
 func createdContainerID(appName string, dockerArgs []string, image string, command []string, phase string) (string, error) {
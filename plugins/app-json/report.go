@@ -1,6 +1,9 @@
 package appjson
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/dokku/dokku/plugins/common"
 )
 
@@ -11,9 +14,28 @@ func ReportSingleApp(appName string, format string, infoFlag string) error {
 	}
 
 	flags := map[string]common.ReportFunc{
-		"--app-json-computed-selected": reportComputedAppjsonpath,
-		"--app-json-global-selected":   reportGlobalAppjsonpath,
-		"--app-json-selected":          reportAppjsonpath,
+		"--app-json-computed-selected":       reportComputedAppjsonpath,
+		"--app-json-global-selected":         reportGlobalAppjsonpath,
+		"--app-json-selected":                reportAppjsonpath,
+		"--app-json-computed-script-retries": reportComputedScriptRetries,
+		"--app-json-global-script-retries":   reportGlobalScriptRetries,
+		"--app-json-script-retries":          reportScriptRetries,
+		"--app-json-computed-script-timeout": reportComputedScriptTimeout,
+		"--app-json-global-script-timeout":   reportGlobalScriptTimeout,
+		"--app-json-script-timeout":          reportScriptTimeout,
+	}
+
+	scriptResults, err := common.PropertyGetAllByPrefix("app-json", appName, "script-result.")
+	if err != nil {
+		return err
+	}
+
+	for property, value := range scriptResults {
+		flag := "--app-json-" + strings.ReplaceAll(property, ".", "-")
+		result := value
+		flags[flag] = func(appName string) string {
+			return reportScriptResult(result)
+		}
 	}
 
 	flagKeys := []string{}
@@ -43,3 +65,41 @@ func reportGlobalAppjsonpath(appName string) string {
 func reportAppjsonpath(appName string) string {
 	return common.PropertyGet("app-json", appName, "appjson-path")
 }
+
+func reportComputedScriptRetries(appName string) string {
+	return getComputedProperty(appName, "script-retries")
+}
+
+func reportGlobalScriptRetries(appName string) string {
+	return common.PropertyGet("app-json", "--global", "script-retries")
+}
+
+func reportScriptRetries(appName string) string {
+	return common.PropertyGet("app-json", appName, "script-retries")
+}
+
+func reportComputedScriptTimeout(appName string) string {
+	return getComputedProperty(appName, "script-timeout")
+}
+
+func reportGlobalScriptTimeout(appName string) string {
+	return common.PropertyGet("app-json", "--global", "script-timeout")
+}
+
+func reportScriptTimeout(appName string) string {
+	return common.PropertyGet("app-json", appName, "script-timeout")
+}
+
+func reportScriptResult(value string) string {
+	parts := strings.Fields(value)
+	if len(parts) < 2 {
+		return value
+	}
+
+	result := fmt.Sprintf("exit code %s in %ss", parts[0], parts[1])
+	if len(parts) > 3 && parts[3] != "1" {
+		result = fmt.Sprintf("%s after %s attempts", result, parts[3])
+	}
+
+	return result
+}
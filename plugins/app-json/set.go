@@ -0,0 +1,31 @@
+package appjson
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func validateSetValue(appName string, key string, value string) error {
+	if key == "script-retries" || key == "script-timeout" {
+		return validateNonNegativeInteger(key, value)
+	}
+
+	return nil
+}
+
+func validateNonNegativeInteger(key string, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("Invalid %s value, unable to convert number to int: %s", key, err.Error())
+	}
+
+	if number < 0 {
+		return fmt.Errorf("Invalid %s value, must be greater than or equal to 0", key)
+	}
+
+	return nil
+}
@@ -40,6 +40,12 @@ func main() {
 			value = args.Arg(1)
 		}
 		err = appjson.CommandSet(appName, property, value)
+	case "validate":
+		args := flag.NewFlagSet("app-json:validate", flag.ExitOnError)
+		file := args.String("file", "", "--file: path to an app.json file to validate")
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		err = appjson.CommandValidate(appName, *file)
 	default:
 		err = fmt.Errorf("Invalid plugin subcommand call: %s", subcommand)
 	}
@@ -25,6 +25,9 @@ func main() {
 	case "app-json-get-content":
 		appName := flag.Arg(0)
 		err = appjson.TriggerAppJSONGetContent(appName)
+	case "app-json-get-env-schema":
+		appName := flag.Arg(0)
+		err = appjson.TriggerAppJSONGetEnvSchema(appName)
 	case "core-post-deploy":
 		appName := flag.Arg(0)
 		err = appjson.TriggerCorePostDeploy(appName)
@@ -46,6 +49,11 @@ func main() {
 		oldAppName := flag.Arg(0)
 		newAppName := flag.Arg(1)
 		err = appjson.TriggerPostAppRenameSetup(oldAppName, newAppName)
+	case "post-build":
+		builderType := flag.Arg(0)
+		appName := flag.Arg(1)
+		sourceWorkDir := flag.Arg(2)
+		err = appjson.TriggerPostBuild(builderType, appName, sourceWorkDir)
 	case "post-create":
 		appName := flag.Arg(0)
 		err = appjson.TriggerPostCreate(appName)
@@ -61,6 +69,11 @@ func main() {
 		appName := flag.Arg(1)
 		image := flag.Arg(2)
 		err = appjson.TriggerPostReleaseBuilder(builderType, appName, image)
+	case "pre-build":
+		builderType := flag.Arg(0)
+		appName := flag.Arg(1)
+		sourceWorkDir := flag.Arg(2)
+		err = appjson.TriggerPreBuild(builderType, appName, sourceWorkDir)
 	case "pre-release-builder":
 		builderType := flag.Arg(0)
 		appName := flag.Arg(1)
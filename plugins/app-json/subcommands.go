@@ -2,6 +2,7 @@ package appjson
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/dokku/dokku/plugins/common"
 )
@@ -30,6 +31,66 @@ func CommandReport(appName string, format string, infoFlag string) error {
 
 // CommandSet set or clear a builder property for an app
 func CommandSet(appName string, property string, value string) error {
+	if err := validateSetValue(appName, property, value); err != nil {
+		return err
+	}
+
 	common.CommandPropertySet("app-json", appName, property, value, DefaultProperties, GlobalProperties)
 	return nil
 }
+
+// CommandValidate validates the app.json file for an app
+func CommandValidate(appName string, file string) error {
+	if err := common.VerifyAppName(appName); err != nil {
+		return err
+	}
+
+	path := file
+	if path == "" {
+		if !hasAppJSON(appName) {
+			common.LogWarn(fmt.Sprintf("No app.json found for %s, skipping validation", appName))
+			return nil
+		}
+
+		path = getProcessSpecificAppJSONPath(appName)
+	}
+
+	if !common.FileExists(path) {
+		return fmt.Errorf("Specified app.json file does not exist: %s", path)
+	}
+
+	problems, err := ValidateAppJSON(ValidateAppJSONInput{
+		AppName: appName,
+		Path:    path,
+	})
+	if err != nil {
+		return err
+	}
+
+	errorCount := 0
+	warningCount := 0
+	for _, problem := range problems {
+		if problem.Warning {
+			warningCount++
+			continue
+		}
+
+		errorCount++
+	}
+
+	if errorCount == 0 {
+		if err := validationErrorsToError(problems); err != nil {
+			return err
+		}
+
+		common.LogInfo1(fmt.Sprintf("app.json for %s is valid", appName))
+		return nil
+	}
+
+	if warningCount > 0 {
+		common.LogWarn(fmt.Sprintf("app.json for %s has %d problem(s) and %d warning(s)", appName, errorCount, warningCount))
+	} else {
+		common.LogWarn(fmt.Sprintf("app.json for %s has %d problem(s)", appName, errorCount))
+	}
+	return validationErrorsToError(problems)
+}
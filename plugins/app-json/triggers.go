@@ -2,7 +2,6 @@ package appjson
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"strings"
 
@@ -56,6 +55,22 @@ func TriggerAppJSONGetContent(appName string) error {
 	return nil
 }
 
+// TriggerAppJSONGetEnvSchema outputs the config schema rules declared in the app.json env section as json
+func TriggerAppJSONGetEnvSchema(appName string) error {
+	appJSON, err := GetAppJSON(appName)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(getEnvSchema(appJSON))
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(string(content))
+	return nil
+}
+
 // TriggerCorePostDeploy moves the extracted app.json to the app data directory
 // allowing the app to be restored on boot
 func TriggerCorePostDeploy(appName string) error {
@@ -70,6 +85,7 @@ func TriggerCorePostDeploy(appName string) error {
 }
 
 // TriggerCorePostExtract ensures that the main app.json is the one specified by app-json-path
+// and seeds the app config from the app.json env section
 func TriggerCorePostExtract(appName string, sourceWorkDir string) error {
 	destination := common.GetAppDataDirectory("app-json", appName)
 	appJSONPath := strings.Trim(reportComputedAppjsonpath(appName), "/")
@@ -82,21 +98,15 @@ func TriggerCorePostExtract(appName string, sourceWorkDir string) error {
 			return nil
 		}
 
-		result, err := common.CallPlugnTrigger(common.PlugnTriggerInput{
-			Trigger:      "app-json-is-valid",
-			Args:         []string{appName, path},
-			StreamStdout: true,
-			StreamStderr: true,
+		problems, err := ValidateAppJSON(ValidateAppJSONInput{
+			AppName: appName,
+			Path:    path,
 		})
-
 		if err != nil {
-			if result.StderrContents() != "" {
-				return errors.New(result.StderrContents())
-			}
-
 			return err
 		}
-		return nil
+
+		return validationErrorsToError(problems)
 	}
 
 	results, _ := common.CallPlugnTrigger(common.PlugnTriggerInput{
@@ -104,7 +114,7 @@ func TriggerCorePostExtract(appName string, sourceWorkDir string) error {
 		Args:    []string{appName, "build-dir"},
 	})
 	buildDir := results.StdoutContents()
-	return common.CorePostExtract(common.CorePostExtractInput{
+	err := common.CorePostExtract(common.CorePostExtractInput{
 		AppName:       appName,
 		BuildDir:      buildDir,
 		Destination:   destination,
@@ -120,6 +130,17 @@ func TriggerCorePostExtract(appName string, sourceWorkDir string) error {
 			},
 		},
 	})
+	if err != nil {
+		return err
+	}
+
+	appJSON, err := GetAppJSON(appName)
+	if err != nil {
+		return err
+	}
+
+	reportAddons(appName, appJSON)
+	return seedEnv(appName, appJSON)
 }
 
 // TriggerInstall initializes app-json directory structures
@@ -190,9 +211,36 @@ func TriggerPostDeploy(appName string, imageTag string) error {
 	return executeScript(appName, image, imageTag, "postdeploy")
 }
 
+// TriggerPostBuild is a trigger to execute the postbuild deployment tasks
+func TriggerPostBuild(builderType string, appName string, sourceWorkDir string) error {
+	image := common.GetAppImageName(appName, "", "")
+	return executeScript(appName, image, "latest", "postbuild")
+}
+
+// TriggerPreBuild is a trigger to execute the prebuild deployment tasks
+func TriggerPreBuild(builderType string, appName string, sourceWorkDir string) error {
+	if builderType != "herokuish" {
+		scripts, err := getPhaseScripts(appName, "prebuild")
+		if err == nil && len(scripts) > 0 {
+			common.LogWarn(fmt.Sprintf("Skipping prebuild tasks as they are not supported by the %s builder", builderType))
+		}
+		return nil
+	}
+
+	image := common.GetAppImageName(appName, "", "")
+	return executeScript(appName, image, "latest", "prebuild")
+}
+
+// TriggerPreReleaseBuilder is a trigger to execute prerelease and predeploy deployment tasks
 func TriggerPreReleaseBuilder(builderType string, appName string, image string) error {
 	parts := strings.Split(image, ":")
 	imageTag := parts[len(parts)-1]
+	if err := executeScript(appName, image, imageTag, "prerelease"); err != nil {
+		return err
+	}
+
+	phase := common.StartLogPhase(appName, "predeploy")
+	defer phase.End()
 	return executeScript(appName, image, imageTag, "predeploy")
 }
 
@@ -0,0 +1,468 @@
+package appjson
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dokku/dokku/plugins/common"
+	"github.com/hashicorp/go-multierror"
+	"github.com/tailscale/hujson"
+)
+
+// Schema is the published JSON Schema for the app.json file as understood by Dokku
+//
+//go:embed schema.json
+var Schema []byte
+
+// ValidationError is a single problem found while validating an app.json file
+type ValidationError struct {
+	// Path is the JSON path to the offending value
+	Path string
+
+	// Line is the line number of the offending value, or 0 if unknown
+	Line int
+
+	// Message is a description of the problem
+	Message string
+
+	// Warning is whether the problem is reported without failing validation
+	Warning bool
+}
+
+// Error returns the validation error as a string
+func (e ValidationError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s (line %d): %s", e.Path, e.Line, e.Message)
+	}
+
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidateAppJSONInput is the input for the ValidateAppJSON function
+type ValidateAppJSONInput struct {
+	// AppName is the name of the app the app.json file belongs to
+	AppName string
+
+	// Path is the path to the app.json file on disk
+	Path string
+
+	// SkipPluginChecks skips the app-json-is-valid plugin trigger
+	SkipPluginChecks bool
+}
+
+// ValidateAppJSON validates an app.json file against the published schema and
+// all plugins implementing the app-json-is-valid trigger, returning every problem found
+func ValidateAppJSON(input ValidateAppJSONInput) ([]ValidationError, error) {
+	b, err := os.ReadFile(input.Path)
+	if err != nil {
+		return []ValidationError{}, fmt.Errorf("Cannot read app.json file: %v", err)
+	}
+
+	problems := validateAgainstSchema(b)
+	if input.SkipPluginChecks || input.AppName == "" {
+		return problems, nil
+	}
+
+	result, err := common.CallPlugnTrigger(common.PlugnTriggerInput{
+		Trigger: "app-json-is-valid",
+		Args:    []string{input.AppName, input.Path},
+	})
+	if err == nil {
+		return problems, nil
+	}
+
+	messages := []string{}
+	for _, line := range strings.Split(result.StderrContents(), "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "!"))
+		if line != "" {
+			messages = append(messages, line)
+		}
+	}
+	if len(messages) == 0 {
+		messages = append(messages, err.Error())
+	}
+
+	for _, message := range messages {
+		problems = append(problems, ValidationError{Path: "$", Message: message})
+	}
+
+	return problems, nil
+}
+
+// validationErrorsToError logs any validation warnings and combines the remaining validation errors into a single error
+func validationErrorsToError(problems []ValidationError) error {
+	var result *multierror.Error
+	for _, problem := range problems {
+		if problem.Warning {
+			common.LogWarn(problem.Error())
+			continue
+		}
+
+		result = multierror.Append(result, problem)
+	}
+
+	return result.ErrorOrNil()
+}
+
+type jsonSchema struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	Type                 jsonSchemaTypes        `json:"type,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	AdditionalProperties *jsonSchemaAdditional  `json:"additionalProperties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	Defs                 map[string]*jsonSchema `json:"$defs,omitempty"`
+}
+
+// jsonSchemaTypes is a list of allowed types, decoded from either a string or a list of strings
+type jsonSchemaTypes []string
+
+// UnmarshalJSON decodes either a single type or a list of types
+func (t *jsonSchemaTypes) UnmarshalJSON(b []byte) error {
+	var schemaType string
+	if err := json.Unmarshal(b, &schemaType); err == nil {
+		*t = jsonSchemaTypes{schemaType}
+		return nil
+	}
+
+	var schemaTypes []string
+	if err := json.Unmarshal(b, &schemaTypes); err != nil {
+		return err
+	}
+
+	*t = jsonSchemaTypes(schemaTypes)
+	return nil
+}
+
+// jsonSchemaAdditional is either a boolean or a schema for additionalProperties
+type jsonSchemaAdditional struct {
+	Allowed bool
+	Schema  *jsonSchema
+}
+
+// UnmarshalJSON decodes either a boolean or a schema object
+func (a *jsonSchemaAdditional) UnmarshalJSON(b []byte) error {
+	var allowed bool
+	if err := json.Unmarshal(b, &allowed); err == nil {
+		a.Allowed = allowed
+		return nil
+	}
+
+	a.Allowed = true
+	return json.Unmarshal(b, &a.Schema)
+}
+
+type jsonNodeMember struct {
+	name   string
+	offset int64
+	node   *jsonNode
+}
+
+type jsonNode struct {
+	kind     string
+	offset   int64
+	members  []jsonNodeMember
+	elements []*jsonNode
+	str      string
+	number   json.Number
+	boolean  bool
+}
+
+func validateAgainstSchema(b []byte) []ValidationError {
+	if strings.TrimSpace(string(b)) == "" {
+		return []ValidationError{}
+	}
+
+	var schema jsonSchema
+	if err := json.Unmarshal(Schema, &schema); err != nil {
+		return []ValidationError{{Path: "$", Message: fmt.Sprintf("Cannot parse app.json schema: %v", err)}}
+	}
+
+	// standardizing replaces comments and trailing commas with whitespace, preserving offsets
+	standardized, err := hujson.Standardize(b)
+	if err != nil {
+		return []ValidationError{{Path: "$", Message: fmt.Sprintf("Cannot parse app.json as jsonc: %v", err)}}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(standardized))
+	decoder.UseNumber()
+	root, err := decodeJSONNode(decoder, standardized)
+	if err != nil {
+		problem := ValidationError{Path: "$", Message: fmt.Sprintf("Cannot parse app.json: %v", err)}
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			problem.Line = lineForOffset(standardized, syntaxErr.Offset)
+		}
+		return []ValidationError{problem}
+	}
+
+	v := schemaValidator{root: &schema, data: standardized, problems: []ValidationError{}}
+	v.validate(&schema, root, "$")
+	return v.problems
+}
+
+func decodeJSONNode(decoder *json.Decoder, data []byte) (*jsonNode, error) {
+	offset := skipJSONSeparators(data, decoder.InputOffset())
+	token, err := decoder.Token()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("unexpected end of file")
+		}
+		return nil, err
+	}
+
+	node := &jsonNode{offset: offset}
+	switch value := token.(type) {
+	case json.Delim:
+		if value == '{' {
+			node.kind = "object"
+			for decoder.More() {
+				keyOffset := skipJSONSeparators(data, decoder.InputOffset())
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+
+				child, err := decodeJSONNode(decoder, data)
+				if err != nil {
+					return nil, err
+				}
+
+				node.members = append(node.members, jsonNodeMember{name: keyToken.(string), offset: keyOffset, node: child})
+			}
+		} else {
+			node.kind = "array"
+			for decoder.More() {
+				child, err := decodeJSONNode(decoder, data)
+				if err != nil {
+					return nil, err
+				}
+
+				node.elements = append(node.elements, child)
+			}
+		}
+
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+	case string:
+		node.kind = "string"
+		node.str = value
+	case json.Number:
+		node.kind = "number"
+		node.number = value
+	case bool:
+		node.kind = "boolean"
+		node.boolean = value
+	case nil:
+		node.kind = "null"
+	}
+
+	return node, nil
+}
+
+func skipJSONSeparators(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) {
+		switch data[offset] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			offset++
+		default:
+			return offset
+		}
+	}
+
+	return offset
+}
+
+func lineForOffset(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+type schemaValidator struct {
+	root     *jsonSchema
+	data     []byte
+	problems []ValidationError
+}
+
+func (v *schemaValidator) addProblem(path string, offset int64, message string) {
+	v.problems = append(v.problems, ValidationError{
+		Path:    path,
+		Line:    lineForOffset(v.data, offset),
+		Message: message,
+	})
+}
+
+func (v *schemaValidator) addWarning(path string, offset int64, message string) {
+	v.problems = append(v.problems, ValidationError{
+		Path:    path,
+		Line:    lineForOffset(v.data, offset),
+		Message: message,
+		Warning: true,
+	})
+}
+
+func (v *schemaValidator) resolve(schema *jsonSchema) *jsonSchema {
+	for schema != nil && schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/$defs/")
+		schema = v.root.Defs[name]
+	}
+
+	return schema
+}
+
+func (v *schemaValidator) validate(schema *jsonSchema, node *jsonNode, path string) {
+	schema = v.resolve(schema)
+	if schema == nil {
+		return
+	}
+
+	if len(schema.Type) > 0 && !nodeMatchesType(node, schema.Type) {
+		v.addProblem(path, node.offset, fmt.Sprintf("expected %s, found %s", strings.Join(schema.Type, " or "), node.kind))
+		return
+	}
+
+	switch node.kind {
+	case "object":
+		v.validateObject(schema, node, path)
+	case "array":
+		if schema.Items != nil {
+			for i, element := range node.elements {
+				v.validate(schema.Items, element, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	case "string":
+		if len(schema.Enum) > 0 && !stringInSlice(node.str, schema.Enum) {
+			v.addProblem(path, node.offset, fmt.Sprintf("invalid value %q, must be one of: %s", node.str, strings.Join(schema.Enum, ", ")))
+		}
+
+		if schema.MinLength != nil && len(node.str) < *schema.MinLength {
+			v.addProblem(path, node.offset, "value must not be empty")
+		}
+	case "number":
+		if schema.Minimum != nil {
+			if value, err := node.number.Float64(); err == nil && value < *schema.Minimum {
+				v.addProblem(path, node.offset, fmt.Sprintf("value must be greater than or equal to %s", strconv.FormatFloat(*schema.Minimum, 'f', -1, 64)))
+			}
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(schema *jsonSchema, node *jsonNode, path string) {
+	seen := map[string]bool{}
+	for _, member := range node.members {
+		seen[member.name] = true
+		memberPath := path + "." + member.name
+		if propertySchema, ok := schema.Properties[member.name]; ok {
+			v.validate(propertySchema, member.node, memberPath)
+			continue
+		}
+
+		if schema.AdditionalProperties == nil {
+			continue
+		}
+
+		// unknown properties are only warned about, as app.json files may contain keys for other platforms
+		if !schema.AdditionalProperties.Allowed {
+			message := fmt.Sprintf("unknown property %q", member.name)
+			if suggestion := suggestProperty(member.name, schema.Properties); suggestion != "" {
+				message = fmt.Sprintf("%s, did you mean %q?", message, suggestion)
+			}
+			v.addWarning(memberPath, member.offset, message)
+			continue
+		}
+
+		if schema.AdditionalProperties.Schema != nil {
+			v.validate(schema.AdditionalProperties.Schema, member.node, memberPath)
+		}
+	}
+
+	for _, required := range schema.Required {
+		if !seen[required] {
+			v.addProblem(path, node.offset, fmt.Sprintf("missing required property %q", required))
+		}
+	}
+}
+
+func nodeMatchesType(node *jsonNode, schemaTypes []string) bool {
+	for _, schemaType := range schemaTypes {
+		if schemaType == "integer" && node.kind == "number" {
+			if _, err := node.number.Int64(); err == nil {
+				return true
+			}
+		}
+
+		if node.kind == schemaType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// suggestProperty returns the closest known property name for a misspelled property
+func suggestProperty(name string, properties map[string]*jsonSchema) string {
+	names := []string{}
+	for property := range properties {
+		names = append(names, property)
+	}
+	sort.Strings(names)
+
+	suggestion := ""
+	bestDistance := 3
+	for _, property := range names {
+		distance := levenshteinDistance(name, property)
+		if distance < bestDistance {
+			bestDistance = distance
+			suggestion = property
+		}
+	}
+
+	return suggestion
+}
+
+func levenshteinDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func stringInSlice(value string, values []string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,6 +1,7 @@
 package buildpacks
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -11,6 +12,38 @@ import (
 	"github.com/dokku/dokku/plugins/common"
 )
 
+// appJSONBuildpacks returns the buildpacks specified in the app.json file for an app
+func appJSONBuildpacks(appName string) ([]string, error) {
+	results, err := common.CallPlugnTrigger(common.PlugnTriggerInput{
+		Trigger: "app-json-get-content",
+		Args:    []string{appName},
+	})
+	if err != nil {
+		return []string{}, nil
+	}
+
+	var appJSON struct {
+		Buildpacks []struct {
+			URL string `json:"url"`
+		} `json:"buildpacks"`
+	}
+	if err := json.Unmarshal(results.StdoutBytes(), &appJSON); err != nil {
+		return []string{}, fmt.Errorf("Unable to parse app.json buildpacks: %s", err.Error())
+	}
+
+	buildpacks := []string{}
+	for i, buildpack := range appJSON.Buildpacks {
+		url, err := validBuildpackURL(buildpack.URL)
+		if err != nil {
+			return []string{}, fmt.Errorf("Invalid app.json buildpack at index %d: %s", i, err.Error())
+		}
+
+		buildpacks = append(buildpacks, url)
+	}
+
+	return buildpacks, nil
+}
+
 func rewriteBuildpacksFile(sourceWorkDir string) error {
 	buildpacksPath := filepath.Join(sourceWorkDir, ".buildpacks")
 	if !common.FileExists(buildpacksPath) {
@@ -79,11 +79,22 @@ func TriggerPostExtract(appName string, sourceWorkDir string) error {
 		return nil
 	}
 
+	buildpacksPath := filepath.Join(sourceWorkDir, ".buildpacks")
+	if len(buildpacks) == 0 && !common.FileExists(buildpacksPath) {
+		buildpacks, err = appJSONBuildpacks(appName)
+		if err != nil {
+			return err
+		}
+
+		if len(buildpacks) > 0 {
+			common.LogInfo1("Using buildpacks from app.json")
+		}
+	}
+
 	if len(buildpacks) == 0 {
 		return rewriteBuildpacksFile(sourceWorkDir)
 	}
 
-	buildpacksPath := filepath.Join(sourceWorkDir, ".buildpacks")
 	file, err := os.OpenFile(buildpacksPath, os.O_RDWR|os.O_TRUNC|os.O_CREATE, 0600)
 	if err != nil {
 		return fmt.Errorf("Error writing .buildpacks file: %s", err.Error())
@@ -0,0 +1,369 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// DeliveryMaxAttempts is the number of times delivery of a queued request is attempted before it is dropped
+	DeliveryMaxAttempts = 5
+
+	// deliveryTimeout is how long a single delivery attempt may take
+	deliveryTimeout = 5 * time.Second
+)
+
+// DeliveryRequest is a json request posted to a webhook
+type DeliveryRequest struct {
+	// URL is the url the request is posted to
+	URL string `json:"url"`
+
+	// Headers are additional headers sent with the request
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body is the json body of the request
+	Body json.RawMessage `json:"body"`
+}
+
+// DeliveryResult is the outcome of a single delivery attempt
+type DeliveryResult struct {
+	// StatusCode is the http status code returned by the webhook, if any
+	StatusCode int
+
+	// Duration is how long the attempt took
+	Duration time.Duration
+
+	// Timestamp is the time the attempt was made
+	Timestamp time.Time
+
+	// Error is the reason the attempt failed, if it did
+	Error error
+}
+
+// QueuedDelivery is a request waiting in a delivery queue
+type QueuedDelivery struct {
+	// ID uniquely identifies the delivery, and starts with the time it was created so the queue can be ordered
+	ID string `json:"id"`
+
+	// Description describes the delivery in log output
+	Description string `json:"description"`
+
+	// Request is the request to deliver
+	Request DeliveryRequest `json:"request"`
+
+	// Attempts is the number of failed delivery attempts so far
+	Attempts int `json:"attempts"`
+
+	// NextAttemptAt is the earliest time delivery will next be attempted
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+
+	// LastError is the error from the last failed delivery attempt
+	LastError string `json:"last_error,omitempty"`
+
+	// Metadata contains plugin-specific details about the delivery
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// DeliveryQueue is a directory of requests that are retried with an exponential backoff until they are delivered
+type DeliveryQueue struct {
+	// Directory is the directory queued deliveries are stored in
+	Directory string
+
+	// Ordered stops delivery at the first queued request that is not delivered, so requests are delivered in order
+	Ordered bool
+
+	// OnAttempt is called after every delivery attempt
+	OnAttempt func(delivery QueuedDelivery, result DeliveryResult)
+}
+
+// DeliveryRetryBackoff returns how long to wait before the next delivery attempt, doubling after each failure
+func DeliveryRetryBackoff(attempts int) time.Duration {
+	return time.Duration(1<<(attempts-1)) * time.Minute
+}
+
+// PostDelivery posts a delivery request, failing on a timeout or a non-2xx response
+func PostDelivery(request DeliveryRequest) DeliveryResult {
+	result := DeliveryResult{Timestamp: time.Now().UTC()}
+	req, err := http.NewRequest(http.MethodPost, request.URL, bytes.NewReader(request.Body))
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range request.Headers {
+		req.Header.Set(key, value)
+	}
+
+	client := &http.Client{Timeout: deliveryTimeout}
+	res, err := client.Do(req)
+	if err != nil {
+		result.Error = err
+		result.Duration = time.Since(result.Timestamp)
+		return result
+	}
+	defer res.Body.Close()
+
+	result.StatusCode = res.StatusCode
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		result.Error = fmt.Errorf("Webhook returned status %d", res.StatusCode)
+	}
+
+	result.Duration = time.Since(result.Timestamp)
+	return result
+}
+
+// attempt posts a queued delivery and notifies the OnAttempt callback
+func (q DeliveryQueue) attempt(delivery QueuedDelivery) error {
+	result := PostDelivery(delivery.Request)
+	if q.OnAttempt != nil {
+		q.OnAttempt(delivery, result)
+	}
+
+	return result.Error
+}
+
+// Send attempts a delivery immediately, queueing it for a retry on failure
+func (q DeliveryQueue) Send(delivery QueuedDelivery) error {
+	if q.Ordered {
+		// wait for earlier requests to be delivered first
+		queued, err := q.List()
+		if err != nil {
+			return err
+		}
+		if len(queued) > 0 {
+			return q.Enqueue(delivery)
+		}
+	}
+
+	err := q.attempt(delivery)
+	if err == nil {
+		LogVerboseQuiet(fmt.Sprintf("Delivered %s", delivery.Description))
+		return nil
+	}
+
+	delivery.Attempts = 1
+	delivery.LastError = err.Error()
+	delivery.NextAttemptAt = time.Now().UTC().Add(DeliveryRetryBackoff(delivery.Attempts))
+	LogWarn(fmt.Sprintf("Unable to deliver %s, retrying after %s: %s", delivery.Description, delivery.NextAttemptAt.Format(time.RFC3339), err.Error()))
+	return q.Enqueue(delivery)
+}
+
+// Enqueue writes a delivery to the queue, replacing any queued delivery with the same id
+func (q DeliveryQueue) Enqueue(delivery QueuedDelivery) error {
+	if err := os.MkdirAll(q.Directory, 0700); err != nil {
+		return fmt.Errorf("Unable to create delivery queue directory: %w", err)
+	}
+
+	b, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+
+	// queued requests may contain signatures or credentials in the url
+	if err := os.WriteFile(q.filename(delivery), b, 0600); err != nil {
+		return fmt.Errorf("Unable to queue delivery: %w", err)
+	}
+
+	return nil
+}
+
+func (q DeliveryQueue) filename(delivery QueuedDelivery) string {
+	return filepath.Join(q.Directory, fmt.Sprintf("%s.json", delivery.ID))
+}
+
+// List returns the queued deliveries, oldest first
+func (q DeliveryQueue) List() ([]QueuedDelivery, error) {
+	deliveries := []QueuedDelivery{}
+	files, err := filepath.Glob(filepath.Join(q.Directory, "*.json"))
+	if err != nil {
+		return deliveries, err
+	}
+
+	sort.Strings(files)
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return deliveries, err
+		}
+
+		var delivery QueuedDelivery
+		if err := json.Unmarshal(b, &delivery); err != nil || delivery.ID == "" {
+			LogWarn(fmt.Sprintf("Removing invalid queued delivery %s", filepath.Base(file)))
+			os.Remove(file)
+			continue
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+// Remove removes the queued deliveries that match a filter
+func (q DeliveryQueue) Remove(match func(delivery QueuedDelivery) bool) error {
+	deliveries, err := q.List()
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		if !match(delivery) {
+			continue
+		}
+
+		if err := os.Remove(q.filename(delivery)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Flush retries the queued deliveries that are due, skipping the run if another process is already flushing the queue
+func (q DeliveryQueue) Flush() error {
+	if err := os.MkdirAll(q.Directory, 0700); err != nil {
+		return fmt.Errorf("Unable to create delivery queue directory: %w", err)
+	}
+
+	lock, err := os.OpenFile(filepath.Join(q.Directory, ".lock"), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("Unable to open delivery queue lock: %w", err)
+	}
+	defer lock.Close()
+
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil
+		}
+		return fmt.Errorf("Unable to lock delivery queue: %w", err)
+	}
+	defer syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+
+	deliveries, err := q.List()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, delivery := range deliveries {
+		if now.Before(delivery.NextAttemptAt) {
+			if q.Ordered {
+				return nil
+			}
+			continue
+		}
+
+		filename := q.filename(delivery)
+		if err := q.attempt(delivery); err != nil {
+			delivery.Attempts++
+			delivery.LastError = err.Error()
+			if delivery.Attempts >= DeliveryMaxAttempts {
+				LogWarn(fmt.Sprintf("Dropping %s after %d failed attempts: %s", delivery.Description, delivery.Attempts, err.Error()))
+				if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+				continue
+			}
+
+			delivery.NextAttemptAt = now.Add(DeliveryRetryBackoff(delivery.Attempts))
+			LogWarn(fmt.Sprintf("Unable to deliver %s, retrying after %s: %s", delivery.Description, delivery.NextAttemptAt.Format(time.RFC3339), err.Error()))
+			if err := q.Enqueue(delivery); err != nil {
+				return err
+			}
+			if q.Ordered {
+				return nil
+			}
+			continue
+		}
+
+		LogVerboseQuiet(fmt.Sprintf("Delivered %s", delivery.Description))
+		if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AppendJSONLine appends a value as a single line of json to a file, trimming the file to the most recent maxLines lines.
+// The file is locked while it is written, so concurrent commands neither interleave nor drop lines.
+func AppendJSONLine(filename string, v interface{}, maxLines int) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	lock, err := os.OpenFile(filename+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("Unable to open lock for %s: %w", filepath.Base(filename), err)
+	}
+	defer lock.Close()
+
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("Unable to lock %s: %w", filepath.Base(filename), err)
+	}
+	defer syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if maxLines <= 0 {
+		return nil
+	}
+
+	return trimLines(filename, maxLines)
+}
+
+// trimLines rewrites a file to contain only its last maxLines lines, and must be called with the file locked
+func trimLines(filename string, maxLines int) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+
+	lines := []string{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	f.Close()
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	// allow the file to grow past the limit before trimming, so it is not rewritten on every append
+	if len(lines) <= maxLines+maxLines/10 {
+		return nil
+	}
+
+	lines = lines[len(lines)-maxLines:]
+	tmpFile := filename + ".tmp"
+	if err := os.WriteFile(tmpFile, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, filename)
+}
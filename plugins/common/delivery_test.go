@@ -0,0 +1,61 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCommonAppendJSONLine(t *testing.T) {
+	RegisterTestingT(t)
+	filename := filepath.Join(t.TempDir(), "records.jsonl")
+	for i := 0; i < 12; i++ {
+		Expect(AppendJSONLine(filename, map[string]int{"i": i}, 10)).To(Succeed())
+	}
+
+	lines, err := FileToSlice(filename)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(lines).To(HaveLen(10))
+	Expect(lines[0]).To(Equal(`{"i":2}`))
+	Expect(lines[9]).To(Equal(`{"i":11}`))
+}
+
+func TestCommonDeliveryQueueFlush(t *testing.T) {
+	RegisterTestingT(t)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	queue := DeliveryQueue{Directory: t.TempDir(), Ordered: true}
+	now := time.Now().UTC()
+	Expect(queue.Enqueue(QueuedDelivery{ID: "1", Description: "delivery 1", Request: DeliveryRequest{URL: server.URL, Body: []byte("{}")}, NextAttemptAt: now})).To(Succeed())
+	Expect(queue.Enqueue(QueuedDelivery{ID: "2", Description: "delivery 2", Request: DeliveryRequest{URL: server.URL, Body: []byte("{}")}, NextAttemptAt: now})).To(Succeed())
+
+	// an ordered queue stops at the first failed delivery
+	Expect(queue.Flush()).To(Succeed())
+	Expect(requests).To(Equal(1))
+
+	deliveries, err := queue.List()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(deliveries).To(HaveLen(2))
+	Expect(deliveries[0].Attempts).To(Equal(1))
+	Expect(deliveries[0].NextAttemptAt).To(BeTemporally(">", now))
+
+	info, err := os.Stat(filepath.Join(queue.Directory, "1.json"))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+	Expect(queue.Remove(func(delivery QueuedDelivery) bool { return delivery.ID == "1" })).To(Succeed())
+	deliveries, err = queue.List()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(deliveries).To(HaveLen(1))
+	Expect(deliveries[0].ID).To(Equal("2"))
+}
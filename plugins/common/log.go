@@ -1,12 +1,14 @@
 package common
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 )
@@ -71,10 +73,145 @@ func (pw *PrefixingWriter) Write(p []byte) (int, error) {
 	return n, err
 }
 
+// LogRecord is a single line of json log output
+type LogRecord struct {
+	// Timestamp is the time the record was emitted
+	Timestamp time.Time `json:"timestamp"`
+
+	// Level is the level of the record, such as info, verbose, warn or error
+	Level string `json:"level"`
+
+	// Plugin is the plugin that emitted the record
+	Plugin string `json:"plugin,omitempty"`
+
+	// App is the app the record is for
+	App string `json:"app,omitempty"`
+
+	// Phase is the deploy phase the record was emitted in
+	Phase string `json:"phase,omitempty"`
+
+	// Event is set to phase.start or phase.end for records marking the boundaries of a deploy phase
+	Event string `json:"event,omitempty"`
+
+	// Message is the log message
+	Message string `json:"message"`
+
+	// StartedAt is the time the phase started, and is only set on phase.end records
+	StartedAt *time.Time `json:"started_at,omitempty"`
+
+	// DurationMs is the duration of the phase in milliseconds, and is only set on phase.end records
+	DurationMs *int64 `json:"duration_ms,omitempty"`
+}
+
+// LogPhase is a deploy phase that is being logged
+type LogPhase struct {
+	// App is the app being deployed
+	App string
+
+	// Name is the name of the phase
+	Name string
+
+	// StartedAt is the time the phase started
+	StartedAt time.Time
+
+	// previousPhase is the phase that was in progress when this phase started
+	previousPhase string
+}
+
+// IsJSONOutput returns whether log output should be emitted as json
+func IsJSONOutput() bool {
+	return os.Getenv("DOKKU_OUTPUT_FORMAT") == "json"
+}
+
+// getLogPluginName returns the name of the plugin the current executable belongs to
+func getLogPluginName() string {
+	parts := strings.Split(os.Args[0], "/")
+	for i, part := range parts {
+		if (part == "available" || part == "enabled") && i+1 < len(parts)-1 {
+			return parts[i+1]
+		}
+	}
+
+	return ""
+}
+
+// logJSON writes a log record as a single line of json
+func logJSON(w io.Writer, record LogRecord) {
+	record.Timestamp = time.Now().UTC()
+	record.Plugin = getLogPluginName()
+	if record.App == "" {
+		record.App = os.Getenv("DOKKU_OUTPUT_APP")
+	}
+	if record.App == "" {
+		record.App = os.Getenv("DOKKU_APP_NAME")
+	}
+	if record.Phase == "" {
+		record.Phase = os.Getenv("DOKKU_OUTPUT_PHASE")
+	}
+
+	// the encoder appends a trailing newline, ensuring one record per line
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(record)
+}
+
+// logText writes a prefixed log line, or a json record with the specified level when json output is enabled
+func logText(w io.Writer, level string, prefix string, text string) {
+	if IsJSONOutput() {
+		logJSON(w, LogRecord{Level: level, Message: strings.TrimRight(text, "\n")})
+		return
+	}
+
+	fmt.Fprintln(w, prefix+text)
+}
+
+// StartLogPhase marks the start of a deploy phase, emitting a phase.start record when json output is enabled.
+// Log records emitted by the current process and any processes it starts are tagged with the phase until it ends.
+func StartLogPhase(appName string, phase string) *LogPhase {
+	logPhase := &LogPhase{
+		App:           appName,
+		Name:          phase,
+		StartedAt:     time.Now().UTC(),
+		previousPhase: os.Getenv("DOKKU_OUTPUT_PHASE"),
+	}
+
+	os.Setenv("DOKKU_OUTPUT_APP", appName)
+	os.Setenv("DOKKU_OUTPUT_PHASE", phase)
+	if IsJSONOutput() {
+		logJSON(os.Stdout, LogRecord{
+			Level:   "info",
+			App:     appName,
+			Phase:   phase,
+			Event:   "phase.start",
+			Message: fmt.Sprintf("Starting %s phase", phase),
+		})
+	}
+
+	return logPhase
+}
+
+// End marks the end of the deploy phase, emitting a phase.end record when json output is enabled
+func (p *LogPhase) End() {
+	if IsJSONOutput() {
+		durationMs := time.Since(p.StartedAt).Milliseconds()
+		logJSON(os.Stdout, LogRecord{
+			Level:      "info",
+			App:        p.App,
+			Phase:      p.Name,
+			Event:      "phase.end",
+			Message:    fmt.Sprintf("Finished %s phase", p.Name),
+			StartedAt:  &p.StartedAt,
+			DurationMs: &durationMs,
+		})
+	}
+
+	os.Setenv("DOKKU_OUTPUT_PHASE", p.previousPhase)
+}
+
 // LogFail is the failure log formatter
 // prints text to stderr and exits with status 1
 func LogFail(text string) {
-	fmt.Fprintln(os.Stderr, fmt.Sprintf(" !     %s", text))
+	logText(os.Stderr, "error", " !     ", text)
 	os.Exit(1)
 }
 
@@ -87,11 +224,11 @@ func LogFailWithError(err error) {
 
 	if merr, ok := err.(*multierror.Error); ok {
 		for _, e := range merr.Errors {
-			fmt.Fprintf(os.Stderr, " !     %s\n", e.Error())
+			logText(os.Stderr, "error", " !     ", e.Error())
 		}
 	} else {
 		if err.Error() != "" {
-			fmt.Fprintf(os.Stderr, " !     %s\n", err.Error())
+			logText(os.Stderr, "error", " !     ", err.Error())
 		}
 	}
 	if errExit, ok := err.(ErrWithExitCode); ok {
@@ -105,7 +242,7 @@ func LogFailWithError(err error) {
 // The error message is not printed if DOKKU_QUIET_OUTPUT has any value
 func LogFailWithErrorQuiet(err error) {
 	if os.Getenv("DOKKU_QUIET_OUTPUT") == "" {
-		fmt.Fprintln(os.Stderr, fmt.Sprintf(" !     %s", err.Error()))
+		logText(os.Stderr, "error", " !     ", err.Error())
 	}
 	if errExit, ok := err.(ErrWithExitCode); ok {
 		os.Exit(errExit.ExitCode())
@@ -117,7 +254,7 @@ func LogFailWithErrorQuiet(err error) {
 // prints text to stderr and exits with status 1
 func LogFailQuiet(text string) {
 	if os.Getenv("DOKKU_QUIET_OUTPUT") == "" {
-		fmt.Fprintln(os.Stderr, fmt.Sprintf(" !     %s", text))
+		logText(os.Stderr, "error", " !     ", text)
 	}
 	os.Exit(1)
 }
@@ -136,7 +273,7 @@ func LogQuiet(text string) {
 
 // LogInfo1 is the info1 header formatter
 func LogInfo1(text string) {
-	fmt.Println(fmt.Sprintf("-----> %s", text))
+	logText(os.Stdout, "info", "-----> ", text)
 }
 
 // LogInfo1Quiet is the info1 header formatter (with quiet option)
@@ -148,7 +285,7 @@ func LogInfo1Quiet(text string) {
 
 // LogInfo2 is the info2 header formatter
 func LogInfo2(text string) {
-	fmt.Println(fmt.Sprintf("=====> %s", text))
+	logText(os.Stdout, "info", "=====> ", text)
 }
 
 // LogInfo2Quiet is the info2 header formatter (with quiet option)
@@ -161,13 +298,13 @@ func LogInfo2Quiet(text string) {
 // LogVerbose is the verbose log formatter
 // prints indented text to stdout
 func LogVerbose(text string) {
-	fmt.Println(fmt.Sprintf("       %s", text))
+	logText(os.Stdout, "verbose", "       ", text)
 }
 
 // LogVerboseStderr is the verbose log formatter
 // prints indented text to stderr
 func LogVerboseStderr(text string) {
-	fmt.Fprintln(os.Stderr, fmt.Sprintf(" !     %s", text))
+	logText(os.Stderr, "verbose", " !     ", text)
 }
 
 // LogVerboseQuiet is the verbose log formatter
@@ -228,15 +365,16 @@ func LogVerboseQuietContainerLogsTail(containerID string, lines int, tail bool)
 
 // LogWarn is the warning log formatter
 func LogWarn(text string) {
-	fmt.Fprintln(os.Stderr, fmt.Sprintf(" !     %s", text))
+	logText(os.Stderr, "warn", " !     ", text)
 }
 
 // LogExclaim is the log exclaim formatter
 func LogExclaim(text string) {
-	fmt.Println(fmt.Sprintf(" !     %s", text))
+	logText(os.Stdout, "warn", " !     ", text)
 }
 
 // LogStderr is the stderr log formatter
+// prints text to stderr as-is
 func LogStderr(text string) {
 	fmt.Fprintln(os.Stderr, text)
 }
@@ -244,6 +382,6 @@ func LogStderr(text string) {
 // LogDebug is the debug log formatter
 func LogDebug(text string) {
 	if os.Getenv("DOKKU_TRACE") == "1" {
-		fmt.Fprintln(os.Stderr, fmt.Sprintf(" ?     %s", strings.TrimPrefix(text, " ?     ")))
+		logText(os.Stderr, "debug", " ?     ", strings.TrimPrefix(text, " ?     "))
 	}
 }
@@ -0,0 +1,76 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCommonLogTextFormat(t *testing.T) {
+	RegisterTestingT(t)
+	t.Setenv("DOKKU_OUTPUT_FORMAT", "")
+
+	var b bytes.Buffer
+	logText(&b, "info", "-----> ", "Deploying")
+	Expect(b.String()).To(Equal("-----> Deploying\n"))
+}
+
+func TestCommonLogJSONFormat(t *testing.T) {
+	RegisterTestingT(t)
+	t.Setenv("DOKKU_OUTPUT_FORMAT", "json")
+	t.Setenv("DOKKU_OUTPUT_APP", testAppName)
+	t.Setenv("DOKKU_OUTPUT_PHASE", "build")
+
+	var b bytes.Buffer
+	logText(&b, "warn", " !     ", "Detected <nothing>")
+	logText(&b, "info", "-----> ", "Deploying")
+
+	lines := bytes.Split(bytes.TrimSpace(b.Bytes()), []byte("\n"))
+	Expect(lines).To(HaveLen(2))
+
+	var record LogRecord
+	Expect(json.Unmarshal(lines[0], &record)).To(Succeed())
+	Expect(record.Level).To(Equal("warn"))
+	Expect(record.App).To(Equal(testAppName))
+	Expect(record.Phase).To(Equal("build"))
+	Expect(record.Message).To(Equal("Detected <nothing>"))
+	Expect(record.Timestamp.IsZero()).To(BeFalse())
+	Expect(record.DurationMs).To(BeNil())
+}
+
+func TestCommonLogPhase(t *testing.T) {
+	RegisterTestingT(t)
+	t.Setenv("DOKKU_OUTPUT_FORMAT", "")
+	t.Setenv("DOKKU_OUTPUT_APP", "")
+	t.Setenv("DOKKU_OUTPUT_PHASE", "release")
+
+	phase := StartLogPhase(testAppName, "build")
+	Expect(os.Getenv("DOKKU_OUTPUT_APP")).To(Equal(testAppName))
+	Expect(os.Getenv("DOKKU_OUTPUT_PHASE")).To(Equal("build"))
+
+	phase.End()
+	Expect(os.Getenv("DOKKU_OUTPUT_PHASE")).To(Equal("release"))
+}
+
+func TestCommonLogRawOutput(t *testing.T) {
+	RegisterTestingT(t)
+	t.Setenv("DOKKU_OUTPUT_FORMAT", "json")
+	t.Setenv("DOKKU_QUIET_OUTPUT", "")
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	Expect(err).NotTo(HaveOccurred())
+	os.Stdout = w
+	Log(`{"app-dir":"/home/dokku/test-app-1"}`)
+	LogQuiet("test-app-1")
+	os.Stdout = stdout
+	Expect(w.Close()).To(Succeed())
+
+	var b bytes.Buffer
+	_, err = b.ReadFrom(r)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(b.String()).To(Equal("{\"app-dir\":\"/home/dokku/test-app-1\"}\ntest-app-1\n"))
+}
@@ -25,6 +25,9 @@ type PlugnTriggerInput struct {
 	// Stdin is the stdin of the command
 	Stdin io.Reader
 
+	// StdoutWriter is the writer to write stdout to
+	StdoutWriter io.Writer
+
 	// StreamStdio determines whether to stream the stdio of the trigger
 	StreamStdio bool
 
@@ -53,6 +56,7 @@ func CallPlugnTriggerWithContext(ctx context.Context, input PlugnTriggerInput) (
 		DisableStdioBuffer: input.DisableStdioBuffer,
 		Env:                input.Env,
 		Stdin:              input.Stdin,
+		StdoutWriter:       input.StdoutWriter,
 		StreamStdio:        input.StreamStdio,
 		StreamStdout:       input.StreamStdout,
 		StreamStderr:       input.StreamStderr,
@@ -31,11 +31,16 @@ func GetWithDefault(appName string, key string, defaultValue string) (value stri
 
 // SetMany variables in the environment. If appName is empty the global config is used. If restart is true the app is restarted.
 func SetMany(appName string, entries map[string]string, replace bool, restart bool) (err error) {
+	return setMany(appName, entries, replace, restart, "set")
+}
+
+func setMany(appName string, entries map[string]string, replace bool, restart bool, operation string) (err error) {
 	global := appName == "" || appName == "--global"
 	env, err := loadAppOrGlobalEnv(appName)
 	if err != nil {
 		return
 	}
+	before := copyEnvMap(env.Map())
 	keys := make([]string, 0, len(entries))
 	for k := range entries {
 		if err = validateKey(k); err != nil {
@@ -55,11 +60,14 @@ func SetMany(appName string, entries map[string]string, replace bool, restart bo
 		if os.Getenv("DOKKU_QUIET_OUTPUT") == "" {
 			fmt.Println(prettyPrintEnvEntries("       ", entries))
 		}
-		env.Write()
+		if err = env.Write(); err != nil {
+			return
+		}
 		common.SetPermissions(common.SetPermissionInput{
 			Filename: env.Filename(),
 			Mode:     os.FileMode(0600),
 		})
+		logRevisionError(recordRevision(appName, "", operation, before, env.Map()))
 		triggerUpdate(appName, "set", keys)
 	}
 	if !global && restart && env.GetBoolDefault("DOKKU_APP_RESTORE", true) {
@@ -75,6 +83,7 @@ func UnsetMany(appName string, keys []string, restart bool) (err error) {
 	if err != nil {
 		return
 	}
+	before := copyEnvMap(env.Map())
 	var changed = false
 	for _, k := range keys {
 		if err = validateKey(k); err != nil {
@@ -91,11 +100,14 @@ func UnsetMany(appName string, keys []string, restart bool) (err error) {
 		}
 	}
 	if changed {
-		env.Write()
+		if err = env.Write(); err != nil {
+			return
+		}
 		common.SetPermissions(common.SetPermissionInput{
 			Filename: env.Filename(),
 			Mode:     os.FileMode(0600),
 		})
+		logRevisionError(recordRevision(appName, "", "unset", before, env.Map()))
 		triggerUpdate(appName, "unset", keys)
 	}
 	if !global && restart && env.GetBoolDefault("DOKKU_APP_RESTORE", true) {
@@ -106,11 +118,16 @@ func UnsetMany(appName string, keys []string, restart bool) (err error) {
 
 // UnsetAll removes all config keys
 func UnsetAll(appName string, restart bool) (err error) {
+	return unsetAll(appName, restart, "clear")
+}
+
+func unsetAll(appName string, restart bool, operation string) (err error) {
 	global := appName == "" || appName == "--global"
 	env, err := loadAppOrGlobalEnv(appName)
 	if err != nil {
 		return
 	}
+	before := copyEnvMap(env.Map())
 	var changed = false
 	for k := range env.Map() {
 		common.LogInfo1Quiet(fmt.Sprintf("Unsetting %s", k))
@@ -118,11 +135,14 @@ func UnsetAll(appName string, restart bool) (err error) {
 		changed = true
 	}
 	if changed {
-		env.Write()
+		if err = env.Write(); err != nil {
+			return
+		}
 		common.SetPermissions(common.SetPermissionInput{
 			Filename: env.Filename(),
 			Mode:     os.FileMode(0600),
 		})
+		logRevisionError(recordRevision(appName, "", operation, before, env.Map()))
 		triggerUpdate(appName, "clear", []string{})
 	}
 	if !global && restart && env.GetBoolDefault("DOKKU_APP_RESTORE", true) {
@@ -131,6 +151,46 @@ func UnsetAll(appName string, restart bool) (err error) {
 	return
 }
 
+// Rollback restores the environment recorded in the given revision. If appName is empty the global config is used. If restart is true the app is restarted.
+func Rollback(appName string, revision int, restart bool) error {
+	r, err := GetRevision(appName, revision)
+	if err != nil {
+		return err
+	}
+
+	if !r.Restorable {
+		return fmt.Errorf("Revision %d cannot be restored, the config values for it were not stored", revision)
+	}
+
+	if r.ProcessType != "" {
+		return rollbackProcess(appName, r, restart)
+	}
+
+	env, err := loadAppOrGlobalEnv(appName)
+	if err != nil {
+		return err
+	}
+
+	if len(diffEnvMaps(env.Map(), r.Env)) == 0 {
+		common.LogInfo1Quiet(fmt.Sprintf("Environment already matches revision %d", revision))
+		return nil
+	}
+
+	common.LogInfo1Quiet(fmt.Sprintf("Rolling back config vars to revision %d", revision))
+	operation := fmt.Sprintf("rollback:%d", revision)
+	if len(r.Env) == 0 {
+		return unsetAll(appName, restart, operation)
+	}
+
+	return setMany(appName, r.Env, true, restart, operation)
+}
+
+func logRevisionError(err error) {
+	if err != nil {
+		common.LogWarn(fmt.Sprintf("Failure while recording config history: %s", err))
+	}
+}
+
 func triggerRestart(appName string) {
 	if !common.IsDeployed(appName) {
 		return
@@ -159,10 +219,15 @@ func triggerUpdate(appName string, operation string, args []string) {
 	}
 }
 
-// getEnvironment for the given app (global config if appName is empty). Merge with global environment if merged is true.
-func getEnvironment(appName string, merged bool) (env *Env) {
+// getEnvironment for the given app (global config if appName is empty). If processType is set, the environment
+// for the process type is used instead. Merge with the global and app environments if merged is true.
+func getEnvironment(appName string, processType string, merged bool) (env *Env) {
 	var err error
-	if appName != "" && merged {
+	if processType != "" && merged {
+		env, err = LoadMergedProcessEnv(appName, processType)
+	} else if processType != "" {
+		env, err = LoadProcessEnv(appName, processType)
+	} else if appName != "" && merged {
 		env, err = LoadMergedAppEnv(appName)
 	} else {
 		env, err = loadAppOrGlobalEnv(appName)
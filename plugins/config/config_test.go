@@ -1,7 +1,11 @@
 package config
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -75,19 +79,19 @@ func TestConfigSetMany(t *testing.T) {
 	expectValue(testAppName, "testKey", "TESTING")
 
 	vals := []string{"testKey=updated", "testKey2=new"}
-	Expect(CommandSet(testAppName, vals, false, true, false)).To(Succeed())
+	Expect(CommandSet(testAppName, vals, false, true, false, false, "")).To(Succeed())
 	expectValue(testAppName, "testKey", "updated")
 	expectValue(testAppName, "testKey2", "new")
 
 	vals = []string{"testKey=updated_global", "testKey2=new_global"}
-	Expect(CommandSet("", vals, true, true, false)).To(Succeed())
+	Expect(CommandSet("", vals, true, true, false, false, "")).To(Succeed())
 	expectValue("", "testKey", "updated_global")
 	expectValue("", "testKey2", "new_global")
 	expectValue("", "globalKey", "GLOBAL_VALUE")
 	expectValue(testAppName, "testKey", "updated")
 	expectValue(testAppName, "testKey2", "new")
 
-	Expect(CommandSet(testAppName+"does_not_exist", vals, false, true, false)).ToNot(Succeed())
+	Expect(CommandSet(testAppName+"does_not_exist", vals, false, true, false, false, "")).ToNot(Succeed())
 }
 
 func TestConfigUnsetAll(t *testing.T) {
@@ -117,15 +121,15 @@ func TestConfigUnsetMany(t *testing.T) {
 	expectValue("", "testKey", "GLOBAL_TESTING")
 
 	keys := []string{"testKey", "noKey"}
-	Expect(CommandUnset(testAppName, keys, false, true)).To(Succeed())
+	Expect(CommandUnset(testAppName, keys, false, true, false, "")).To(Succeed())
 	expectNoValue(testAppName, "testKey")
 	expectValue("", "testKey", "GLOBAL_TESTING")
 
-	Expect(CommandUnset(testAppName, keys, false, true)).To(Succeed())
+	Expect(CommandUnset(testAppName, keys, false, true, false, "")).To(Succeed())
 	expectNoValue(testAppName, "testKey")
 	expectNoValue(testAppName, "globalKey")
 
-	Expect(CommandUnset(testAppName+"does-not-exist", keys, false, true)).ToNot(Succeed())
+	Expect(CommandUnset(testAppName+"does-not-exist", keys, false, true, false, "")).ToNot(Succeed())
 }
 
 func TestConfigImport(t *testing.T) {
@@ -204,6 +208,352 @@ func TestConfigImportJSON(t *testing.T) {
 	expectNoValue(testAppName, "testKey3")
 }
 
+func TestConfigImportFormats(t *testing.T) {
+	RegisterTestingT(t)
+	Expect(setupTests()).To(Succeed())
+	Expect(setupTestApp()).To(Succeed())
+	defer teardownTestApp()
+
+	envMap, err := parseEnvFormat("yaml", []byte("database:\n  host: db.internal\n  port: 5432\nDEBUG: true\n"))
+	Expect(err).To(Succeed())
+	Expect(envMap).To(Equal(map[string]string{"database_host": "db.internal", "database_port": "5432", "DEBUG": "true"}))
+
+	envMap, err = parseEnvFormat("toml", []byte("DEBUG = false\n\n[database]\nhost = \"db.internal\"\n"))
+	Expect(err).To(Succeed())
+	Expect(envMap).To(Equal(map[string]string{"database_host": "db.internal", "DEBUG": "false"}))
+
+	_, err = parseEnvFormat("yaml", []byte("HOSTS:\n  - a\n  - b\n"))
+	Expect(err).To(MatchError(ContainSubstring("Unsupported list value for key 'HOSTS'")))
+	_, err = parseEnvFormat("yaml", []byte("A_B: one\nA:\n  B: two\n"))
+	Expect(err).To(MatchError(ContainSubstring("Duplicate key 'A_B'")))
+	_, err = parseEnvFormat("xml", []byte(""))
+	Expect(err).To(MatchError(ContainSubstring("Unknown format: xml")))
+
+	env, err := LoadAppEnv(testAppName)
+	Expect(err).To(Succeed())
+	env.Set("testKey2", "line1\nline2")
+	Expect(env.Write()).To(Succeed())
+
+	for _, format := range []string{"k8s-configmap", "k8s-secret", "toml", "yaml"} {
+		exportTypes := map[string]ExportFormat{
+			"k8s-configmap": ExportFormatKubernetesConfigMap,
+			"k8s-secret":    ExportFormatKubernetesSecret,
+			"toml":          ExportFormatTOML,
+			"yaml":          ExportFormatYAML,
+		}
+		envMap, err := parseEnvFormat(format, []byte(env.Export(exportTypes[format])))
+		Expect(err).To(Succeed())
+		Expect(envMap).To(Equal(env.Map()))
+	}
+
+	_, err = parseEnvFormat("k8s-configmap", []byte(env.Export(ExportFormatKubernetesSecret)))
+	Expect(err).To(MatchError(ContainSubstring("Expected a ConfigMap manifest, got kind 'Secret'")))
+
+	tempFile, err := os.CreateTemp("", "test-config-import-*.yaml")
+	Expect(err).To(Succeed())
+	defer os.Remove(tempFile.Name())
+	_, err = tempFile.WriteString(env.Export(ExportFormatKubernetesSecret))
+	Expect(err).To(Succeed())
+	tempFile.Close()
+
+	Expect(CommandUnset(testAppName, []string{"testKey", "testKey2"}, false, true, false, "")).To(Succeed())
+	Expect(CommandImport(testAppName, false, false, true, "k8s-secret", tempFile.Name())).To(Succeed())
+	expectValue(testAppName, "testKey", "TESTING")
+	expectValue(testAppName, "testKey2", "line1\nline2")
+}
+
+func TestConfigHistory(t *testing.T) {
+	RegisterTestingT(t)
+	Expect(setupTests()).To(Succeed())
+	Expect(setupTestApp()).To(Succeed())
+	defer teardownTestApp()
+
+	Expect(CommandSet(testAppName, []string{"testKey=updated", "testKey2=new"}, false, true, false, false, "")).To(Succeed())
+	Expect(CommandUnset(testAppName, []string{"testKey2"}, false, true, false, "")).To(Succeed())
+	Expect(CommandSet(testAppName, []string{"testKey=updated"}, false, true, false, false, "")).To(Succeed())
+
+	revisions, err := GetRevisions(testAppName)
+	Expect(err).To(Succeed())
+	Expect(revisions).To(HaveLen(2))
+	Expect(revisions[0].Revision).To(Equal(1))
+	Expect(revisions[0].Operation).To(Equal("set"))
+	Expect(revisions[0].Keys()).To(Equal([]string{"testKey", "testKey2"}))
+	Expect(revisions[0].Changes[0].OldHash).To(Equal(hashValue("TESTING")))
+	Expect(revisions[1].Operation).To(Equal("unset"))
+	Expect(revisions[1].Changes[0].Action).To(Equal("removed"))
+	Expect(revisions[1].Fingerprints).To(Equal(map[string]string{"testKey": hashValue("updated")}))
+
+	// values are stored in a separate env file, and never in the revision itself
+	Expect(revisions[0].Restorable).To(BeTrue())
+	Expect(revisions[0].Env).To(Equal(map[string]string{"testKey": "updated", "testKey2": "new"}))
+	b, err := os.ReadFile(filepath.Join(getHistoryDirectory(testAppName), "1.json"))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(string(b)).NotTo(ContainSubstring("updated"))
+	info, err := os.Stat(getRevisionEnvFilename(testAppName, 1))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+	Expect(CommandRollback(testAppName, false, "1", true)).To(Succeed())
+	expectValue(testAppName, "testKey2", "new")
+	Expect(CommandUnset(testAppName, []string{"testKey2"}, false, true, false, "")).To(Succeed())
+
+	Expect(os.Remove(getRevisionEnvFilename(testAppName, 1))).To(Succeed())
+	Expect(CommandRollback(testAppName, false, "1", true)).To(MatchError(ContainSubstring("cannot be restored")))
+
+	defer os.Unsetenv("DOKKU_CONFIG_ENCRYPTION")
+	defer os.Unsetenv("DOKKU_CONFIG_KEYFILE")
+	keyfile := filepath.Join(t.TempDir(), "config.key")
+	Expect(os.WriteFile(keyfile, []byte(strings.Repeat("ab", 32)), 0600)).To(Succeed())
+	os.Setenv("DOKKU_CONFIG_ENCRYPTION", "keyfile")
+	os.Setenv("DOKKU_CONFIG_KEYFILE", keyfile)
+
+	Expect(CommandSet(testAppName, []string{"testKey2=new"}, false, true, false, false, "")).To(Succeed())
+	Expect(CommandSet(testAppName, []string{"testKey2=newer"}, false, true, false, false, "")).To(Succeed())
+	b, err = os.ReadFile(getRevisionEnvFilename(testAppName, 6))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(string(b)).NotTo(ContainSubstring("newer"))
+	Expect(CommandRollback(testAppName, false, "5", true)).To(Succeed())
+	expectValue(testAppName, "testKey", "updated")
+	expectValue(testAppName, "testKey2", "new")
+
+	revisions, err = GetRevisions(testAppName)
+	Expect(err).To(Succeed())
+	Expect(revisions).To(HaveLen(7))
+	Expect(revisions[4].Restorable).To(BeTrue())
+	Expect(revisions[6].Operation).To(Equal("rollback:5"))
+
+	Expect(CommandRollback(testAppName, false, "10", true)).ToNot(Succeed())
+	Expect(CommandRollback(testAppName, false, "invalid", true)).ToNot(Succeed())
+}
+
+func TestConfigEncryption(t *testing.T) {
+	RegisterTestingT(t)
+	Expect(setupTests()).To(Succeed())
+	Expect(setupTestApp()).To(Succeed())
+	defer teardownTestApp()
+	defer os.Unsetenv("DOKKU_CONFIG_ENCRYPTION")
+	defer os.Unsetenv("DOKKU_CONFIG_KEYFILE")
+	defer os.Unsetenv("DOKKU_CONFIG_KMS_URL")
+
+	keyfile := filepath.Join(t.TempDir(), "config.key")
+	Expect(os.WriteFile(keyfile, []byte(strings.Repeat("ab", 32)+"\n"), 0600)).To(Succeed())
+	os.Setenv("DOKKU_CONFIG_ENCRYPTION", "keyfile")
+	os.Setenv("DOKKU_CONFIG_KEYFILE", keyfile)
+
+	Expect(SetMany(testAppName, map[string]string{"SECRET_KEY": "hunter2"}, false, false)).To(Succeed())
+	b, err := os.ReadFile(filepath.Join(testAppDir, "ENV"))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(string(b)).NotTo(ContainSubstring("hunter2"))
+	Expect(string(b)).To(ContainSubstring(encryptedValuePrefix + "keyfile:"))
+
+	env, err := LoadAppEnv(testAppName)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(env.IsSealed("SECRET_KEY")).To(BeTrue())
+	Expect(env.Export(ExportFormatDockerArgs)).To(ContainSubstring("--env=SECRET_KEY='hunter2'"))
+	Expect(env.MaskedMap()["SECRET_KEY"]).To(Equal("********"))
+	expectValue(testAppName, "SECRET_KEY", "hunter2")
+
+	revisions, err := GetRevisions(testAppName)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(revisions[len(revisions)-1].Env["SECRET_KEY"]).To(Equal("hunter2"))
+
+	Expect(os.WriteFile(keyfile, []byte(strings.Repeat("cd", 32)), 0600)).To(Succeed())
+	_, err = LoadAppEnv(testAppName)
+	Expect(err).To(HaveOccurred())
+	Expect(os.WriteFile(keyfile, []byte(strings.Repeat("ab", 32)), 0600)).To(Succeed())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]string
+		Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+		switch r.URL.Path {
+		case "/encrypt":
+			json.NewEncoder(w).Encode(map[string]string{"ciphertext": "wrapped:" + request["plaintext"]})
+		case "/decrypt":
+			json.NewEncoder(w).Encode(map[string]string{"plaintext": strings.TrimPrefix(request["ciphertext"], "wrapped:")})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	os.Setenv("DOKKU_CONFIG_ENCRYPTION", "kms")
+	os.Setenv("DOKKU_CONFIG_KMS_URL", server.URL)
+	Expect(SetMany(testAppName, map[string]string{"SECRET_KEY": "hunter3"}, true, false)).To(Succeed())
+	b, err = os.ReadFile(filepath.Join(testAppDir, "ENV"))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(string(b)).NotTo(ContainSubstring("hunter3"))
+	expectValue(testAppName, "SECRET_KEY", "hunter3")
+
+	os.Unsetenv("DOKKU_CONFIG_ENCRYPTION")
+	Expect(SetMany(testAppName, map[string]string{"OTHER_KEY": "value"}, false, false)).To(Succeed())
+	b, err = os.ReadFile(filepath.Join(testAppDir, "ENV"))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(string(b)).To(ContainSubstring("hunter3"))
+}
+
+func TestConfigReferences(t *testing.T) {
+	RegisterTestingT(t)
+	Expect(setupTests()).To(Succeed())
+	Expect(setupTestApp()).To(Succeed())
+	defer teardownTestApp()
+
+	otherAppName := "test-app-2"
+	otherAppDir := strings.Join([]string{dokkuRoot, otherAppName}, "/")
+	Expect(os.MkdirAll(otherAppDir, 0766)).To(Succeed())
+	defer os.RemoveAll(otherAppDir)
+
+	Expect(SetMany(otherAppName, map[string]string{"DATABASE_URL": "postgres://db/${global:globalKey}"}, false, false)).To(Succeed())
+	Expect(SetMany(testAppName, map[string]string{"DATABASE_URL": "${app:test-app-2:DATABASE_URL}?sslmode=disable"}, false, false)).To(Succeed())
+
+	env, err := LoadMergedAppEnv(testAppName)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(env.GetDefault("DATABASE_URL", "")).To(Equal("postgres://db/GLOBAL_VALUE?sslmode=disable"))
+
+	env, err = LoadAppEnv(testAppName)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(env.GetDefault("DATABASE_URL", "")).To(Equal("${app:test-app-2:DATABASE_URL}?sslmode=disable"))
+
+	dependents, err := getDependentApps(otherAppName, []string{"DATABASE_URL"})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(dependents).To(Equal([]string{testAppName}))
+
+	dependents, err = getDependentApps("--global", []string{"globalKey"})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(dependents).To(ContainElements(testAppName, otherAppName))
+
+	Expect(SetMany(otherAppName, map[string]string{"DATABASE_URL": "${app:test-app-1:DATABASE_URL}"}, false, false)).To(Succeed())
+	_, err = LoadMergedAppEnv(testAppName)
+	Expect(err).To(MatchError(ContainSubstring("Config reference cycle detected")))
+
+	Expect(SetMany(otherAppName, map[string]string{"DATABASE_URL": "${app:test-app-2:MISSING}"}, false, false)).To(Succeed())
+	env, err = LoadMergedAppEnv(testAppName)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(env.GetDefault("DATABASE_URL", "")).To(Equal("${app:test-app-2:MISSING}?sslmode=disable"))
+
+	Expect(SetMany(testAppName, map[string]string{"DATABASE_URL": "${app:missing-app:DATABASE_URL}"}, false, false)).To(Succeed())
+	env, err = LoadMergedAppEnv(testAppName)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(env.GetDefault("DATABASE_URL", "")).To(Equal("${app:missing-app:DATABASE_URL}"))
+
+	dependents, err = getDependentApps("missing-app", []string{"DATABASE_URL"})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(dependents).To(Equal([]string{testAppName}))
+}
+
+func TestConfigSchema(t *testing.T) {
+	RegisterTestingT(t)
+	Expect(setupTests()).To(Succeed())
+	Expect(setupTestApp()).To(Succeed())
+	defer teardownTestApp()
+
+	Expect(CommandSchemaSet(testAppName, "WEB_CONCURRENCY", SchemaRule{Type: "int", Default: "2"})).To(Succeed())
+	Expect(CommandSchemaSet(testAppName, "LOG_LEVEL", SchemaRule{Type: "enum", Values: []string{"debug", "info"}})).To(Succeed())
+	Expect(CommandSchemaSet(testAppName, "SECRET_KEY_BASE", SchemaRule{Type: "regex", Pattern: "[a-f0-9]{8}", Required: true})).To(Succeed())
+	Expect(CommandSchemaSet(testAppName, "BAD", SchemaRule{Type: "enum"})).NotTo(Succeed())
+	Expect(CommandSchemaSet(testAppName, "BAD", SchemaRule{Type: "int", Default: "abc"})).NotTo(Succeed())
+
+	Expect(CommandSet(testAppName, []string{"WEB_CONCURRENCY=abc"}, false, true, false, false, "")).To(MatchError(ContainSubstring("expected an int")))
+	Expect(CommandSet(testAppName, []string{"LOG_LEVEL=warn"}, false, true, false, false, "")).To(MatchError(ContainSubstring("expected one of debug, info")))
+	Expect(CommandSet(testAppName, []string{"SECRET_KEY_BASE="}, false, true, false, false, "")).To(MatchError(ContainSubstring("value is required")))
+	Expect(CommandSet(testAppName, []string{"SECRET_KEY_BASE=abcdef012"}, false, true, false, false, "")).NotTo(Succeed())
+	expectNoValue(testAppName, "WEB_CONCURRENCY")
+
+	Expect(validateEnvAgainstSchema(testAppName)).To(MatchError(ContainSubstring("Missing required config vars: SECRET_KEY_BASE")))
+	expectValue(testAppName, "WEB_CONCURRENCY", "2")
+
+	Expect(CommandSet(testAppName, []string{"SECRET_KEY_BASE=abcdef01", "LOG_LEVEL=info"}, false, true, false, false, "")).To(Succeed())
+	Expect(validateEnvAgainstSchema(testAppName)).To(Succeed())
+
+	Expect(CommandSchemaUnset(testAppName, "LOG_LEVEL")).To(Succeed())
+	Expect(CommandSchemaUnset(testAppName, "LOG_LEVEL")).NotTo(Succeed())
+	Expect(CommandSet(testAppName, []string{"LOG_LEVEL=warn"}, false, true, false, false, "")).To(Succeed())
+}
+
+func TestConfigProcessHistory(t *testing.T) {
+	RegisterTestingT(t)
+	Expect(setupTests()).To(Succeed())
+	Expect(setupTestApp()).To(Succeed())
+	defer teardownTestApp()
+
+	Expect(SetManyProcess(testAppName, "worker", map[string]string{"WEB_CONCURRENCY": "1"}, false)).To(Succeed())
+	Expect(SetManyProcess(testAppName, "worker", map[string]string{"WEB_CONCURRENCY": "2"}, false)).To(Succeed())
+	Expect(UnsetManyProcess(testAppName, "worker", []string{"WEB_CONCURRENCY"}, false)).To(Succeed())
+
+	revisions, err := GetRevisions(testAppName)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(revisions).To(HaveLen(3))
+	Expect(revisions[0].ProcessType).To(Equal("worker"))
+	Expect(revisions[0].Operation).To(Equal("set"))
+	Expect(revisions[2].Operation).To(Equal("unset"))
+	Expect(revisions[2].Keys()).To(Equal([]string{"WEB_CONCURRENCY"}))
+	Expect(Rollback(testAppName, revisions[1].Revision, false)).To(Succeed())
+	env, err := LoadProcessEnv(testAppName, "worker")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(env.GetDefault("WEB_CONCURRENCY", "")).To(Equal("2"))
+	Expect(UnsetManyProcess(testAppName, "worker", []string{"WEB_CONCURRENCY"}, false)).To(Succeed())
+
+	defer os.Unsetenv("DOKKU_CONFIG_ENCRYPTION")
+	defer os.Unsetenv("DOKKU_CONFIG_KEYFILE")
+	keyfile := filepath.Join(t.TempDir(), "config.key")
+	Expect(os.WriteFile(keyfile, []byte(strings.Repeat("ab", 32)), 0600)).To(Succeed())
+	os.Setenv("DOKKU_CONFIG_ENCRYPTION", "keyfile")
+	os.Setenv("DOKKU_CONFIG_KEYFILE", keyfile)
+
+	Expect(SetManyProcess(testAppName, "worker", map[string]string{"WEB_CONCURRENCY": "1"}, false)).To(Succeed())
+	Expect(UnsetManyProcess(testAppName, "worker", []string{"WEB_CONCURRENCY"}, false)).To(Succeed())
+	revisions, err = GetRevisions(testAppName)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(revisions).To(HaveLen(7))
+
+	Expect(Rollback(testAppName, revisions[5].Revision, false)).To(Succeed())
+	env, err = LoadProcessEnv(testAppName, "worker")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(env.GetDefault("WEB_CONCURRENCY", "")).To(Equal("1"))
+	expectValue(testAppName, "testKey", "TESTING")
+
+	revisions, err = GetRevisions(testAppName)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(revisions).To(HaveLen(8))
+	Expect(revisions[7].ProcessType).To(Equal("worker"))
+	Expect(revisions[7].Operation).To(Equal("rollback:6"))
+}
+
+func TestConfigProcessEnv(t *testing.T) {
+	RegisterTestingT(t)
+	Expect(setupTests()).To(Succeed())
+	Expect(setupTestApp()).To(Succeed())
+	defer teardownTestApp()
+
+	Expect(CommandSet(testAppName, []string{"WEB_CONCURRENCY=4", "testKey=WORKER"}, false, true, false, false, "worker")).To(Succeed())
+	Expect(CommandSet(testAppName, []string{"MALLOC_ARENA_MAX=${global:globalKey}-2"}, false, true, false, false, "worker")).To(Succeed())
+	Expect(CommandSet("", []string{"WEB_CONCURRENCY=4"}, true, true, false, false, "worker")).NotTo(Succeed())
+	Expect(CommandSet(testAppName, []string{"WEB_CONCURRENCY=4"}, false, true, false, false, "bad/type")).NotTo(Succeed())
+	expectValue(testAppName, "testKey", "TESTING")
+	expectNoValue(testAppName, "WEB_CONCURRENCY")
+
+	processTypes, err := GetProcessTypesWithConfig(testAppName)
+	Expect(err).To(Succeed())
+	Expect(processTypes).To(Equal([]string{"worker"}))
+
+	env, err := LoadMergedProcessEnv(testAppName, "worker")
+	Expect(err).To(Succeed())
+	Expect(env.GetDefault("WEB_CONCURRENCY", "")).To(Equal("4"))
+	Expect(env.GetDefault("testKey", "")).To(Equal("WORKER"))
+	Expect(env.GetDefault("globalKey", "")).To(Equal("GLOBAL_VALUE"))
+	Expect(env.GetDefault("MALLOC_ARENA_MAX", "")).To(Equal("GLOBAL_VALUE-2"))
+
+	env, err = LoadMergedProcessEnv(testAppName, "web")
+	Expect(err).To(Succeed())
+	Expect(env.GetDefault("testKey", "")).To(Equal("TESTING"))
+	_, ok := env.Get("WEB_CONCURRENCY")
+	Expect(ok).To(Equal(false))
+
+	Expect(CommandUnset(testAppName, []string{"WEB_CONCURRENCY", "testKey", "MALLOC_ARENA_MAX"}, false, true, false, "worker")).To(Succeed())
+	processTypes, err = GetProcessTypesWithConfig(testAppName)
+	Expect(err).To(Succeed())
+	Expect(processTypes).To(BeEmpty())
+}
+
 func TestEnvironmentLoading(t *testing.T) {
 	RegisterTestingT(t)
 	Expect(setupTests()).To(Succeed())
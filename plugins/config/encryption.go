@@ -0,0 +1,280 @@
+package config
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// encryptedValuePrefix is prepended to every config value that is encrypted at rest
+const encryptedValuePrefix = "dokku:enc:v1:"
+
+// KeyProvider provides the keys used to encrypt config values at rest
+type KeyProvider interface {
+	// Name returns the name of the key provider, which is stored alongside each encrypted value
+	Name() string
+
+	// DataKey returns a key to encrypt values with, along with a wrapped form of
+	// the key that is stored alongside each encrypted value
+	DataKey() (*[32]byte, string, error)
+
+	// UnwrapKey returns the key for the wrapped form stored alongside an encrypted value
+	UnwrapKey(wrapped string) (*[32]byte, error)
+}
+
+// keyfileProvider encrypts values with a key read from a file on disk
+type keyfileProvider struct {
+	filename string
+}
+
+// Name returns the name of the key provider
+func (p keyfileProvider) Name() string {
+	return "keyfile"
+}
+
+// DataKey returns the key from the keyfile
+func (p keyfileProvider) DataKey() (*[32]byte, string, error) {
+	key, err := p.readKey()
+	return key, "", err
+}
+
+// UnwrapKey returns the key from the keyfile
+func (p keyfileProvider) UnwrapKey(wrapped string) (*[32]byte, error) {
+	return p.readKey()
+}
+
+func (p keyfileProvider) readKey() (*[32]byte, error) {
+	b, err := os.ReadFile(p.filename)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read config encryption keyfile: %w", err)
+	}
+
+	contents := strings.TrimSpace(string(b))
+	decoded, err := hex.DecodeString(contents)
+	if err != nil {
+		decoded, err = base64.StdEncoding.DecodeString(contents)
+	}
+	if err != nil {
+		decoded = b
+	}
+
+	if len(decoded) != 32 {
+		return nil, fmt.Errorf("Invalid config encryption keyfile %s, expected a 32 byte key", p.filename)
+	}
+
+	var key [32]byte
+	copy(key[:], decoded)
+	return &key, nil
+}
+
+// kmsProvider encrypts values with a random data key that is wrapped by an http key management service
+type kmsProvider struct {
+	url   string
+	token string
+}
+
+// Name returns the name of the key provider
+func (p kmsProvider) Name() string {
+	return "kms"
+}
+
+// DataKey generates a random key and wraps it via the key management service
+func (p kmsProvider) DataKey() (*[32]byte, string, error) {
+	var key [32]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return nil, "", err
+	}
+
+	var response struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	request := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(key[:])}
+	if err := p.call("encrypt", request, &response); err != nil {
+		return nil, "", err
+	}
+
+	if response.Ciphertext == "" {
+		return nil, "", errors.New("Key management service returned an empty ciphertext")
+	}
+
+	return &key, response.Ciphertext, nil
+}
+
+// UnwrapKey unwraps a data key via the key management service
+func (p kmsProvider) UnwrapKey(wrapped string) (*[32]byte, error) {
+	var response struct {
+		Plaintext string `json:"plaintext"`
+	}
+	if err := p.call("decrypt", map[string]string{"ciphertext": wrapped}, &response); err != nil {
+		return nil, err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(response.Plaintext)
+	if err != nil || len(decoded) != 32 {
+		return nil, errors.New("Key management service returned an invalid data key")
+	}
+
+	var key [32]byte
+	copy(key[:], decoded)
+	return &key, nil
+}
+
+func (p kmsProvider) call(action string, request interface{}, response interface{}) error {
+	b, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(p.url, "/")+"/"+action, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Unable to reach key management service: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("Key management service %s request failed with status %d", action, res.StatusCode)
+	}
+
+	return json.NewDecoder(res.Body).Decode(response)
+}
+
+// encryptionEnabled returns whether config values should be encrypted at rest
+func encryptionEnabled() bool {
+	return os.Getenv("DOKKU_CONFIG_ENCRYPTION") != ""
+}
+
+// getKeyProvider returns the key provider for a given name
+func getKeyProvider(name string) (KeyProvider, error) {
+	switch name {
+	case "keyfile":
+		filename := os.Getenv("DOKKU_CONFIG_KEYFILE")
+		if filename == "" {
+			filename = "/etc/dokku/config.key"
+		}
+		return keyfileProvider{filename: filename}, nil
+	case "kms":
+		url := os.Getenv("DOKKU_CONFIG_KMS_URL")
+		if url == "" {
+			return nil, errors.New("DOKKU_CONFIG_KMS_URL must be set to use the kms config encryption provider")
+		}
+		return kmsProvider{url: url, token: os.Getenv("DOKKU_CONFIG_KMS_TOKEN")}, nil
+	}
+
+	return nil, fmt.Errorf("Invalid config encryption provider: %s", name)
+}
+
+// isSealedValue returns whether a stored config value is encrypted
+func isSealedValue(value string) bool {
+	return strings.HasPrefix(value, encryptedValuePrefix)
+}
+
+// sealEnvMap encrypts every value in an environment with the configured key provider.
+// If encryption is not enabled, the environment is returned as is.
+func sealEnvMap(env map[string]string) (map[string]string, error) {
+	if !encryptionEnabled() || len(env) == 0 {
+		return env, nil
+	}
+
+	provider, err := getKeyProvider(os.Getenv("DOKKU_CONFIG_ENCRYPTION"))
+	if err != nil {
+		return nil, err
+	}
+
+	key, wrapped, err := provider.DataKey()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := make(map[string]string, len(env))
+	for k, v := range env {
+		var nonce [24]byte
+		if _, err := rand.Read(nonce[:]); err != nil {
+			return nil, err
+		}
+
+		box := secretbox.Seal(nonce[:], []byte(v), &nonce, key)
+		sealed[k] = strings.Join([]string{
+			encryptedValuePrefix + provider.Name(),
+			base64.RawURLEncoding.EncodeToString([]byte(wrapped)),
+			base64.RawURLEncoding.EncodeToString(box),
+		}, ":")
+	}
+
+	return sealed, nil
+}
+
+// openEnvMap decrypts every encrypted value in an environment, returning the
+// decrypted environment and the keys whose values were encrypted
+func openEnvMap(env map[string]string) (map[string]string, map[string]bool, error) {
+	opened := make(map[string]string, len(env))
+	sealedKeys := map[string]bool{}
+	keys := map[string]*[32]byte{}
+	for k, v := range env {
+		if !isSealedValue(v) {
+			opened[k] = v
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(v, encryptedValuePrefix), ":", 3)
+		if len(parts) != 3 {
+			return nil, nil, fmt.Errorf("Invalid encrypted value for config var %s", k)
+		}
+
+		cacheKey := parts[0] + ":" + parts[1]
+		key, ok := keys[cacheKey]
+		if !ok {
+			provider, err := getKeyProvider(parts[0])
+			if err != nil {
+				return nil, nil, err
+			}
+
+			wrapped, err := base64.RawURLEncoding.DecodeString(parts[1])
+			if err != nil {
+				return nil, nil, fmt.Errorf("Invalid encrypted value for config var %s", k)
+			}
+
+			key, err = provider.UnwrapKey(string(wrapped))
+			if err != nil {
+				return nil, nil, err
+			}
+			keys[cacheKey] = key
+		}
+
+		box, err := base64.RawURLEncoding.DecodeString(parts[2])
+		if err != nil || len(box) < 24 {
+			return nil, nil, fmt.Errorf("Invalid encrypted value for config var %s", k)
+		}
+
+		var nonce [24]byte
+		copy(nonce[:], box[:24])
+		value, ok := secretbox.Open(nil, box[24:], &nonce, key)
+		if !ok {
+			return nil, nil, fmt.Errorf("Unable to decrypt config var %s, check the config encryption key", k)
+		}
+
+		opened[k] = string(value)
+		sealedKeys[k] = true
+	}
+
+	return opened, sealedKeys, nil
+}
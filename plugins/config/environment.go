@@ -2,6 +2,7 @@ package config
 
 import (
 	"archive/tar"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/BurntSushi/toml"
 	"github.com/dokku/dokku/plugins/common"
 	"github.com/joho/godotenv"
 	"github.com/ryanuber/columnize"
@@ -38,6 +40,14 @@ const (
 	ExportFormatJSONList
 	//ExportFormatPackArgKeys format: --env KEY args for pack
 	ExportFormatPackArgKeys
+	//ExportFormatYAML format: yaml key/value output
+	ExportFormatYAML
+	//ExportFormatTOML format: toml key/value output
+	ExportFormatTOML
+	//ExportFormatKubernetesSecret format: kubernetes Secret manifest
+	ExportFormatKubernetesSecret
+	//ExportFormatKubernetesConfigMap format: kubernetes ConfigMap manifest
+	ExportFormatKubernetesConfigMap
 )
 
 // Env is a representation for global or app environment
@@ -45,6 +55,7 @@ type Env struct {
 	name     string
 	filename string
 	env      map[string]string
+	sealed   map[string]bool
 }
 
 // newEnvFromString creates an env from the given ENVFILE contents representation
@@ -67,8 +78,22 @@ func LoadAppEnv(appName string) (env *Env, err error) {
 	return loadFromFile(appName, appfile)
 }
 
-// LoadMergedAppEnv loads an app environment merged with the global environment
+// LoadMergedAppEnv loads an app environment merged with the global environment,
+// with all config references resolved
 func LoadMergedAppEnv(appName string) (env *Env, err error) {
+	env, err = loadMergedAppEnv(appName)
+	if err != nil {
+		return
+	}
+
+	resolver := newReferenceResolver()
+	_, err = resolver.resolveEnv(appName, env)
+	resolver.logUnresolved()
+	return
+}
+
+// loadMergedAppEnv loads an app environment merged with the global environment without resolving references
+func loadMergedAppEnv(appName string) (env *Env, err error) {
 	env, err = LoadAppEnv(appName)
 	if err != nil {
 		return
@@ -123,6 +148,11 @@ func (e *Env) GetBoolDefault(key string, defaultValue bool) bool {
 	return v != "0"
 }
 
+// IsSealed returns whether the value for a key was encrypted at rest when it was loaded
+func (e *Env) IsSealed(key string) bool {
+	return e.sealed[key]
+}
+
 // Set an environment variable
 func (e *Env) Set(key string, value string) {
 	e.env[key] = value
@@ -161,6 +191,14 @@ func (e *Env) String() string {
 func (e *Env) Merge(other *Env) {
 	for _, k := range other.Keys() {
 		e.Set(k, other.GetDefault(k, ""))
+		if other.IsSealed(k) {
+			if e.sealed == nil {
+				e.sealed = map[string]bool{}
+			}
+			e.sealed[k] = true
+		} else {
+			delete(e.sealed, k)
+		}
 	}
 }
 
@@ -169,7 +207,12 @@ func (e *Env) Write() error {
 	if e.filename == "" {
 		return errors.New("this Env was created unbound to a file")
 	}
-	return godotenv.Write(e.Map(), e.filename)
+
+	envMap, err := sealEnvMap(e.Map())
+	if err != nil {
+		return fmt.Errorf("Unable to encrypt config: %w", err)
+	}
+	return godotenv.Write(envMap, e.filename)
 }
 
 // Export the Env in the given format
@@ -193,12 +236,32 @@ func (e *Env) Export(format ExportFormat) string {
 		return e.JSONListString()
 	case ExportFormatPackArgKeys:
 		return e.PackArgKeysAsString()
+	case ExportFormatYAML:
+		return e.YAMLString()
+	case ExportFormatTOML:
+		return e.TOMLString()
+	case ExportFormatKubernetesSecret:
+		return kubernetesManifestString(e.name, "Secret", e.Map())
+	case ExportFormatKubernetesConfigMap:
+		return kubernetesManifestString(e.name, "ConfigMap", e.Map())
 	default:
 		common.LogFail(fmt.Sprintf("Unknown export format: %v", format))
 		return ""
 	}
 }
 
+// MaskedMap returns the Env as a map, with values that were encrypted at rest replaced by a placeholder
+func (e *Env) MaskedMap() map[string]string {
+	masked := make(map[string]string, len(e.env))
+	for k, v := range e.env {
+		if e.IsSealed(k) {
+			v = "********"
+		}
+		masked[k] = v
+	}
+	return masked
+}
+
 // EnvfileString returns the contents of this Env in dotenv format
 func (e *Env) EnvfileString() string {
 	rep, _ := godotenv.Marshal(e.Map())
@@ -254,6 +317,25 @@ func (e *Env) JSONListString() string {
 	return string(data)
 }
 
+// YAMLString returns the contents of this Env as a yaml mapping
+func (e *Env) YAMLString() string {
+	if e.Len() == 0 {
+		return "{}"
+	}
+
+	return marshalYAML(e.Map())
+}
+
+// TOMLString returns the contents of this Env as a toml table
+func (e *Env) TOMLString() string {
+	var b bytes.Buffer
+	if err := toml.NewEncoder(&b).Encode(e.Map()); err != nil {
+		return ""
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
 // PackArgKeysAsString gets the contents of this Env in the form -env KEY --env...
 func (e *Env) PackArgKeysAsString() string {
 	keys := e.Keys()
@@ -358,31 +440,35 @@ func loadFromFile(name string, filename string) (env *Env, err error) {
 		}
 	}
 
+	envMap, sealed, err := openEnvMap(envMap)
+	if err != nil {
+		return nil, err
+	}
+
 	env = &Env{
 		name:     name,
 		filename: filename,
 		env:      envMap,
+		sealed:   sealed,
 	}
 	return
 }
 
-func loadFromFileJSON(name string, filename string) (env *Env, err error) {
-	envMap := make(map[string]string)
-	if filename == "-" {
-		err = json.NewDecoder(os.Stdin).Decode(&envMap)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		file, err := os.Open(filename)
-		if err != nil {
-			return nil, err
-		}
-		defer file.Close()
-		err = json.NewDecoder(file).Decode(&envMap)
-		if err != nil {
-			return nil, err
-		}
+// loadFromImportFile loads an environment from a file in a given import format, or from stdin if the filename is -
+func loadFromImportFile(name string, filename string, format string) (*Env, error) {
+	contents, err := readImportSource(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	envMap, err := parseEnvFormat(format, contents)
+	if err != nil {
+		return nil, err
+	}
+
+	envMap, _, err = openEnvMap(envMap)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Env{
@@ -391,6 +477,7 @@ func loadFromFileJSON(name string, filename string) (env *Env, err error) {
 		env:      envMap,
 	}, nil
 }
+
 func getAppFile(appName string) (string, error) {
 	return filepath.Join(common.MustGetEnv("DOKKU_ROOT"), appName, "ENV"), nil
 }
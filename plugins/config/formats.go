@@ -0,0 +1,220 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// kubernetesMetadata is the metadata for a kubernetes manifest
+type kubernetesMetadata struct {
+	Name   string            `yaml:"name"`
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+// kubernetesManifest is a kubernetes Secret or ConfigMap manifest
+type kubernetesManifest struct {
+	APIVersion string             `yaml:"apiVersion"`
+	Kind       string             `yaml:"kind"`
+	Metadata   kubernetesMetadata `yaml:"metadata"`
+	Type       string             `yaml:"type,omitempty"`
+	Data       map[string]string  `yaml:"data,omitempty"`
+	StringData map[string]string  `yaml:"stringData,omitempty"`
+}
+
+// importFormats are the formats supported by config:import
+var importFormats = []string{"envfile", "json", "k8s-configmap", "k8s-secret", "toml", "yaml"}
+
+// kubernetesResourceName returns the name of the kubernetes resource for an environment
+func kubernetesResourceName(name string) string {
+	if name == "<global>" || name == "--global" || name == "" {
+		return "env-global"
+	}
+	return "env-" + name
+}
+
+// kubernetesManifestString renders an environment as a kubernetes Secret or ConfigMap manifest
+func kubernetesManifestString(name string, kind string, env map[string]string) string {
+	manifest := kubernetesManifest{
+		APIVersion: "v1",
+		Kind:       kind,
+		Metadata: kubernetesMetadata{
+			Name:   kubernetesResourceName(name),
+			Labels: map[string]string{"dokku.com/managed": "true"},
+		},
+		Data: map[string]string{},
+	}
+
+	for key, value := range env {
+		if kind == "Secret" {
+			value = base64.StdEncoding.EncodeToString([]byte(value))
+		}
+		manifest.Data[key] = value
+	}
+	if kind == "Secret" {
+		manifest.Type = "Opaque"
+	}
+
+	return marshalYAML(manifest)
+}
+
+func marshalYAML(data interface{}) string {
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(data); err != nil {
+		return ""
+	}
+	encoder.Close()
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// readImportSource reads the contents of a file, or stdin if the filename is -
+func readImportSource(filename string) ([]byte, error) {
+	if filename == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(filename)
+}
+
+// parseEnvFormat parses the contents of an environment in a given import format
+func parseEnvFormat(format string, contents []byte) (map[string]string, error) {
+	switch format {
+	case "envfile":
+		return godotenv.Unmarshal(string(contents))
+	case "json":
+		envMap := map[string]string{}
+		err := json.Unmarshal(contents, &envMap)
+		return envMap, err
+	case "yaml":
+		data := map[string]interface{}{}
+		if err := yaml.Unmarshal(contents, &data); err != nil {
+			return nil, err
+		}
+		return flattenEnvMap(data)
+	case "toml":
+		data := map[string]interface{}{}
+		if err := toml.Unmarshal(contents, &data); err != nil {
+			return nil, err
+		}
+		return flattenEnvMap(data)
+	case "k8s-configmap", "k8s-secret":
+		return parseKubernetesManifest(format, contents)
+	}
+
+	return nil, fmt.Errorf("Unknown format: %s, supported formats: %s", format, strings.Join(importFormats, ", "))
+}
+
+// parseKubernetesManifest reads the config vars from a kubernetes Secret or ConfigMap manifest
+func parseKubernetesManifest(format string, contents []byte) (map[string]string, error) {
+	var manifest kubernetesManifest
+	if err := yaml.Unmarshal(contents, &manifest); err != nil {
+		return nil, err
+	}
+
+	expectedKind := "ConfigMap"
+	if format == "k8s-secret" {
+		expectedKind = "Secret"
+	}
+	if manifest.Kind != expectedKind {
+		return nil, fmt.Errorf("Expected a %s manifest, got kind '%s'", expectedKind, manifest.Kind)
+	}
+
+	envMap := map[string]string{}
+	for key, value := range manifest.Data {
+		if manifest.Kind == "Secret" {
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid base64 value for key '%s': %w", key, err)
+			}
+			value = string(decoded)
+		}
+		envMap[key] = value
+	}
+	for key, value := range manifest.StringData {
+		envMap[key] = value
+	}
+
+	return envMap, nil
+}
+
+// flattenEnvMap converts a possibly nested map into config vars, joining the
+// keys of nested maps with an underscore
+func flattenEnvMap(data map[string]interface{}) (map[string]string, error) {
+	envMap := map[string]string{}
+	if err := flattenInto(envMap, "", data); err != nil {
+		return nil, err
+	}
+	return envMap, nil
+}
+
+func flattenInto(envMap map[string]string, prefix string, data map[string]interface{}) error {
+	keys := []string{}
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fullKey := key
+		if prefix != "" {
+			fullKey = prefix + "_" + key
+		}
+
+		switch value := data[key].(type) {
+		case map[string]interface{}:
+			if err := flattenInto(envMap, fullKey, value); err != nil {
+				return err
+			}
+		case []interface{}:
+			return fmt.Errorf("Unsupported list value for key '%s'", fullKey)
+		default:
+			stringValue, err := scalarToString(value)
+			if err != nil {
+				return fmt.Errorf("Unsupported value for key '%s': %w", fullKey, err)
+			}
+
+			if _, ok := envMap[fullKey]; ok {
+				return fmt.Errorf("Duplicate key '%s' after flattening", fullKey)
+			}
+			envMap[fullKey] = stringValue
+		}
+	}
+
+	return nil
+}
+
+func scalarToString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	}
+
+	return "", errors.New("value must be a string, number or boolean")
+}
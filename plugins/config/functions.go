@@ -2,16 +2,19 @@ package config
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/dokku/dokku/plugins/common"
+	"github.com/ryanuber/columnize"
 )
 
-func export(appName string, merged bool, format string) error {
-	env := getEnvironment(appName, merged)
+func export(appName string, processType string, merged bool, format string) error {
+	env := getEnvironment(appName, processType, merged)
 	exportType := ExportFormatExports
 	suffix := "\n"
 
@@ -23,8 +26,12 @@ func export(appName string, merged bool, format string) error {
 		"json":             ExportFormatJSON,
 		"json-list":        ExportFormatJSONList,
 		"pack-keys":        ExportFormatPackArgKeys,
+		"k8s-configmap":    ExportFormatKubernetesConfigMap,
+		"k8s-secret":       ExportFormatKubernetesSecret,
 		"pretty":           ExportFormatPretty,
 		"shell":            ExportFormatShell,
+		"toml":             ExportFormatTOML,
+		"yaml":             ExportFormatYAML,
 	}
 
 	exportType, ok := exportTypes[format]
@@ -43,7 +50,7 @@ func export(appName string, merged bool, format string) error {
 
 // SubBundle implements the logic for config:bundle without app name validation
 func SubBundle(appName string, merged bool) error {
-	env := getEnvironment(appName, merged)
+	env := getEnvironment(appName, "", merged)
 	return env.ExportBundle(os.Stdout)
 }
 
@@ -53,12 +60,12 @@ func SubClear(appName string, noRestart bool) error {
 }
 
 // SubExport implements the logic for config:export without app name validation
-func SubExport(appName string, merged bool, format string) error {
-	return export(appName, merged, format)
+func SubExport(appName string, merged bool, format string, processType string) error {
+	return export(appName, processType, merged, format)
 }
 
 // SubGet implements the logic for config:get without app name validation
-func SubGet(appName string, keys []string, quoted bool) error {
+func SubGet(appName string, keys []string, quoted bool, processType string) error {
 	if len(keys) == 0 {
 		return errors.New("Expected: key")
 	}
@@ -67,7 +74,13 @@ func SubGet(appName string, keys []string, quoted bool) error {
 		return fmt.Errorf("Unexpected argument(s): %v", keys[1:])
 	}
 
-	value, ok := Get(appName, keys[0])
+	var value string
+	var ok bool
+	if processType != "" {
+		value, ok = getEnvironment(appName, processType, false).Get(keys[0])
+	} else {
+		value, ok = Get(appName, keys[0])
+	}
 	if !ok {
 		os.Exit(1)
 		return nil
@@ -84,6 +97,10 @@ func SubGet(appName string, keys []string, quoted bool) error {
 
 // SubImport imports environment variables from a file
 func SubImport(appName string, replace bool, noRestart bool, format string, filename string) error {
+	if filename == "" {
+		return errors.New("A filename must be given, or - to read from stdin")
+	}
+
 	if filename == "-" {
 		common.LogInfo1Quiet("Reading config vars from stdin")
 	}
@@ -92,45 +109,302 @@ func SubImport(appName string, replace bool, noRestart bool, format string, file
 		format = "envfile"
 	}
 
-	var env *Env
-	var err error
-	switch format {
-	case "envfile":
-		env, err = loadFromFile(appName, filename)
+	env, err := loadFromImportFile(appName, filename, format)
+	if err != nil {
+		return err
+	}
+
+	if len(env.Map()) == 0 {
+		return errors.New("No config vars to set")
+	}
+
+	if err := validateEntries(appName, env.Map()); err != nil {
+		return err
+	}
+
+	for k, v := range env.Map() {
+		common.LogInfo1Quiet(fmt.Sprintf("Setting config var %s=%s", k, v))
+	}
+
+	return setMany(appName, env.Map(), replace, !noRestart, "import")
+}
+
+// SubDiff implements the logic for config:diff without app name validation
+func SubDiff(appName string, revisionA string, revisionB string) error {
+	if revisionA == "" {
+		return errors.New("At least one revision must be given")
+	}
+
+	a, err := parseRevision(revisionA)
+	if err != nil {
+		return err
+	}
+
+	before, err := GetRevision(appName, a)
+	if err != nil {
+		return err
+	}
+
+	var after map[string]string
+	if revisionB == "" || revisionB == "current" {
+		env, err := loadAppOrGlobalEnv(appName)
+		if before.ProcessType != "" {
+			env, err = LoadProcessEnv(appName, before.ProcessType)
+		}
+		if err != nil {
+			return err
+		}
+		after = fingerprintEnvMap(env.Map())
+	} else {
+		b, err := parseRevision(revisionB)
 		if err != nil {
 			return err
 		}
-	case "json":
-		env, err = loadFromFileJSON(appName, filename)
+
+		revision, err := GetRevision(appName, b)
 		if err != nil {
 			return err
 		}
-	default:
-		return fmt.Errorf("Unknown format: %s", format)
+		after = revision.Fingerprints
 	}
 
-	if len(env.Map()) == 0 {
-		return errors.New("No config vars to set")
+	for _, change := range diffFingerprints(before.Fingerprints, after) {
+		switch change.Action {
+		case "added":
+			fmt.Printf("+ %s %s\n", change.Key, change.NewHash)
+		case "removed":
+			fmt.Printf("- %s %s\n", change.Key, change.OldHash)
+		case "changed":
+			fmt.Printf("~ %s %s -> %s\n", change.Key, change.OldHash, change.NewHash)
+		}
 	}
 
-	for k, v := range env.Map() {
-		common.LogInfo1Quiet(fmt.Sprintf("Setting config var %s=%s", k, v))
+	return nil
+}
+
+// SubHistory implements the logic for config:history without app name validation
+func SubHistory(appName string, format string) error {
+	if format != "stdout" && format != "json" {
+		return fmt.Errorf("Invalid format specified, supported formats: json, stdout")
+	}
+
+	revisions, err := GetRevisions(appName)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		for i := range revisions {
+			revisions[i].Fingerprints = nil
+		}
+
+		b, err := json.Marshal(revisions)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(b))
+		return nil
+	}
+
+	contextName := "global"
+	if appName != "" && appName != "--global" {
+		contextName = appName
+	}
+	common.LogInfo2Quiet(contextName + " config history")
+
+	lines := []string{"Revision | Date | User | Operation | Keys"}
+	for i := len(revisions) - 1; i >= 0; i-- {
+		revision := revisions[i]
+		date := time.Unix(revision.Timestamp, 0).UTC().Format(time.RFC3339)
+		user := fmt.Sprintf("%s (%s)", revision.User, revision.Name)
+		operation := revision.Operation
+		if revision.ProcessType != "" {
+			operation = fmt.Sprintf("%s (%s)", operation, revision.ProcessType)
+		}
+		lines = append(lines, fmt.Sprintf("%d | %s | %s | %s | %s", revision.Revision, date, user, operation, strings.Join(revision.Keys(), ",")))
 	}
 
-	return SetMany(appName, env.Map(), replace, !noRestart)
+	fmt.Println(columnize.SimpleFormat(lines))
+	return nil
 }
 
 // SubKeys implements the logic for config:keys without app name validation
 func SubKeys(appName string, merged bool) error {
-	env := getEnvironment(appName, merged)
+	env := getEnvironment(appName, "", merged)
 	for _, k := range env.Keys() {
 		fmt.Println(k)
 	}
 	return nil
 }
 
+// SubRefs implements the logic for config:refs without app name validation
+func SubRefs(appName string, reveal bool) error {
+	env, err := loadMergedAppEnv(appName)
+	if err != nil {
+		return err
+	}
+
+	raw := copyEnvMap(env.Map())
+	resolver := newReferenceResolver()
+	if _, err := resolver.resolveEnv(appName, env); err != nil {
+		return err
+	}
+	resolver.logUnresolved()
+
+	common.LogInfo2Quiet(appName + " config references")
+	lines := []string{"Key | References | Resolved value"}
+	for _, key := range env.Keys() {
+		node := Reference{AppName: appName, Key: key}.node()
+		if len(resolver.dependencies[node]) == 0 {
+			continue
+		}
+
+		value, _ := env.Get(key)
+		if env.IsSealed(key) && !reveal {
+			value = "********"
+		}
+
+		references := []string{}
+		for _, reference := range parseReferences(raw[key]) {
+			references = append(references, reference.String())
+		}
+		lines = append(lines, fmt.Sprintf("%s | %s | %s", key, strings.Join(references, ","), value))
+	}
+	fmt.Println(columnize.SimpleFormat(lines))
+
+	appEnv, err := LoadAppEnv(appName)
+	if err != nil {
+		return err
+	}
+
+	referenceDependents, err := getReferenceDependents()
+	if err != nil {
+		return err
+	}
+
+	common.LogInfo2Quiet(appName + " config dependents")
+	lines = []string{"Key | Dependent apps"}
+	for _, key := range appEnv.Keys() {
+		dependents := referenceDependents[Reference{AppName: appName, Key: key}.node()]
+		if len(dependents) == 0 {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s | %s", key, strings.Join(dependents, ",")))
+	}
+	fmt.Println(columnize.SimpleFormat(lines))
+	return nil
+}
+
+// SubRollback implements the logic for config:rollback without app name validation
+func SubRollback(appName string, revision string, noRestart bool) error {
+	if revision == "" {
+		return errors.New("A revision must be given")
+	}
+
+	r, err := parseRevision(revision)
+	if err != nil {
+		return err
+	}
+
+	return Rollback(appName, r, !noRestart)
+}
+
+// SubSchemaSet implements the logic for config:schema:set without app name validation
+func SubSchemaSet(appName string, key string, rule SchemaRule) error {
+	if key == "" {
+		return errors.New("A key must be given")
+	}
+
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	schema, err := getAppSchema(appName)
+	if err != nil {
+		return err
+	}
+
+	schema[key] = rule
+	if err := writeAppSchema(appName, schema); err != nil {
+		return err
+	}
+
+	common.LogInfo1(fmt.Sprintf("Setting config schema for %s", key))
+	return nil
+}
+
+// SubSchemaShow implements the logic for config:schema:show without app name validation
+func SubSchemaShow(appName string, format string) error {
+	if format != "stdout" && format != "json" {
+		return fmt.Errorf("Invalid format specified, supported formats: json, stdout")
+	}
+
+	schema, err := GetSchema(appName)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		b, err := json.Marshal(schema)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(b))
+		return nil
+	}
+
+	common.LogInfo2Quiet(appName + " config schema")
+	lines := []string{"Key | Type | Required | Default | Constraint | Source"}
+	for _, key := range schema.Keys() {
+		rule := schema[key]
+		ruleType := rule.Type
+		if ruleType == "" {
+			ruleType = "string"
+		}
+
+		constraint := rule.Pattern
+		if len(rule.Values) > 0 {
+			constraint = strings.Join(rule.Values, ",")
+		}
+		lines = append(lines, fmt.Sprintf("%s | %s | %t | %s | %s | %s", key, ruleType, rule.Required, rule.Default, constraint, rule.Source))
+	}
+
+	fmt.Println(columnize.SimpleFormat(lines))
+	return nil
+}
+
+// SubSchemaUnset implements the logic for config:schema:unset without app name validation
+func SubSchemaUnset(appName string, key string) error {
+	if key == "" {
+		return errors.New("A key must be given")
+	}
+
+	schema, err := getAppSchema(appName)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := schema[key]; !ok {
+		return fmt.Errorf("No config schema set for %s", key)
+	}
+
+	delete(schema, key)
+	if err := writeAppSchema(appName, schema); err != nil {
+		return err
+	}
+
+	common.LogInfo1(fmt.Sprintf("Removing config schema for %s", key))
+	return nil
+}
+
 // SubSet implements the logic for config:set without app name validation
-func SubSet(appName string, pairs []string, noRestart bool, encoded bool) error {
+func SubSet(appName string, pairs []string, noRestart bool, encoded bool, restartDependents bool, processType string) error {
 	if len(pairs) == 0 {
 		return errors.New("At least one env pair must be given")
 	}
@@ -153,12 +427,32 @@ func SubSet(appName string, pairs []string, noRestart bool, encoded bool) error
 		updated[key] = value
 	}
 
-	return SetMany(appName, updated, false, !noRestart)
+	if err := validateEntries(appName, updated); err != nil {
+		return err
+	}
+
+	if processType != "" {
+		return SetManyProcess(appName, processType, updated, !noRestart)
+	}
+
+	if err := SetMany(appName, updated, false, !noRestart); err != nil {
+		return err
+	}
+
+	if !restartDependents {
+		return nil
+	}
+
+	keys := make([]string, 0, len(updated))
+	for key := range updated {
+		keys = append(keys, key)
+	}
+	return restartDependentApps(appName, keys)
 }
 
 // SubShow implements the logic for config:show without app name validation
-func SubShow(appName string, merged bool, shell bool, export bool) error {
-	env := getEnvironment(appName, merged)
+func SubShow(appName string, merged bool, shell bool, export bool, reveal bool, processType string) error {
+	env := getEnvironment(appName, processType, merged)
 	if shell && export {
 		return errors.New("Only one of --shell and --export can be given")
 	}
@@ -173,18 +467,37 @@ func SubShow(appName string, merged bool, shell bool, export bool) error {
 		if appName != "" {
 			contextName = appName
 		}
+		if processType != "" {
+			contextName = fmt.Sprintf("%s %s process", contextName, processType)
+		}
 		common.LogInfo2Quiet(contextName + " env vars")
-		fmt.Println(env.Export(ExportFormatPretty))
+		if reveal {
+			fmt.Println(env.Export(ExportFormatPretty))
+		} else {
+			fmt.Println(prettyPrintEnvEntries("", env.MaskedMap()))
+		}
 	}
 
 	return nil
 }
 
 // SubUnset implements the logic for config:unset without app name validation
-func SubUnset(appName string, keys []string, noRestart bool) error {
+func SubUnset(appName string, keys []string, noRestart bool, restartDependents bool, processType string) error {
 	if len(keys) == 0 {
 		return fmt.Errorf("At least one key must be given")
 	}
 
-	return UnsetMany(appName, keys, !noRestart)
+	if processType != "" {
+		return UnsetManyProcess(appName, processType, keys, !noRestart)
+	}
+
+	if err := UnsetMany(appName, keys, !noRestart); err != nil {
+		return err
+	}
+
+	if !restartDependents {
+		return nil
+	}
+
+	return restartDependentApps(appName, keys)
 }
@@ -0,0 +1,352 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dokku/dokku/plugins/common"
+	"github.com/joho/godotenv"
+)
+
+// Revision is a single recorded change to an app or global environment
+type Revision struct {
+	// Revision is the revision number
+	Revision int `json:"revision"`
+
+	// Timestamp is the unix timestamp at which the revision was recorded
+	Timestamp int64 `json:"timestamp"`
+
+	// User is the ssh user that made the change
+	User string `json:"user"`
+
+	// Name is the ssh key name of the user that made the change
+	Name string `json:"name"`
+
+	// Operation is the config operation that created the revision
+	Operation string `json:"operation"`
+
+	// ProcessType is the process type whose environment was changed, or empty for the app or global environment
+	ProcessType string `json:"process_type,omitempty"`
+
+	// Changes is a list of changed keys, with hashed values
+	Changes []RevisionChange `json:"changes"`
+
+	// Fingerprints maps each key in the environment as of this revision to the hash of its value
+	Fingerprints map[string]string `json:"fingerprints,omitempty"`
+
+	// Restorable is whether the environment as of this revision was stored alongside it
+	Restorable bool `json:"restorable"`
+
+	// Env is the environment as of this revision, which is stored in a separate env file
+	Env map[string]string `json:"-"`
+}
+
+// RevisionChange is a single changed key within a revision
+type RevisionChange struct {
+	// Key is the changed key
+	Key string `json:"key"`
+
+	// Action is one of added, changed or removed
+	Action string `json:"action"`
+
+	// OldHash is the hash of the value before the change
+	OldHash string `json:"old_hash,omitempty"`
+
+	// NewHash is the hash of the value after the change
+	NewHash string `json:"new_hash,omitempty"`
+}
+
+// Keys returns the changed keys in a revision
+func (r Revision) Keys() []string {
+	keys := []string{}
+	for _, change := range r.Changes {
+		keys = append(keys, change.Key)
+	}
+	return keys
+}
+
+// hashValue returns a short, non-reversible representation of a config value
+func hashValue(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:])[0:12]
+}
+
+// fingerprintEnvMap returns the hash of every value in an environment
+func fingerprintEnvMap(env map[string]string) map[string]string {
+	fingerprints := make(map[string]string, len(env))
+	for key, value := range env {
+		fingerprints[key] = hashValue(value)
+	}
+	return fingerprints
+}
+
+// diffEnvMaps returns the changes required to go from one environment to another
+func diffEnvMaps(before map[string]string, after map[string]string) []RevisionChange {
+	return diffFingerprints(fingerprintEnvMap(before), fingerprintEnvMap(after))
+}
+
+// diffFingerprints returns the changes required to go from one set of fingerprints to another
+func diffFingerprints(before map[string]string, after map[string]string) []RevisionChange {
+	keys := map[string]bool{}
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+
+	sortedKeys := []string{}
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	changes := []RevisionChange{}
+	for _, key := range sortedKeys {
+		oldHash, hadKey := before[key]
+		newHash, hasKey := after[key]
+		switch {
+		case !hadKey:
+			changes = append(changes, RevisionChange{Key: key, Action: "added", NewHash: newHash})
+		case !hasKey:
+			changes = append(changes, RevisionChange{Key: key, Action: "removed", OldHash: oldHash})
+		case oldHash != newHash:
+			changes = append(changes, RevisionChange{Key: key, Action: "changed", OldHash: oldHash, NewHash: newHash})
+		}
+	}
+
+	return changes
+}
+
+func copyEnvMap(env map[string]string) map[string]string {
+	copied := make(map[string]string, len(env))
+	for key, value := range env {
+		copied[key] = value
+	}
+	return copied
+}
+
+func getHistoryDirectory(appName string) string {
+	if appName == "" || appName == "--global" {
+		return filepath.Join(common.MustGetEnv("DOKKU_ROOT"), "ENV.history")
+	}
+	return filepath.Join(common.MustGetEnv("DOKKU_ROOT"), appName, "ENV.history")
+}
+
+// recordRevision stores a new revision for an environment if anything changed.
+// If processType is set, the revision is for the environment of that process type.
+func recordRevision(appName string, processType string, operation string, before map[string]string, after map[string]string) error {
+	changes := diffEnvMaps(before, after)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	directory := getHistoryDirectory(appName)
+	if err := os.MkdirAll(directory, 0700); err != nil {
+		return fmt.Errorf("Unable to create config history directory: %w", err)
+	}
+	if err := common.SetPermissions(common.SetPermissionInput{
+		Filename: directory,
+		Mode:     os.FileMode(0700),
+	}); err != nil {
+		return err
+	}
+
+	user := os.Getenv("SSH_USER")
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+	name := os.Getenv("SSH_NAME")
+	if name == "" {
+		name = os.Getenv("NAME")
+	}
+	if name == "" {
+		name = "default"
+	}
+
+	revisions, err := listRevisionNumbers(appName)
+	if err != nil {
+		return err
+	}
+
+	revision := Revision{
+		Revision:     1,
+		Timestamp:    time.Now().Unix(),
+		User:         user,
+		Name:         name,
+		Operation:    operation,
+		ProcessType:  processType,
+		Changes:      changes,
+		Fingerprints: fingerprintEnvMap(after),
+		Restorable:   true,
+	}
+
+	// the environment is stored like the ENV file, encrypted when config encryption is enabled
+	sealedEnv, err := sealEnvMap(after)
+	if err != nil {
+		return fmt.Errorf("Unable to encrypt config history: %w", err)
+	}
+	contents, err := godotenv.Marshal(sealedEnv)
+	if err != nil {
+		return err
+	}
+
+	if len(revisions) > 0 {
+		revision.Revision = revisions[len(revisions)-1] + 1
+	}
+
+	for {
+		b, err := json.Marshal(revision)
+		if err != nil {
+			return err
+		}
+
+		filename := filepath.Join(directory, fmt.Sprintf("%d.json", revision.Revision))
+		file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, os.ErrExist) {
+			revision.Revision++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = file.Write(b)
+		file.Close()
+		if err != nil {
+			return err
+		}
+
+		if err := common.SetPermissions(common.SetPermissionInput{
+			Filename: filename,
+			Mode:     os.FileMode(0600),
+		}); err != nil {
+			return err
+		}
+
+		envFilename := getRevisionEnvFilename(appName, revision.Revision)
+		if err := os.WriteFile(envFilename, []byte(contents+"\n"), 0600); err != nil {
+			return err
+		}
+
+		return common.SetPermissions(common.SetPermissionInput{
+			Filename: envFilename,
+			Mode:     os.FileMode(0600),
+		})
+	}
+}
+
+// getRevisionEnvFilename returns the file the environment as of a revision is stored in
+func getRevisionEnvFilename(appName string, revision int) string {
+	return filepath.Join(getHistoryDirectory(appName), fmt.Sprintf("%d.env", revision))
+}
+
+// listRevisionNumbers returns the recorded revision numbers for an environment in ascending order
+func listRevisionNumbers(appName string) ([]int, error) {
+	revisions := []int{}
+	files, err := os.ReadDir(getHistoryDirectory(appName))
+	if errors.Is(err, os.ErrNotExist) {
+		return revisions, nil
+	}
+	if err != nil {
+		return revisions, err
+	}
+
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		revision, err := strconv.Atoi(strings.TrimSuffix(file.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		revisions = append(revisions, revision)
+	}
+
+	sort.Ints(revisions)
+	return revisions, nil
+}
+
+// GetRevision loads a single revision for an app or global environment
+func GetRevision(appName string, revision int) (Revision, error) {
+	var r Revision
+	b, err := os.ReadFile(filepath.Join(getHistoryDirectory(appName), fmt.Sprintf("%d.json", revision)))
+	if errors.Is(err, os.ErrNotExist) {
+		return r, fmt.Errorf("Revision %d does not exist", revision)
+	}
+	if err != nil {
+		return r, err
+	}
+
+	if err := json.Unmarshal(b, &r); err != nil {
+		return r, fmt.Errorf("Unable to parse revision %d: %w", revision, err)
+	}
+
+	r.Env = map[string]string{}
+	r.Restorable = false
+	envFilename := getRevisionEnvFilename(appName, revision)
+	if common.FileExists(envFilename) {
+		sealedEnv, err := godotenv.Read(envFilename)
+		if err != nil {
+			return r, fmt.Errorf("Unable to read revision %d: %w", revision, err)
+		}
+
+		r.Env, _, err = openEnvMap(sealedEnv)
+		if err != nil {
+			return r, fmt.Errorf("Unable to decrypt revision %d: %w", revision, err)
+		}
+		r.Restorable = true
+	}
+	if r.Fingerprints == nil {
+		r.Fingerprints = fingerprintEnvMap(r.Env)
+	}
+
+	return r, nil
+}
+
+// GetRevisions loads all revisions for an app or global environment in ascending order
+func GetRevisions(appName string) ([]Revision, error) {
+	revisions := []Revision{}
+	numbers, err := listRevisionNumbers(appName)
+	if err != nil {
+		return revisions, err
+	}
+
+	for _, number := range numbers {
+		revision, err := GetRevision(appName, number)
+		if err != nil {
+			return revisions, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, nil
+}
+
+// cloneHistory copies the config history from one app to another
+func cloneHistory(oldAppName string, newAppName string) error {
+	oldDirectory := getHistoryDirectory(oldAppName)
+	if !common.DirectoryExists(oldDirectory) {
+		return nil
+	}
+
+	return common.Copy(oldDirectory, getHistoryDirectory(newAppName))
+}
+
+func parseRevision(value string) (int, error) {
+	revision, err := strconv.Atoi(value)
+	if err != nil || revision < 1 {
+		return 0, fmt.Errorf("Invalid revision: %s", value)
+	}
+	return revision, nil
+}
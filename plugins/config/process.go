@@ -0,0 +1,272 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dokku/dokku/plugins/common"
+)
+
+// processEnvPrefix is the prefix of the files holding the config vars for each process type of an app
+const processEnvPrefix = "ENV.process."
+
+// processTypePattern matches valid process type names
+var processTypePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+func validateProcessType(processType string) error {
+	if !processTypePattern.MatchString(processType) {
+		return fmt.Errorf("Invalid process type: '%s'", processType)
+	}
+	return nil
+}
+
+// getAppNameForProcess validates the app name for a command that may target the environment for a process type
+func getAppNameForProcess(appName string, global bool, processType string) (string, error) {
+	if processType == "" {
+		return getAppNameOrGlobal(appName, global)
+	}
+
+	if global {
+		return appName, errors.New("The --process flag cannot be used with --global")
+	}
+
+	if err := validateProcessType(processType); err != nil {
+		return appName, err
+	}
+
+	return getAppNameOrGlobal(appName, false)
+}
+
+func getProcessFile(appName string, processType string) string {
+	return filepath.Join(common.MustGetEnv("DOKKU_ROOT"), appName, processEnvPrefix+processType)
+}
+
+// LoadProcessEnv loads the environment for a process type of an app, without the app or global environment
+func LoadProcessEnv(appName string, processType string) (*Env, error) {
+	if err := validateProcessType(processType); err != nil {
+		return nil, err
+	}
+
+	return loadFromFile(appName, getProcessFile(appName, processType))
+}
+
+// LoadMergedProcessEnv loads an app environment merged with the global environment
+// and the environment for the process type, with all config references resolved
+func LoadMergedProcessEnv(appName string, processType string) (*Env, error) {
+	if processType == "" {
+		return LoadMergedAppEnv(appName)
+	}
+
+	env, err := loadMergedAppEnv(appName)
+	if err != nil {
+		return nil, err
+	}
+
+	processEnv, err := LoadProcessEnv(appName, processType)
+	if err != nil {
+		return nil, err
+	}
+
+	env.Merge(processEnv)
+	resolver := newReferenceResolver()
+	_, err = resolver.resolveEnv(appName, env)
+	resolver.logUnresolved()
+	return env, err
+}
+
+// GetProcessTypesWithConfig returns the process types of an app that have config vars set
+func GetProcessTypesWithConfig(appName string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(common.MustGetEnv("DOKKU_ROOT"), appName, processEnvPrefix+"*"))
+	if err != nil {
+		return []string{}, err
+	}
+
+	processTypes := []string{}
+	for _, file := range files {
+		processType := strings.TrimPrefix(filepath.Base(file), processEnvPrefix)
+		if validateProcessType(processType) == nil {
+			processTypes = append(processTypes, processType)
+		}
+	}
+
+	sort.Strings(processTypes)
+	return processTypes, nil
+}
+
+// SetManyProcess sets config vars in the environment for a process type. If restart is true the app is restarted.
+func SetManyProcess(appName string, processType string, entries map[string]string, restart bool) error {
+	env, err := LoadProcessEnv(appName, processType)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		if err := validateKey(k); err != nil {
+			return err
+		}
+		keys = append(keys, k)
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	before := copyEnvMap(env.Map())
+	for k, v := range entries {
+		env.Set(k, v)
+	}
+
+	common.LogInfo1Quiet(fmt.Sprintf("Setting config vars for the %s process type", processType))
+	if os.Getenv("DOKKU_QUIET_OUTPUT") == "" {
+		fmt.Println(prettyPrintEnvEntries("       ", entries))
+	}
+
+	if err := writeProcessEnv(env); err != nil {
+		return err
+	}
+
+	logRevisionError(recordRevision(appName, processType, "set", before, env.Map()))
+	triggerUpdate(appName, "set", keys)
+	if restart {
+		restartForProcessEnv(appName)
+	}
+	return nil
+}
+
+// UnsetManyProcess unsets config vars in the environment for a process type. If restart is true the app is restarted.
+func UnsetManyProcess(appName string, processType string, keys []string, restart bool) error {
+	env, err := LoadProcessEnv(appName, processType)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		if err := validateKey(k); err != nil {
+			return err
+		}
+	}
+
+	before := copyEnvMap(env.Map())
+	changed := false
+	for _, k := range keys {
+		if _, hasKey := env.Get(k); hasKey {
+			common.LogInfo1Quiet(fmt.Sprintf("Unsetting %s for the %s process type", k, processType))
+			env.Unset(k)
+			changed = true
+		} else {
+			common.LogInfo1Quiet(fmt.Sprintf("Skipping %s, it is not set in the environment for the %s process type", k, processType))
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if err := writeProcessEnv(env); err != nil {
+		return err
+	}
+
+	logRevisionError(recordRevision(appName, processType, "unset", before, env.Map()))
+	triggerUpdate(appName, "unset", keys)
+	if restart {
+		restartForProcessEnv(appName)
+	}
+	return nil
+}
+
+// rollbackProcess restores the environment for a process type to a previous revision
+func rollbackProcess(appName string, r Revision, restart bool) error {
+	env, err := LoadProcessEnv(appName, r.ProcessType)
+	if err != nil {
+		return err
+	}
+
+	before := copyEnvMap(env.Map())
+	if len(diffEnvMaps(before, r.Env)) == 0 {
+		common.LogInfo1Quiet(fmt.Sprintf("Environment for the %s process type already matches revision %d", r.ProcessType, r.Revision))
+		return nil
+	}
+
+	common.LogInfo1Quiet(fmt.Sprintf("Rolling back config vars for the %s process type to revision %d", r.ProcessType, r.Revision))
+	env.Clear()
+	keys := []string{}
+	for k, v := range r.Env {
+		env.Set(k, v)
+		keys = append(keys, k)
+	}
+
+	if err := writeProcessEnv(env); err != nil {
+		return err
+	}
+
+	logRevisionError(recordRevision(appName, r.ProcessType, fmt.Sprintf("rollback:%d", r.Revision), before, env.Map()))
+	triggerUpdate(appName, "set", keys)
+	if restart {
+		restartForProcessEnv(appName)
+	}
+	return nil
+}
+
+// writeProcessEnv writes a process environment, removing the file once no config vars are left
+func writeProcessEnv(env *Env) error {
+	if env.Len() == 0 {
+		if err := os.Remove(env.Filename()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	if err := env.Write(); err != nil {
+		return err
+	}
+
+	return common.SetPermissions(common.SetPermissionInput{
+		Filename: env.Filename(),
+		Mode:     os.FileMode(0600),
+	})
+}
+
+func restartForProcessEnv(appName string) {
+	env, err := LoadAppEnv(appName)
+	if err != nil {
+		common.LogWarn(fmt.Sprintf("Unable to load config for %s: %s", appName, err.Error()))
+		return
+	}
+
+	if env.GetBoolDefault("DOKKU_APP_RESTORE", true) {
+		triggerRestart(appName)
+	}
+}
+
+// cloneProcessEnvs copies the environments for every process type from one app to another
+func cloneProcessEnvs(oldAppName string, newAppName string) error {
+	processTypes, err := GetProcessTypesWithConfig(oldAppName)
+	if err != nil {
+		return err
+	}
+
+	for _, processType := range processTypes {
+		oldEnv, err := LoadProcessEnv(oldAppName, processType)
+		if err != nil {
+			return err
+		}
+
+		newEnv, err := LoadProcessEnv(newAppName, processType)
+		if err != nil {
+			return err
+		}
+
+		newEnv.Merge(oldEnv)
+		if err := writeProcessEnv(newEnv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
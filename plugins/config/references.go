@@ -0,0 +1,316 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/dokku/dokku/plugins/common"
+)
+
+// referencePattern matches ${app:APP:KEY} and ${global:KEY} references within a config value
+var referencePattern = regexp.MustCompile(`\$\{(?:app:([a-z0-9][a-z0-9.-]*)|global):([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+
+// Reference is a reference from a config value to a key in another environment
+type Reference struct {
+	// AppName is the referenced app, or empty for the global environment
+	AppName string
+
+	// Key is the referenced key
+	Key string
+}
+
+// String returns the reference in its ${...} form
+func (r Reference) String() string {
+	return "${" + r.node() + "}"
+}
+
+// node returns a unique identifier for the referenced key
+func (r Reference) node() string {
+	if r.AppName == "" {
+		return "global:" + r.Key
+	}
+	return "app:" + r.AppName + ":" + r.Key
+}
+
+// parseReferences returns all references contained within a config value
+func parseReferences(value string) []Reference {
+	references := []Reference{}
+	for _, match := range referencePattern.FindAllStringSubmatch(value, -1) {
+		references = append(references, Reference{AppName: match[1], Key: match[2]})
+	}
+	return references
+}
+
+// unresolvedReferenceError is returned when a reference points to a missing app or key
+type unresolvedReferenceError struct {
+	reference Reference
+	reason    string
+}
+
+// Error returns the error message
+func (e *unresolvedReferenceError) Error() string {
+	return fmt.Sprintf("Unable to resolve config reference %s: %s", e.reference, e.reason)
+}
+
+// referenceResolver resolves config references, caching resolved values and the keys they depend on
+type referenceResolver struct {
+	envs         map[string]*Env
+	values       map[string]string
+	dependencies map[string]map[string]bool
+	sealed       map[string]bool
+	unresolved   map[string]error
+	stack        []string
+}
+
+func newReferenceResolver() *referenceResolver {
+	return &referenceResolver{
+		envs:         map[string]*Env{},
+		values:       map[string]string{},
+		dependencies: map[string]map[string]bool{},
+		sealed:       map[string]bool{},
+		unresolved:   map[string]error{},
+	}
+}
+
+// logUnresolved warns about every reference that was left unresolved
+func (r *referenceResolver) logUnresolved() {
+	nodes := []string{}
+	for node := range r.unresolved {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	for _, node := range nodes {
+		common.LogWarn(r.unresolved[node].Error())
+	}
+}
+
+// env loads the unresolved environment a reference points to
+func (r *referenceResolver) env(appName string) (*Env, error) {
+	if env, ok := r.envs[appName]; ok {
+		return env, nil
+	}
+
+	var env *Env
+	var err error
+	if appName == "" {
+		env, err = LoadGlobalEnv()
+	} else {
+		if !common.DirectoryExists(common.AppRoot(appName)) {
+			return nil, fmt.Errorf("App %s does not exist", appName)
+		}
+		env, err = loadMergedAppEnv(appName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.envs[appName] = env
+	return env, nil
+}
+
+// resolve returns the resolved value for a reference
+func (r *referenceResolver) resolve(reference Reference) (string, error) {
+	node := reference.node()
+	if value, ok := r.values[node]; ok {
+		return value, nil
+	}
+
+	for i, visited := range r.stack {
+		if visited == node {
+			cycle := append(append([]string{}, r.stack[i:]...), node)
+			return "", fmt.Errorf("Config reference cycle detected: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	env, err := r.env(reference.AppName)
+	if err != nil {
+		return "", &unresolvedReferenceError{reference: reference, reason: err.Error()}
+	}
+
+	raw, ok := env.Get(reference.Key)
+	if !ok {
+		return "", &unresolvedReferenceError{reference: reference, reason: "key is not set"}
+	}
+
+	r.stack = append(r.stack, node)
+	dependencies := map[string]bool{}
+	value, err := r.resolveValue(raw, dependencies)
+	r.stack = r.stack[:len(r.stack)-1]
+	if err != nil {
+		return "", err
+	}
+
+	r.values[node] = value
+	r.dependencies[node] = dependencies
+	r.sealed[node] = env.IsSealed(reference.Key)
+	return value, nil
+}
+
+// resolveValue replaces all references within a value, recording every key the value depends on.
+// References to a missing app or key are left as-is so that the rest of the environment still loads.
+func (r *referenceResolver) resolveValue(value string, dependencies map[string]bool) (string, error) {
+	var resolveErr error
+	resolved := referencePattern.ReplaceAllStringFunc(value, func(match string) string {
+		if resolveErr != nil {
+			return match
+		}
+
+		reference := parseReferences(match)[0]
+		resolvedValue, err := r.resolve(reference)
+		var unresolvedErr *unresolvedReferenceError
+		if errors.As(err, &unresolvedErr) {
+			r.unresolved[reference.node()] = err
+			dependencies[reference.node()] = true
+			return match
+		}
+		if err != nil {
+			resolveErr = err
+			return match
+		}
+
+		dependencies[reference.node()] = true
+		for dependency := range r.dependencies[reference.node()] {
+			dependencies[dependency] = true
+		}
+		return resolvedValue
+	})
+
+	return resolved, resolveErr
+}
+
+// resolveEnv resolves all references within a merged app environment
+func (r *referenceResolver) resolveEnv(appName string, env *Env) (map[string]map[string]bool, error) {
+	r.envs[appName] = env
+	dependencies := map[string]map[string]bool{}
+	for _, key := range env.Keys() {
+		raw, _ := env.Get(key)
+		if len(parseReferences(raw)) == 0 {
+			continue
+		}
+
+		value, err := r.resolve(Reference{AppName: appName, Key: key})
+		if err != nil {
+			return dependencies, err
+		}
+
+		dependencies[key] = r.dependencies[Reference{AppName: appName, Key: key}.node()]
+		env.Set(key, value)
+		for dependency := range dependencies[key] {
+			if r.sealed[dependency] {
+				if env.sealed == nil {
+					env.sealed = map[string]bool{}
+				}
+				env.sealed[key] = true
+			}
+		}
+	}
+
+	return dependencies, nil
+}
+
+// getReferenceDependents returns a map of referenced keys to the apps that depend on them
+func getReferenceDependents() (map[string][]string, error) {
+	dependents := map[string][]string{}
+	apps, err := common.UnfilteredDokkuApps()
+	if err != nil {
+		return dependents, nil
+	}
+
+	for _, appName := range apps {
+		env, err := loadMergedAppEnv(appName)
+		if err != nil {
+			common.LogWarn(fmt.Sprintf("Unable to load config for %s: %s", appName, err.Error()))
+			continue
+		}
+
+		dependencies, err := newReferenceResolver().resolveEnv(appName, env)
+		if err != nil {
+			common.LogWarn(fmt.Sprintf("Unable to resolve config references for %s: %s", appName, err.Error()))
+			continue
+		}
+
+		nodes := map[string]bool{}
+		for _, keyDependencies := range dependencies {
+			for node := range keyDependencies {
+				nodes[node] = true
+			}
+		}
+		for node := range nodes {
+			dependents[node] = append(dependents[node], appName)
+		}
+	}
+
+	for node := range dependents {
+		sort.Strings(dependents[node])
+	}
+	return dependents, nil
+}
+
+// getDependentApps returns the apps with config values that reference a key in the given environment
+func getDependentApps(appName string, keys []string) ([]string, error) {
+	if appName == "--global" {
+		appName = ""
+	}
+
+	referenceDependents, err := getReferenceDependents()
+	if err != nil {
+		return []string{}, err
+	}
+
+	apps := map[string]bool{}
+	for _, key := range keys {
+		for _, dependent := range referenceDependents[Reference{AppName: appName, Key: key}.node()] {
+			apps[dependent] = true
+		}
+	}
+
+	dependents := []string{}
+	for dependent := range apps {
+		dependents = append(dependents, dependent)
+	}
+	sort.Strings(dependents)
+	return dependents, nil
+}
+
+// restartDependentApps restarts all apps with config values that reference a key in the given environment
+func restartDependentApps(appName string, keys []string) error {
+	apps, err := getDependentApps(appName, keys)
+	if err != nil {
+		return err
+	}
+
+	dependents := []string{}
+	isDependent := map[string]bool{}
+	for _, dependent := range apps {
+		if dependent != appName {
+			dependents = append(dependents, dependent)
+			isDependent[dependent] = true
+		}
+	}
+
+	if len(dependents) == 0 {
+		return nil
+	}
+
+	common.LogInfo1(fmt.Sprintf("Restarting apps that reference the changed config vars: %s", strings.Join(dependents, ", ")))
+	return common.RunCommandAgainstAllApps(func(dependentAppName string) error {
+		if !isDependent[dependentAppName] {
+			return nil
+		}
+
+		env, err := LoadAppEnv(dependentAppName)
+		if err != nil {
+			return err
+		}
+
+		if env.GetBoolDefault("DOKKU_APP_RESTORE", true) {
+			triggerRestart(dependentAppName)
+		}
+		return nil
+	}, "config:restart-dependents", runtime.NumCPU())
+}
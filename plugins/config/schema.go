@@ -0,0 +1,352 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dokku/dokku/plugins/common"
+)
+
+// SchemaRule is the validation rule for a single config var
+type SchemaRule struct {
+	// Type is the type of the value, one of string, int, bool, url, enum or regex
+	Type string `json:"type,omitempty"`
+
+	// Required is whether the config var must be set to a non-empty value
+	Required bool `json:"required,omitempty"`
+
+	// Default is the value the config var is set to on deploy if it is not set, and may only be set via config:schema:set
+	Default string `json:"default,omitempty"`
+
+	// Values are the allowed values for the enum type
+	Values []string `json:"values,omitempty"`
+
+	// Pattern is the regular expression a value must fully match for the regex type
+	Pattern string `json:"pattern,omitempty"`
+
+	// Source is where the rule was declared, either app.json or config:schema:set
+	Source string `json:"source,omitempty"`
+}
+
+// Schema is a map of config var names to their validation rules
+type Schema map[string]SchemaRule
+
+// validSchemaTypes are the supported config var types
+var validSchemaTypes = map[string]bool{
+	"bool":   true,
+	"enum":   true,
+	"int":    true,
+	"regex":  true,
+	"string": true,
+	"url":    true,
+}
+
+// Validate checks that a rule is well-formed
+func (r SchemaRule) Validate() error {
+	ruleType := r.Type
+	if ruleType == "" {
+		ruleType = "string"
+	}
+
+	if !validSchemaTypes[ruleType] {
+		return fmt.Errorf("Invalid type %s, supported types: bool, enum, int, regex, string, url", r.Type)
+	}
+
+	if ruleType == "enum" && len(r.Values) == 0 {
+		return errors.New("The enum type requires at least one value")
+	}
+	if ruleType != "enum" && len(r.Values) > 0 {
+		return errors.New("Values may only be specified for the enum type")
+	}
+
+	if ruleType == "regex" {
+		if r.Pattern == "" {
+			return errors.New("The regex type requires a pattern")
+		}
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("Invalid pattern: %w", err)
+		}
+	}
+	if ruleType != "regex" && r.Pattern != "" {
+		return errors.New("A pattern may only be specified for the regex type")
+	}
+
+	if r.Default != "" {
+		if err := r.ValidateValue(r.Default); err != nil {
+			return fmt.Errorf("Invalid default: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateValue checks that a value matches the rule
+func (r SchemaRule) ValidateValue(value string) error {
+	if value == "" {
+		if r.Required {
+			return errors.New("value is required")
+		}
+		return nil
+	}
+
+	switch r.Type {
+	case "bool":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("expected a bool, got '%s'", value)
+		}
+	case "enum":
+		for _, allowed := range r.Values {
+			if value == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected one of %s, got '%s'", strings.Join(r.Values, ", "), value)
+	case "int":
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("expected an int, got '%s'", value)
+		}
+	case "regex":
+		re, err := regexp.Compile("^(?:" + r.Pattern + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("expected a value matching %s", r.Pattern)
+		}
+	case "url":
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("expected a url, got '%s'", value)
+		}
+	}
+
+	return nil
+}
+
+func getSchemaFile(appName string) string {
+	return filepath.Join(common.MustGetEnv("DOKKU_ROOT"), appName, "ENV.schema")
+}
+
+// getAppSchema returns the schema set via config:schema:set for an app
+func getAppSchema(appName string) (Schema, error) {
+	schema := Schema{}
+	b, err := os.ReadFile(getSchemaFile(appName))
+	if errors.Is(err, os.ErrNotExist) {
+		return schema, nil
+	}
+	if err != nil {
+		return schema, err
+	}
+
+	if err := json.Unmarshal(b, &schema); err != nil {
+		return schema, fmt.Errorf("Unable to parse config schema: %w", err)
+	}
+
+	for key, rule := range schema {
+		rule.Source = "config:schema:set"
+		schema[key] = rule
+	}
+	return schema, nil
+}
+
+// writeAppSchema stores the schema set via config:schema:set for an app
+func writeAppSchema(appName string, schema Schema) error {
+	filename := getSchemaFile(appName)
+	if len(schema) == 0 {
+		if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	for key, rule := range schema {
+		rule.Source = ""
+		schema[key] = rule
+	}
+
+	b, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filename, b, 0600); err != nil {
+		return fmt.Errorf("Unable to write config schema: %w", err)
+	}
+
+	return common.SetPermissions(common.SetPermissionInput{
+		Filename: filename,
+		Mode:     os.FileMode(0600),
+	})
+}
+
+// getAppJSONSchema returns the schema declared in the env section of an app's app.json
+func getAppJSONSchema(appName string) (Schema, error) {
+	schema := Schema{}
+	results, err := common.CallPlugnTrigger(common.PlugnTriggerInput{
+		Trigger: "app-json-get-env-schema",
+		Args:    []string{appName},
+	})
+	if err != nil {
+		return schema, fmt.Errorf("Unable to read app.json env schema: %w", err)
+	}
+
+	if len(strings.TrimSpace(results.StdoutContents())) == 0 {
+		return schema, nil
+	}
+
+	if err := json.Unmarshal(results.StdoutBytes(), &schema); err != nil {
+		return schema, fmt.Errorf("Unable to parse app.json env schema: %w", err)
+	}
+
+	for key, rule := range schema {
+		rule.Source = "app.json"
+		schema[key] = rule
+	}
+	return schema, nil
+}
+
+// GetSchema returns the config schema for an app, merging rules declared in
+// the app.json with rules set via config:schema:set
+func GetSchema(appName string) (Schema, error) {
+	if appName == "" || appName == "--global" {
+		return Schema{}, nil
+	}
+
+	schema, err := getAppJSONSchema(appName)
+	if err != nil {
+		return schema, err
+	}
+
+	appSchema, err := getAppSchema(appName)
+	if err != nil {
+		return schema, err
+	}
+
+	for key, rule := range appSchema {
+		schema[key] = rule
+	}
+
+	return schema, nil
+}
+
+// Keys returns the sorted keys in a schema
+func (s Schema) Keys() []string {
+	keys := []string{}
+	for key := range s {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validateEntries checks that config values being set match the app's schema.
+// Values that contain config references are checked on deploy instead.
+func validateEntries(appName string, entries map[string]string) error {
+	schema, err := GetSchema(appName)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{}
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	problems := []string{}
+	for _, key := range keys {
+		rule, ok := schema[key]
+		if !ok || len(parseReferences(entries[key])) > 0 {
+			continue
+		}
+
+		if err := rule.ValidateValue(entries[key]); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", key, err.Error()))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("Invalid config vars:\n%s", strings.Join(problems, "\n"))
+	}
+
+	return nil
+}
+
+// validateEnvAgainstSchema sets defaults for missing config vars and checks
+// that the app's merged environment matches the app's schema. This is the only
+// check for required config vars, including those declared in the app.json.
+func validateEnvAgainstSchema(appName string) error {
+	schema, err := GetSchema(appName)
+	if err != nil {
+		return err
+	}
+
+	if len(schema) == 0 {
+		return nil
+	}
+
+	env, err := LoadMergedAppEnv(appName)
+	if err != nil {
+		return err
+	}
+
+	defaults := map[string]string{}
+	for _, key := range schema.Keys() {
+		if _, ok := env.Get(key); !ok && schema[key].Default != "" {
+			defaults[key] = schema[key].Default
+		}
+	}
+
+	if len(defaults) > 0 {
+		common.LogInfo1("Setting config vars from schema defaults")
+		if err := setMany(appName, defaults, false, false, "schema-default"); err != nil {
+			return err
+		}
+
+		if env, err = LoadMergedAppEnv(appName); err != nil {
+			return err
+		}
+	}
+
+	missing := []string{}
+	problems := []string{}
+	for _, key := range schema.Keys() {
+		value, _ := env.Get(key)
+		if value == "" && schema[key].Required {
+			missing = append(missing, key)
+			continue
+		}
+
+		if err := schema[key].ValidateValue(value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", key, err.Error()))
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("Missing required config vars: %s", strings.Join(missing, ", "))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("Invalid config vars:\n%s", strings.Join(problems, "\n"))
+	}
+
+	return nil
+}
+
+// cloneSchema copies the config schema from one app to another
+func cloneSchema(oldAppName string, newAppName string) error {
+	schema, err := getAppSchema(oldAppName)
+	if err != nil {
+		return err
+	}
+
+	return writeAppSchema(newAppName, schema)
+}
@@ -19,16 +19,23 @@ Manage global and app-specific config vars
 Additional commands:`
 
 	helpContent = `
-    config (<app>|--global), Pretty-print an app or global environment
+    config [--reveal] (<app>|--global), Pretty-print an app or global environment
     config:bundle [--merged] (<app>|--global), Bundle environment into tarfile
     config:clear [--no-restart] (<app>|--global), Clears environment variables
-    config:export [--format=FORMAT] [--merged] (<app>|--global), Export a global or app environment
-    config:get [--quoted] (<app>|--global) KEY, Display a global or app-specific config value
-    config:import [--no-restart] [--replace] (<app>|--global) [FILE|-], Import environment from file
+    config:diff (<app>|--global) <revision> [<revision>], Show keys changed between two config revisions
+    config:export [--format=FORMAT] [--merged] [--process=PROCESS] (<app>|--global), Export a global or app environment
+    config:get [--quoted] [--process=PROCESS] (<app>|--global) KEY, Display a global or app-specific config value
+    config:history [--format=FORMAT] (<app>|--global), Show recorded config revisions
+    config:import [--format=FORMAT] [--no-restart] [--replace] (<app>|--global) [FILE|-], Import environment from file
     config:keys [--merged] (<app>|--global), Show keys set in environment
-    config:show [--merged] (<app>|--global), Show keys set in environment
-    config:set [--encoded] [--no-restart] (<app>|--global) KEY1=VALUE1 [KEY2=VALUE2 ...], Set one or more config vars
-    config:unset [--no-restart] (<app>|--global) KEY1 [KEY2 ...], Unset one or more config vars`
+    config:refs [--reveal] <app>, Show config references and the apps that depend on the app's keys
+    config:rollback [--no-restart] (<app>|--global) <revision>, Restore config vars from a previous revision
+    config:schema:set [--type=TYPE] [--required] [--default=VALUE] [--values=A,B] [--pattern=REGEX] <app> KEY, Set the validation rule for a config var
+    config:schema:show [--format=FORMAT] <app>, Show the config schema for an app
+    config:schema:unset <app> KEY, Remove the validation rule for a config var
+    config:show [--merged] [--process=PROCESS] [--reveal] (<app>|--global), Show keys set in environment
+    config:set [--encoded] [--no-restart] [--process=PROCESS] [--restart-dependents] (<app>|--global) KEY1=VALUE1 [KEY2=VALUE2 ...], Set one or more config vars
+    config:unset [--no-restart] [--process=PROCESS] [--restart-dependents] (<app>|--global) KEY1 [KEY2 ...], Unset one or more config vars`
 )
 
 func main() {
@@ -44,10 +51,11 @@ func main() {
 		shell := args.Bool("shell", false, "--shell: in a single-line for usage in command-line utilities [deprecated]")
 		export := args.Bool("export", false, "--export: print the env as eval-compatible exports [deprecated]")
 		merged := args.Bool("merged", false, "--merged: display the app's environment merged with the global environment")
+		reveal := args.Bool("reveal", false, "--reveal: display values that are encrypted at rest")
 		args.Parse(os.Args[2:])
 		appName := args.Arg(0)
 
-		if err := config.CommandShow(appName, *global, *merged, *shell, *export); err != nil {
+		if err := config.CommandShow(appName, *global, *merged, *shell, *export, *reveal, ""); err != nil {
 			common.LogFailWithError(err)
 		}
 	case "config:help":
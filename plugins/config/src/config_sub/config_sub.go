@@ -48,11 +48,12 @@ func main() {
 		global := args.Bool("global", false, "--global: use the global environment")
 		merged := args.Bool("merged", false, "--merged: merge app environment and global environment")
 		format := args.String("format", "exports", "--format: [ docker-args | docker-args-keys | exports | envfile | json | json-list | pack-keys | pretty | shell ] which format to export as)")
+		processType := args.String("process", "", "--process: use the environment for a process type")
 		args.Parse(os.Args[2:])
 		if !*global {
 			appName = args.Arg(0)
 		}
-		err = config.SubExport(appName, *merged, *format)
+		err = config.SubExport(appName, *merged, *format, *processType)
 	case "get":
 		args := flag.NewFlagSet("get", flag.ExitOnError)
 		global := args.Bool("global", false, "--global: use the global environment")
@@ -62,7 +63,7 @@ func main() {
 			appName = args.Arg(0)
 		}
 		keys := getKeys(args.Args(), *global)
-		err = config.SubGet(appName, keys, *quoted)
+		err = config.SubGet(appName, keys, *quoted, "")
 	case "keys":
 		args := flag.NewFlagSet("keys", flag.ExitOnError)
 		global := args.Bool("global", false, "--global: use the global environment")
@@ -76,11 +77,12 @@ func main() {
 		args := flag.NewFlagSet("show", flag.ExitOnError)
 		global := args.Bool("global", false, "--global: use the global environment")
 		merged := args.Bool("merged", false, "--merged: display the app's environment merged with the global environment")
+		reveal := args.Bool("reveal", false, "--reveal: display values that are encrypted at rest")
 		args.Parse(os.Args[2:])
 		if !*global {
 			appName = args.Arg(0)
 		}
-		err = config.SubShow(appName, *merged, false, false)
+		err = config.SubShow(appName, *merged, false, false, *reveal, "")
 	case "set":
 		args := flag.NewFlagSet("set", flag.ExitOnError)
 		global := args.Bool("global", false, "--global: use the global environment")
@@ -91,7 +93,7 @@ func main() {
 			appName = args.Arg(0)
 		}
 		pairs := getKeys(args.Args(), *global)
-		err = config.SubSet(appName, pairs, *noRestart, *encoded)
+		err = config.SubSet(appName, pairs, *noRestart, *encoded, false, "")
 	case "unset":
 		args := flag.NewFlagSet("unset", flag.ExitOnError)
 		global := args.Bool("global", false, "--global: use the global environment")
@@ -101,7 +103,7 @@ func main() {
 			appName = args.Arg(0)
 		}
 		keys := getKeys(args.Args(), *global)
-		err = config.SubUnset(appName, keys, *noRestart)
+		err = config.SubUnset(appName, keys, *noRestart, false, "")
 	default:
 		err = fmt.Errorf("Invalid plugin config_sub call: %s", action)
 	}
@@ -45,32 +45,59 @@ func main() {
 			appName = args.Arg(0)
 		}
 		err = config.CommandClear(appName, *global, *noRestart)
+	case "diff":
+		args := flag.NewFlagSet("config:diff", flag.ExitOnError)
+		global := args.Bool("global", false, "--global: use the global environment")
+		args.Parse(os.Args[2:])
+		if !*global {
+			appName = args.Arg(0)
+		}
+		revisions := getKeys(args.Args(), *global)
+		revisionA, revisionB := "", ""
+		if len(revisions) > 0 {
+			revisionA = revisions[0]
+		}
+		if len(revisions) > 1 {
+			revisionB = revisions[1]
+		}
+		err = config.CommandDiff(appName, *global, revisionA, revisionB)
 	case "export":
 		args := flag.NewFlagSet("config:export", flag.ExitOnError)
 		global := args.Bool("global", false, "--global: use the global environment")
 		merged := args.Bool("merged", false, "--merged: merge app environment and global environment")
-		format := args.String("format", "exports", "--format: [ docker-args | docker-args-keys | exports | envfile | json | json-list | pack-keys | pretty | shell ] which format to export as)")
+		format := args.String("format", "exports", "--format: [ docker-args | docker-args-keys | exports | envfile | json | json-list | k8s-configmap | k8s-secret | pack-keys | pretty | shell | toml | yaml ] which format to export as)")
+		processType := args.String("process", "", "--process: use the environment for a process type")
 		args.Parse(os.Args[2:])
 		if !*global {
 			appName = args.Arg(0)
 		}
-		err = config.CommandExport(appName, *global, *merged, *format)
+		err = config.CommandExport(appName, *global, *merged, *format, *processType)
 	case "get":
 		args := flag.NewFlagSet("config:get", flag.ExitOnError)
 		global := args.Bool("global", false, "--global: use the global environment")
 		quoted := args.Bool("quoted", false, "--quoted: get the value quoted")
+		processType := args.String("process", "", "--process: use the environment for a process type")
 		args.Parse(os.Args[2:])
 		if !*global {
 			appName = args.Arg(0)
 		}
 		keys := getKeys(args.Args(), *global)
-		err = config.CommandGet(appName, keys, *global, *quoted)
+		err = config.CommandGet(appName, keys, *global, *quoted, *processType)
+	case "history":
+		args := flag.NewFlagSet("config:history", flag.ExitOnError)
+		global := args.Bool("global", false, "--global: use the global environment")
+		format := args.String("format", "stdout", "--format: [ stdout | json ] which format to output as")
+		args.Parse(os.Args[2:])
+		if !*global {
+			appName = args.Arg(0)
+		}
+		err = config.CommandHistory(appName, *global, *format)
 	case "import":
 		args := flag.NewFlagSet("config:import", flag.ExitOnError)
 		global := args.Bool("global", false, "--global: use the global environment")
 		noRestart := args.Bool("no-restart", false, "--no-restart: no restart")
 		replace := args.Bool("replace", false, "--replace: replace existing config vars")
-		format := args.String("format", "envfile", "--format: [ envfile | json ] which format to export as)")
+		format := args.String("format", "envfile", "--format: [ envfile | json | k8s-configmap | k8s-secret | toml | yaml ] which format to import from")
 
 		args.Parse(os.Args[2:])
 		var filename string
@@ -90,36 +117,90 @@ func main() {
 			appName = args.Arg(0)
 		}
 		err = config.CommandKeys(appName, *global, *merged)
+	case "refs":
+		args := flag.NewFlagSet("config:refs", flag.ExitOnError)
+		reveal := args.Bool("reveal", false, "--reveal: display values that are encrypted at rest")
+		args.Parse(os.Args[2:])
+		appName = args.Arg(0)
+		err = config.CommandRefs(appName, *reveal)
+	case "rollback":
+		args := flag.NewFlagSet("config:rollback", flag.ExitOnError)
+		global := args.Bool("global", false, "--global: use the global environment")
+		noRestart := args.Bool("no-restart", false, "--no-restart: no restart")
+		args.Parse(os.Args[2:])
+		if !*global {
+			appName = args.Arg(0)
+		}
+		revision := ""
+		if revisions := getKeys(args.Args(), *global); len(revisions) > 0 {
+			revision = revisions[0]
+		}
+		err = config.CommandRollback(appName, *global, revision, *noRestart)
+	case "schema:set":
+		args := flag.NewFlagSet("config:schema:set", flag.ExitOnError)
+		ruleType := args.String("type", "string", "--type: the type of the value, one of bool, enum, int, regex, string or url")
+		required := args.Bool("required", false, "--required: require the config var to be set to a non-empty value")
+		defaultValue := args.String("default", "", "--default: a value to set the config var to on deploy if it is not set")
+		values := args.StringSlice("values", []string{}, "--values: a comma-separated list of allowed values for the enum type")
+		pattern := args.String("pattern", "", "--pattern: a regular expression values must fully match for the regex type")
+		args.Parse(os.Args[2:])
+		appName = args.Arg(0)
+		key := args.Arg(1)
+		err = config.CommandSchemaSet(appName, key, config.SchemaRule{
+			Type:     *ruleType,
+			Required: *required,
+			Default:  *defaultValue,
+			Values:   *values,
+			Pattern:  *pattern,
+		})
+	case "schema:show":
+		args := flag.NewFlagSet("config:schema:show", flag.ExitOnError)
+		format := args.String("format", "stdout", "--format: [ stdout | json ] which format to output as")
+		args.Parse(os.Args[2:])
+		appName = args.Arg(0)
+		err = config.CommandSchemaShow(appName, *format)
+	case "schema:unset":
+		args := flag.NewFlagSet("config:schema:unset", flag.ExitOnError)
+		args.Parse(os.Args[2:])
+		appName = args.Arg(0)
+		key := args.Arg(1)
+		err = config.CommandSchemaUnset(appName, key)
 	case "show":
 		args := flag.NewFlagSet("config:show", flag.ExitOnError)
 		global := args.Bool("global", false, "--global: use the global environment")
 		merged := args.Bool("merged", false, "--merged: display the app's environment merged with the global environment")
+		reveal := args.Bool("reveal", false, "--reveal: display values that are encrypted at rest")
+		processType := args.String("process", "", "--process: use the environment for a process type")
 		args.Parse(os.Args[2:])
 		if !*global {
 			appName = args.Arg(0)
 		}
-		err = config.CommandShow(appName, *global, *merged, false, false)
+		err = config.CommandShow(appName, *global, *merged, false, false, *reveal, *processType)
 	case "set":
 		args := flag.NewFlagSet("config:set", flag.ExitOnError)
 		global := args.Bool("global", false, "--global: use the global environment")
 		encoded := args.Bool("encoded", false, "--encoded: interpret VALUEs as base64")
 		noRestart := args.Bool("no-restart", false, "--no-restart: no restart")
+		restartDependents := args.Bool("restart-dependents", false, "--restart-dependents: restart apps that reference the changed config vars")
+		processType := args.String("process", "", "--process: set the config vars for a process type")
 		args.Parse(os.Args[2:])
 		if !*global {
 			appName = args.Arg(0)
 		}
 		pairs := getKeys(args.Args(), *global)
-		err = config.CommandSet(appName, pairs, *global, *noRestart, *encoded)
+		err = config.CommandSet(appName, pairs, *global, *noRestart, *encoded, *restartDependents, *processType)
 	case "unset":
 		args := flag.NewFlagSet("config:unset", flag.ExitOnError)
 		global := args.Bool("global", false, "--global: use the global environment")
 		noRestart := args.Bool("no-restart", false, "--no-restart: no restart")
+		restartDependents := args.Bool("restart-dependents", false, "--restart-dependents: restart apps that reference the unset config vars")
+		processType := args.String("process", "", "--process: unset the config vars for a process type")
 		args.Parse(os.Args[2:])
 		if !*global {
 			appName = args.Arg(0)
 		}
 		keys := getKeys(args.Args(), *global)
-		err = config.CommandUnset(appName, keys, *global, *noRestart)
+		err = config.CommandUnset(appName, keys, *global, *noRestart, *restartDependents, *processType)
 	default:
 		err = fmt.Errorf("Invalid plugin subcommand call: %s", subcommand)
 	}
@@ -24,7 +24,8 @@ func main() {
 		global := flag.Arg(1)
 		merged := flag.Arg(2)
 		format := flag.Arg(3)
-		err = config.TriggerConfigExport(appName, global, merged, format)
+		processType := flag.Arg(4)
+		err = config.TriggerConfigExport(appName, global, merged, format, processType)
 	case "config-get":
 		appName := flag.Arg(0)
 		key := flag.Arg(1)
@@ -46,6 +47,10 @@ func main() {
 			restart = flag.Arg(1)
 		}
 		err = config.TriggerConfigUnset(appName, key, common.ToBool(restart))
+	case "core-post-extract":
+		appName := flag.Arg(0)
+		sourceWorkDir := flag.Arg(1)
+		err = config.TriggerCorePostExtract(appName, sourceWorkDir)
 	case "post-app-clone-setup":
 		oldAppName := flag.Arg(0)
 		newAppName := flag.Arg(1)
@@ -21,25 +21,45 @@ func CommandClear(appName string, global bool, noRestart bool) error {
 	return SubClear(appName, noRestart)
 }
 
+// CommandDiff displays the keys changed between two revisions of an environment
+func CommandDiff(appName string, global bool, revisionA string, revisionB string) error {
+	appName, err := getAppNameOrGlobal(appName, global)
+	if err != nil {
+		return err
+	}
+
+	return SubDiff(appName, revisionA, revisionB)
+}
+
 // CommandExport outputs all env vars (merged or not, global or not)
 // in the specified format for consumption by other tools
-func CommandExport(appName string, global bool, merged bool, format string) error {
-	appName, err := getAppNameOrGlobal(appName, global)
+func CommandExport(appName string, global bool, merged bool, format string, processType string) error {
+	appName, err := getAppNameForProcess(appName, global, processType)
 	if err != nil {
 		return err
 	}
 
-	return SubExport(appName, merged, format)
+	return SubExport(appName, merged, format, processType)
 }
 
 // CommandGet gets the value for the specified environment variable
-func CommandGet(appName string, keys []string, global bool, quoted bool) error {
+func CommandGet(appName string, keys []string, global bool, quoted bool, processType string) error {
+	appName, err := getAppNameForProcess(appName, global, processType)
+	if err != nil {
+		return err
+	}
+
+	return SubGet(appName, keys, quoted, processType)
+}
+
+// CommandHistory displays the recorded revisions of an environment
+func CommandHistory(appName string, global bool, format string) error {
 	appName, err := getAppNameOrGlobal(appName, global)
 	if err != nil {
 		return err
 	}
 
-	return SubGet(appName, keys, quoted)
+	return SubHistory(appName, format)
 }
 
 // CommandImport imports environment variables from a file
@@ -62,32 +82,82 @@ func CommandKeys(appName string, global bool, merged bool) error {
 	return SubKeys(appName, merged)
 }
 
-// CommandSet sets one or more environment variable pairs
-func CommandSet(appName string, pairs []string, global bool, noRestart bool, encoded bool) error {
+// CommandRefs displays the config references for an app and the apps that depend on its keys
+func CommandRefs(appName string, reveal bool) error {
+	appName, err := getAppNameOrGlobal(appName, false)
+	if err != nil {
+		return err
+	}
+
+	return SubRefs(appName, reveal)
+}
+
+// CommandRollback restores an environment to a previous revision
+func CommandRollback(appName string, global bool, revision string, noRestart bool) error {
 	appName, err := getAppNameOrGlobal(appName, global)
 	if err != nil {
 		return err
 	}
 
-	return SubSet(appName, pairs, noRestart, encoded)
+	return SubRollback(appName, revision, noRestart)
+}
+
+// CommandSchemaSet sets the validation rule for a config var
+func CommandSchemaSet(appName string, key string, rule SchemaRule) error {
+	appName, err := getAppNameOrGlobal(appName, false)
+	if err != nil {
+		return err
+	}
+
+	return SubSchemaSet(appName, key, rule)
+}
+
+// CommandSchemaShow displays the config schema for an app
+func CommandSchemaShow(appName string, format string) error {
+	appName, err := getAppNameOrGlobal(appName, false)
+	if err != nil {
+		return err
+	}
+
+	return SubSchemaShow(appName, format)
+}
+
+// CommandSchemaUnset removes the validation rule for a config var
+func CommandSchemaUnset(appName string, key string) error {
+	appName, err := getAppNameOrGlobal(appName, false)
+	if err != nil {
+		return err
+	}
+
+	return SubSchemaUnset(appName, key)
+}
+
+// CommandSet sets one or more environment variable pairs
+func CommandSet(appName string, pairs []string, global bool, noRestart bool, encoded bool, restartDependents bool, processType string) error {
+	appName, err := getAppNameForProcess(appName, global, processType)
+	if err != nil {
+		return err
+	}
+
+	return SubSet(appName, pairs, noRestart, encoded, restartDependents, processType)
 }
 
 // CommandShow pretty-prints the specified environment vaiables
-func CommandShow(appName string, global bool, merged bool, shell bool, export bool) error {
-	appName, err := getAppNameOrGlobal(appName, global)
+func CommandShow(appName string, global bool, merged bool, shell bool, export bool, reveal bool, processType string) error {
+	appName, err := getAppNameForProcess(appName, global, processType)
 	if err != nil {
 		return err
 	}
 
-	return SubShow(appName, merged, shell, export)
+	return SubShow(appName, merged, shell, export, reveal, processType)
 }
 
 // CommandUnset unsets one or more keys in a specified environment
-func CommandUnset(appName string, keys []string, global bool, noRestart bool) error {
-	appName, err := getAppNameOrGlobal(appName, global)
+func CommandUnset(appName string, keys []string, global bool, noRestart bool, restartDependents bool, processType string) error {
+	appName, err := getAppNameForProcess(appName, global, processType)
 	if err != nil {
 		return err
 	}
 
-	return SubUnset(appName, keys, noRestart)
+	return SubUnset(appName, keys, noRestart, restartDependents, processType)
 }
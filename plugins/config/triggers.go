@@ -6,7 +6,7 @@ import (
 )
 
 // TriggerConfigExport returns a global config value by key
-func TriggerConfigExport(appName string, global string, merged string, format string) error {
+func TriggerConfigExport(appName string, global string, merged string, format string, processType string) error {
 	g, err := strconv.ParseBool(global)
 	if err != nil {
 		return err
@@ -17,12 +17,12 @@ func TriggerConfigExport(appName string, global string, merged string, format st
 		return err
 	}
 
-	appName, err = getAppNameOrGlobal(appName, g)
+	appName, err = getAppNameForProcess(appName, g, processType)
 	if err != nil {
 		return err
 	}
 
-	return export(appName, m, format)
+	return export(appName, processType, m, format)
 }
 
 // TriggerConfigGet returns an app config value by key
@@ -51,6 +51,12 @@ func TriggerConfigUnset(appName string, key string, restart bool) error {
 	return nil
 }
 
+// TriggerCorePostExtract sets config vars with schema defaults and fails the
+// deploy if the app config does not match the config schema
+func TriggerCorePostExtract(appName string, sourceWorkDir string) error {
+	return validateEnvAgainstSchema(appName)
+}
+
 // TriggerPostAppCloneSetup creates new buildpacks files
 func TriggerPostAppCloneSetup(oldAppName string, newAppName string) error {
 	oldEnv, err := LoadAppEnv(oldAppName)
@@ -68,6 +74,18 @@ func TriggerPostAppCloneSetup(oldAppName string, newAppName string) error {
 		return fmt.Errorf("Unable to write new environment: %s", err.Error())
 	}
 
+	if err := cloneHistory(oldAppName, newAppName); err != nil {
+		return fmt.Errorf("Unable to copy config history: %s", err.Error())
+	}
+
+	if err := cloneSchema(oldAppName, newAppName); err != nil {
+		return fmt.Errorf("Unable to copy config schema: %s", err.Error())
+	}
+
+	if err := cloneProcessEnvs(oldAppName, newAppName); err != nil {
+		return fmt.Errorf("Unable to copy process type environments: %s", err.Error())
+	}
+
 	return nil
 }
 
@@ -88,5 +106,17 @@ func TriggerPostAppRenameSetup(oldAppName string, newAppName string) error {
 		return fmt.Errorf("Unable to write new environment: %s", err.Error())
 	}
 
+	if err := cloneHistory(oldAppName, newAppName); err != nil {
+		return fmt.Errorf("Unable to copy config history: %s", err.Error())
+	}
+
+	if err := cloneSchema(oldAppName, newAppName); err != nil {
+		return fmt.Errorf("Unable to copy config schema: %s", err.Error())
+	}
+
+	if err := cloneProcessEnvs(oldAppName, newAppName); err != nil {
+		return fmt.Errorf("Unable to copy process type environments: %s", err.Error())
+	}
+
 	return nil
 }
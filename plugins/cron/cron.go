@@ -4,27 +4,37 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	appjson "github.com/dokku/dokku/plugins/app-json"
 	"github.com/dokku/dokku/plugins/common"
 
 	"github.com/multiformats/go-base36"
 	cronparser "github.com/robfig/cron/v3"
+	"golang.org/x/sync/errgroup"
 )
 
 var (
 	// DefaultProperties is a map of all valid cron properties with corresponding default property values
 	DefaultProperties = map[string]string{
-		"mailfrom":    "",
-		"mailto":      "",
-		"maintenance": "false",
+		"history-limit":      "50",
+		"mailfrom":           "",
+		"mailto":             "",
+		"maintenance":        "false",
+		"notify-webhook-url": "",
+		"runner":             "crontab",
+		"timezone":           "",
 	}
 
 	// GlobalProperties is a map of all valid global cron properties
 	GlobalProperties = map[string]bool{
-		"mailfrom":    true,
-		"mailto":      true,
-		"maintenance": true,
+		"history-limit":      true,
+		"mailfrom":           true,
+		"mailto":             true,
+		"maintenance":        true,
+		"notify-webhook-url": true,
+		"runner":             true,
+		"timezone":           true,
 	}
 )
 
@@ -41,6 +51,12 @@ type CronTask struct {
 	// Command is the command to run
 	Command string `json:"command"`
 
+	// Name is an optional name for the cron task, which other tasks can reference in after
+	Name string `json:"name,omitempty"`
+
+	// After is the id of the cron task that must succeed before this task runs
+	After string `json:"after,omitempty"`
+
 	// Global is whether the cron task is global
 	Global bool `json:"global,omitempty"`
 
@@ -50,6 +66,21 @@ type CronTask struct {
 	// ConcurrencyPolicy is the concurrency policy for the cron command
 	ConcurrencyPolicy string `json:"concurrency_policy"`
 
+	// MissedRunPolicy is what the cron daemon does with runs missed while it was not running
+	MissedRunPolicy string `json:"missed_run_policy,omitempty"`
+
+	// JitterSeconds is the maximum random delay the cron daemon adds before each run
+	JitterSeconds int `json:"jitter_seconds,omitempty"`
+
+	// Timezone is the timezone the schedule is evaluated in, defaulting to the host timezone
+	Timezone string `json:"timezone,omitempty"`
+
+	// NextRun is the next time the cron task is scheduled to run, only set when listing tasks
+	NextRun string `json:"next_run,omitempty"`
+
+	// PreviousRun is the last time the cron task was scheduled to run, only set when listing tasks
+	PreviousRun string `json:"previous_run,omitempty"`
+
 	// AltCommand is an alternate command to run
 	AltCommand string `json:"-"`
 
@@ -66,7 +97,48 @@ type CronTask struct {
 	Maintenance bool `json:"maintenance"`
 }
 
-// DokkuRunCommand returns the dokku run command to execute for a given cron task
+// HasSecondsField returns whether the cron schedule includes a seconds field,
+// which is only supported by the cron daemon runner
+func (t CronTask) HasSecondsField() bool {
+	return len(strings.Fields(t.Schedule)) == 6
+}
+
+// ParsedSchedule returns the parsed cron schedule, evaluated in the timezone of the task
+func (t CronTask) ParsedSchedule() (cronparser.Schedule, error) {
+	return ParseSchedule(t.Schedule, t.Timezone)
+}
+
+// CrontabSchedule returns the schedule to write to the system crontab. The system
+// crontab always runs in the host timezone, so tasks with a timezone are invoked on
+// every candidate minute and skipped by 'cron:run --if-due' unless actually due.
+func (t CronTask) CrontabSchedule() string {
+	if t.Timezone == "" || t.AltCommand != "" {
+		return t.Schedule
+	}
+
+	minute := "*"
+	if hasWholeHourOffset(t.Timezone) {
+		fields := strings.Fields(t.Schedule)
+		if len(fields) == 5 {
+			minute = fields[0]
+		} else if strings.HasPrefix(t.Schedule, "@") && !strings.HasPrefix(t.Schedule, "@every") {
+			minute = "0"
+		}
+	}
+
+	return fmt.Sprintf("%s * * * *", minute)
+}
+
+// CrontabCommand returns the command to write to the system crontab
+func (t CronTask) CrontabCommand() string {
+	if t.Timezone == "" || t.AltCommand != "" {
+		return t.DokkuRunCommand()
+	}
+
+	return fmt.Sprintf("dokku cron:run --scheduled --if-due %s %s", t.App, t.ID)
+}
+
+// DokkuRunCommand returns the dokku command to execute for a given cron task
 func (t CronTask) DokkuRunCommand() string {
 	if t.AltCommand != "" {
 		if t.LogFile != "" {
@@ -75,7 +147,69 @@ func (t CronTask) DokkuRunCommand() string {
 		return t.AltCommand
 	}
 
-	return fmt.Sprintf("dokku run --concurrency-policy %s --cron-id %s %s %s", t.ConcurrencyPolicy, t.ID, t.App, t.Command)
+	// cron:run records the result of each scheduled run in the cron history
+	return fmt.Sprintf("dokku cron:run --scheduled %s %s", t.App, t.ID)
+}
+
+// ParseSchedule parses a cron schedule, allowing an optional leading seconds field.
+// If a timezone is specified, the schedule is evaluated in that timezone.
+func ParseSchedule(schedule string, timezone string) (cronparser.Schedule, error) {
+	if timezone != "" {
+		schedule = fmt.Sprintf("CRON_TZ=%s %s", timezone, schedule)
+	}
+
+	parser := cronparser.NewParser(cronparser.SecondOptional | cronparser.Minute | cronparser.Hour | cronparser.Dom | cronparser.Month | cronparser.Dow | cronparser.Descriptor)
+	return parser.Parse(schedule)
+}
+
+// hasWholeHourOffset returns whether a timezone is always a whole number of hours
+// away from the host timezone, checked at both solstices to account for daylight saving time
+func hasWholeHourOffset(timezone string) bool {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return false
+	}
+
+	year := time.Now().Year()
+	for _, month := range []time.Month{time.January, time.July} {
+		t := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		_, offset := t.In(location).Zone()
+		_, localOffset := t.In(time.Local).Zone()
+		if (offset-localOffset)%3600 != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsDue returns whether a schedule is due to run in the current minute
+func IsDue(schedule cronparser.Schedule, now time.Time) bool {
+	minute := now.Truncate(time.Minute)
+	return schedule.Next(minute.Add(-time.Second)).Equal(minute)
+}
+
+// PreviousRun returns the last time a schedule ran at or before the given time. As cron
+// schedules can only be iterated forwards, increasingly large windows are searched.
+func PreviousRun(schedule cronparser.Schedule, now time.Time) (time.Time, bool) {
+	windows := []time.Duration{time.Minute, time.Hour, 24 * time.Hour, 7 * 24 * time.Hour, 31 * 24 * time.Hour, 366 * 24 * time.Hour, 5 * 366 * 24 * time.Hour}
+	for _, window := range windows {
+		next := schedule.Next(now.Add(-window))
+		if next.IsZero() || next.After(now) {
+			continue
+		}
+
+		previous := next
+		for {
+			next = schedule.Next(previous)
+			if next.IsZero() || next.After(now) {
+				return previous, true
+			}
+			previous = next
+		}
+	}
+
+	return time.Time{}, false
 }
 
 // FetchCronTasksInput is the input for the FetchCronTasks function
@@ -90,6 +224,8 @@ func FetchCronTasks(input FetchCronTasksInput) ([]CronTask, error) {
 	appName := input.AppName
 	tasks := []CronTask{}
 	isAppCronInMaintenance := reportComputedMaintenance(appName) == "true"
+	runner := GetRunner()
+	defaultTimezone := getComputedProperty(appName, "timezone")
 
 	if input.AppJSON == nil && input.AppName == "" {
 		return tasks, fmt.Errorf("Missing app name or app.json")
@@ -123,7 +259,7 @@ func FetchCronTasks(input FetchCronTasksInput) ([]CronTask, error) {
 			continue
 		}
 
-		if c.Schedule == "" {
+		if c.Schedule == "" && c.After == "" {
 			if input.WarnToFailure {
 				return tasks, fmt.Errorf("Missing cron schedule for app %s (index %d)", appName, i)
 			}
@@ -132,10 +268,37 @@ func FetchCronTasks(input FetchCronTasksInput) ([]CronTask, error) {
 			continue
 		}
 
-		parser := cronparser.NewParser(cronparser.Minute | cronparser.Hour | cronparser.Dom | cronparser.Month | cronparser.Dow | cronparser.Descriptor)
-		_, err := parser.Parse(c.Schedule)
-		if err != nil {
-			return tasks, fmt.Errorf("Invalid cron schedule for app %s (schedule %s): %s", appName, c.Schedule, err.Error())
+		if c.Schedule != "" && c.After != "" {
+			return tasks, fmt.Errorf("Invalid cron task for app %s (index %d): schedule and after cannot both be specified", appName, i)
+		}
+
+		// tasks that run after another task are not scheduled on their own
+		timezone := ""
+		if c.Schedule != "" {
+			timezone = c.Timezone
+			if timezone == "" {
+				timezone = defaultTimezone
+			}
+			if timezone != "" {
+				if _, err := time.LoadLocation(timezone); err != nil {
+					return tasks, fmt.Errorf("Invalid cron timezone for app %s (schedule %s): %s", appName, c.Schedule, timezone)
+				}
+			}
+
+			_, err := ParseSchedule(c.Schedule, timezone)
+			if err != nil {
+				return tasks, fmt.Errorf("Invalid cron schedule for app %s (schedule %s): %s", appName, c.Schedule, err.Error())
+			}
+
+			if len(strings.Fields(c.Schedule)) == 6 && runner != "daemon" {
+				// tasks deployed while the daemon runner was selected are skipped once it is unset
+				if input.WarnToFailure {
+					return tasks, fmt.Errorf("Invalid cron schedule for app %s (schedule %s): a seconds field requires the daemon cron runner", appName, c.Schedule)
+				}
+
+				common.LogWarn(fmt.Sprintf("Skipping cron task for app %s (schedule %s), a seconds field requires the daemon cron runner", appName, c.Schedule))
+				continue
+			}
 		}
 
 		cronID := GenerateCommandID(appName, c)
@@ -158,19 +321,34 @@ func FetchCronTasks(input FetchCronTasksInput) ([]CronTask, error) {
 			return tasks, fmt.Errorf("Invalid cron concurrency policy for app %s (schedule %s): %s", appName, c.Schedule, c.ConcurrencyPolicy)
 		}
 
+		if c.MissedRunPolicy == "" {
+			c.MissedRunPolicy = "skip"
+		}
+		if c.MissedRunPolicy != "skip" && c.MissedRunPolicy != "run-once" {
+			return tasks, fmt.Errorf("Invalid cron missed run policy for app %s (schedule %s): %s", appName, c.Schedule, c.MissedRunPolicy)
+		}
+		if c.JitterSeconds < 0 {
+			return tasks, fmt.Errorf("Invalid cron jitter seconds for app %s (schedule %s): %d", appName, c.Schedule, c.JitterSeconds)
+		}
+
 		tasks = append(tasks, CronTask{
 			App:               appName,
 			Command:           c.Command,
 			Schedule:          c.Schedule,
 			ID:                cronID,
+			Name:              c.Name,
+			After:             c.After,
 			ConcurrencyPolicy: c.ConcurrencyPolicy,
+			MissedRunPolicy:   c.MissedRunPolicy,
+			JitterSeconds:     c.JitterSeconds,
+			Timezone:          timezone,
 			Maintenance:       isAppCronInMaintenance || maintenance,
 			AppInMaintenance:  isAppCronInMaintenance,
 			TaskInMaintenance: maintenance,
 		})
 	}
 
-	return tasks, nil
+	return resolveCronDependencies(appName, tasks, input.WarnToFailure)
 }
 
 // FetchGlobalCronTasks returns a list of global cron tasks
@@ -212,7 +390,92 @@ func FetchGlobalCronTasks() ([]CronTask, error) {
 	return tasks, nil
 }
 
+// FetchSchedulerCronTasks returns the cron tasks that are not in maintenance for all apps
+// using the specified scheduler, along with any injected global cron tasks
+func FetchSchedulerCronTasks(scheduler string) ([]CronTask, error) {
+	apps, _ := common.UnfilteredDokkuApps()
+
+	g := new(errgroup.Group)
+	results := make(chan []CronTask, len(apps)+1)
+	for _, appName := range apps {
+		appName := appName
+		g.Go(func() error {
+			if common.GetAppScheduler(appName) != scheduler {
+				results <- []CronTask{}
+				return nil
+			}
+
+			c, err := FetchCronTasks(FetchCronTasksInput{AppName: appName})
+			if err != nil {
+				results <- []CronTask{}
+				common.LogWarn(err.Error())
+				return nil
+			}
+
+			results <- c
+			return nil
+		})
+	}
+
+	g.Go(func() error {
+		tasks := []CronTask{}
+		response, _ := common.CallPlugnTrigger(common.PlugnTriggerInput{
+			Trigger: "cron-entries",
+			Args:    []string{scheduler},
+		})
+		for _, line := range strings.Split(response.StdoutContents(), "\n") {
+			if strings.TrimSpace(line) == "" {
+				results <- []CronTask{}
+				return nil
+			}
+
+			parts := strings.Split(line, ";")
+			if len(parts) != 2 && len(parts) != 3 {
+				results <- []CronTask{}
+				return fmt.Errorf("Invalid injected cron task: %v", line)
+			}
+
+			id := base36.EncodeToStringLc([]byte(strings.Join(parts, ";;;")))
+			task := CronTask{
+				ID:          id,
+				Schedule:    parts[0],
+				AltCommand:  parts[1],
+				Maintenance: false,
+			}
+			if len(parts) == 3 {
+				task.LogFile = parts[2]
+			}
+			tasks = append(tasks, task)
+		}
+		results <- tasks
+		return nil
+	})
+
+	err := g.Wait()
+	close(results)
+
+	tasks := []CronTask{}
+	if err != nil {
+		return tasks, err
+	}
+
+	for result := range results {
+		for _, task := range result {
+			if !task.Maintenance {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+
+	return tasks, nil
+}
+
 // GenerateCommandID creates a unique ID for a given app/command/schedule combination
 func GenerateCommandID(appName string, c appjson.CronTask) string {
-	return base36.EncodeToStringLc([]byte(appName + "===" + c.Command + "===" + c.Schedule))
+	schedule := c.Schedule
+	if c.After != "" {
+		schedule = "after:" + c.After
+	}
+
+	return base36.EncodeToStringLc([]byte(appName + "===" + c.Command + "===" + schedule))
 }
@@ -0,0 +1,314 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dokku/dokku/plugins/common"
+
+	cronparser "github.com/robfig/cron/v3"
+)
+
+// runningTask is a cron task run started by the cron daemon
+type runningTask struct {
+	cancel context.CancelFunc
+}
+
+// cronDaemon schedules the cron tasks for all docker-local apps in-process
+type cronDaemon struct {
+	mu        sync.Mutex
+	wg        sync.WaitGroup
+	scheduler *cronparser.Cron
+	running   map[string][]*runningTask
+	lastRuns  map[string]time.Time
+}
+
+func getDaemonPidFile() string {
+	return filepath.Join(common.GetDataDirectory("cron"), "daemon.pid")
+}
+
+func getDaemonStateFile() string {
+	return filepath.Join(common.GetDataDirectory("cron"), "daemon-state.json")
+}
+
+// getDaemonPid returns the pid of the running cron daemon, if any
+func getDaemonPid() (int, bool) {
+	b, err := os.ReadFile(getDaemonPidFile())
+	if err != nil {
+		return 0, false
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return 0, false
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return 0, false
+	}
+
+	if err := process.Signal(syscall.Signal(0)); err != nil {
+		return 0, false
+	}
+
+	return pid, true
+}
+
+// SignalDaemonReload tells a running cron daemon to reload its cron tasks
+func SignalDaemonReload() error {
+	pid, ok := getDaemonPid()
+	if !ok {
+		return nil
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+
+	if err := process.Signal(syscall.SIGHUP); err != nil {
+		return fmt.Errorf("Unable to reload the cron daemon: %w", err)
+	}
+
+	return nil
+}
+
+// setDaemonServiceEnabled enables and starts or disables and stops the dokku-cron systemd service
+func setDaemonServiceEnabled(enabled bool) error {
+	systemctlPath, err := exec.LookPath("systemctl")
+	if err != nil || !common.FileExists("/etc/systemd/system/dokku-cron.service") {
+		if enabled {
+			common.LogWarn("The dokku-cron systemd service is not installed, run 'dokku cron:daemon' under a process supervisor")
+		}
+		return nil
+	}
+
+	action := "disable"
+	if enabled {
+		action = "enable"
+	}
+
+	result, err := common.CallExecCommand(common.ExecCommandInput{
+		Command: "sudo",
+		Args:    []string{systemctlPath, action, "--now", "dokku-cron"},
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to %s the cron daemon service: %w", action, err)
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("Unable to %s the cron daemon service: %s", action, result.StderrContents())
+	}
+
+	return nil
+}
+
+// GetRunner returns the configured cron runner, either crontab or daemon
+func GetRunner() string {
+	return common.PropertyGetDefault("cron", "--global", "runner", DefaultProperties["runner"])
+}
+
+// CommandDaemon runs the cron tasks for all docker-local apps in the foreground
+func CommandDaemon() error {
+	if pid, ok := getDaemonPid(); ok && pid != os.Getpid() {
+		return fmt.Errorf("The cron daemon is already running with pid %d", pid)
+	}
+
+	if err := common.CreateDataDirectory("cron"); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(getDaemonPidFile(), []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		return fmt.Errorf("Unable to write cron daemon pid file: %w", err)
+	}
+	defer os.Remove(getDaemonPidFile())
+
+	d := &cronDaemon{
+		running:  map[string][]*runningTask{},
+		lastRuns: loadLastRuns(),
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM)
+
+	d.reload()
+	for sig := range signals {
+		if sig == syscall.SIGHUP {
+			common.LogInfo1("Reloading cron tasks")
+			d.reload()
+			continue
+		}
+
+		common.LogInfo1("Stopping cron daemon, waiting for running cron tasks to finish")
+		d.scheduler.Stop()
+		d.wg.Wait()
+		return nil
+	}
+
+	return nil
+}
+
+// reload replaces the scheduled cron tasks with the current ones. Runs in progress are not interrupted.
+func (d *cronDaemon) reload() {
+	if d.scheduler != nil {
+		d.scheduler.Stop()
+	}
+	d.scheduler = cronparser.New()
+	defer d.scheduler.Start()
+
+	if GetRunner() != "daemon" {
+		common.LogWarn("The cron runner is not set to daemon, no cron tasks will be scheduled")
+		common.LogWarn("Set it via 'dokku cron:set --global runner daemon'")
+		return
+	}
+
+	tasks, err := FetchSchedulerCronTasks("docker-local")
+	if err != nil {
+		common.LogWarn(fmt.Sprintf("Unable to fetch cron tasks: %s", err.Error()))
+		return
+	}
+
+	now := time.Now()
+	scheduled := map[string]bool{}
+	for _, task := range tasks {
+		task := task
+
+		// tasks that run after another task are run by cron:run once that task succeeds
+		if task.After != "" {
+			continue
+		}
+
+		schedule, err := task.ParsedSchedule()
+		if err != nil {
+			common.LogWarn(fmt.Sprintf("Skipping cron task %s with invalid schedule %s: %s", task.ID, task.Schedule, err.Error()))
+			continue
+		}
+
+		d.scheduler.Schedule(schedule, cronparser.FuncJob(func() {
+			d.run(task)
+		}))
+		scheduled[task.ID] = true
+
+		if task.MissedRunPolicy != "run-once" {
+			continue
+		}
+
+		d.mu.Lock()
+		lastRun, ok := d.lastRuns[task.ID]
+		d.mu.Unlock()
+		if ok && schedule.Next(lastRun).Before(now) {
+			common.LogInfo1(fmt.Sprintf("Running missed cron task %s", task.ID))
+			go d.run(task)
+		}
+	}
+
+	d.mu.Lock()
+	for id := range d.lastRuns {
+		if !scheduled[id] {
+			delete(d.lastRuns, id)
+		}
+	}
+	d.saveLastRuns()
+	d.mu.Unlock()
+
+	common.LogInfo1(fmt.Sprintf("Scheduled %d cron tasks", len(scheduled)))
+}
+
+// run executes a single run of a cron task, applying its jitter and concurrency policy
+func (d *cronDaemon) run(task CronTask) {
+	if task.JitterSeconds > 0 {
+		time.Sleep(time.Duration(rand.Intn(task.JitterSeconds+1)) * time.Second)
+	}
+
+	d.mu.Lock()
+	if running := d.running[task.ID]; len(running) > 0 {
+		switch task.ConcurrencyPolicy {
+		case "forbid":
+			d.mu.Unlock()
+			common.LogWarn(fmt.Sprintf("Skipping cron task %s, a previous run is still in progress", task.ID))
+			return
+		case "replace":
+			common.LogWarn(fmt.Sprintf("Replacing running cron task %s", task.ID))
+			for _, r := range running {
+				r.cancel()
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	current := &runningTask{cancel: cancel}
+	d.running[task.ID] = append(d.running[task.ID], current)
+	d.lastRuns[task.ID] = time.Now().UTC()
+	d.saveLastRuns()
+	d.wg.Add(1)
+	d.mu.Unlock()
+
+	defer func() {
+		cancel()
+		d.mu.Lock()
+		running := []*runningTask{}
+		for _, r := range d.running[task.ID] {
+			if r != current {
+				running = append(running, r)
+			}
+		}
+		d.running[task.ID] = running
+		d.mu.Unlock()
+		d.wg.Done()
+	}()
+
+	common.LogInfo1(fmt.Sprintf("Running cron task %s", task.ID))
+	cmd := exec.CommandContext(ctx, "/bin/bash", "-c", task.DokkuRunCommand())
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// run each task in its own process group so that replacing a run stops the whole run
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+
+	if err := cmd.Run(); err != nil {
+		common.LogWarn(fmt.Sprintf("Cron task %s failed: %s", task.ID, err.Error()))
+	}
+}
+
+// loadLastRuns reads the time each cron task was last run by the cron daemon
+func loadLastRuns() map[string]time.Time {
+	lastRuns := map[string]time.Time{}
+	b, err := os.ReadFile(getDaemonStateFile())
+	if err != nil {
+		return lastRuns
+	}
+
+	if err := json.Unmarshal(b, &lastRuns); err != nil {
+		common.LogWarn(fmt.Sprintf("Ignoring invalid cron daemon state file: %s", err.Error()))
+		return map[string]time.Time{}
+	}
+
+	return lastRuns
+}
+
+// saveLastRuns persists the last run times so missed runs can be detected after a restart.
+// The caller must hold the lock.
+func (d *cronDaemon) saveLastRuns() {
+	b, err := json.Marshal(d.lastRuns)
+	if err != nil {
+		return
+	}
+
+	if err := os.WriteFile(getDaemonStateFile(), b, 0644); err != nil {
+		common.LogWarn(fmt.Sprintf("Unable to write cron daemon state file: %s", err.Error()))
+	}
+}
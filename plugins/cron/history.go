@@ -0,0 +1,289 @@
+package cron
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dokku/dokku/plugins/common"
+)
+
+// maxRecordedOutputBytes is the maximum amount of output kept for a single cron task run
+const maxRecordedOutputBytes = 10 * 1024
+
+// CronRun is a struct that represents a single invocation of a cron task
+type CronRun struct {
+	// CronID is the id of the cron task that was run
+	CronID string `json:"cron_id"`
+
+	// App is the app the cron task belongs to
+	App string `json:"app"`
+
+	// Command is the command that was run
+	Command string `json:"command"`
+
+	// Source is how the cron task was invoked, either scheduled or manual
+	Source string `json:"source"`
+
+	// StartedAt is the time the cron task started
+	StartedAt time.Time `json:"started_at"`
+
+	// FinishedAt is the time the cron task finished
+	FinishedAt time.Time `json:"finished_at"`
+
+	// ExitCode is the exit code of the cron task
+	ExitCode int `json:"exit_code"`
+
+	// Output is the tail of the combined stdout and stderr of the cron task
+	Output string `json:"output"`
+
+	// OutputTruncated is whether the output was truncated before being recorded
+	OutputTruncated bool `json:"output_truncated"`
+
+	// PipelineID is the id of the cron task that started the pipeline this run is part of
+	PipelineID string `json:"pipeline_id,omitempty"`
+
+	// PipelineRunID identifies the pipeline run this run is part of
+	PipelineRunID string `json:"pipeline_run_id,omitempty"`
+}
+
+// Duration returns how long the cron task ran for
+func (r CronRun) Duration() time.Duration {
+	return r.FinishedAt.Sub(r.StartedAt).Round(time.Second)
+}
+
+// Status returns a human readable status for the cron task run
+func (r CronRun) Status() string {
+	if r.ExitCode == 0 {
+		return "succeeded"
+	}
+	return "failed"
+}
+
+// FailureNotifier sends a notification when a cron task run fails
+type FailureNotifier interface {
+	// Name returns the name of the notifier
+	Name() string
+
+	// Notify sends a notification for a failed cron task run
+	Notify(run CronRun) error
+}
+
+// triggerNotifier notifies plugins of failed runs via the cron-failed plugin trigger
+type triggerNotifier struct{}
+
+// Name returns the name of the notifier
+func (n triggerNotifier) Name() string {
+	return "cron-failed trigger"
+}
+
+// Notify calls the cron-failed plugin trigger with the run as json on stdin
+func (n triggerNotifier) Notify(run CronRun) error {
+	b, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	_, err = common.CallPlugnTrigger(common.PlugnTriggerInput{
+		Trigger:     "cron-failed",
+		Args:        []string{run.App, run.CronID, strconv.Itoa(run.ExitCode)},
+		Stdin:       bytes.NewReader(b),
+		StreamStdio: true,
+	})
+	return err
+}
+
+// webhookNotifier notifies an http endpoint of failed runs
+type webhookNotifier struct {
+	url string
+}
+
+// Name returns the name of the notifier
+func (n webhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Notify posts the run as json to the webhook url
+func (n webhookNotifier) Notify(run CronRun) error {
+	b, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "dokku-cron")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("Webhook returned status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// getFailureNotifiers returns the notifiers to use for failed runs of an app's cron tasks
+func getFailureNotifiers(appName string) []FailureNotifier {
+	notifiers := []FailureNotifier{triggerNotifier{}}
+	if url := getComputedProperty(appName, "notify-webhook-url"); url != "" {
+		notifiers = append(notifiers, webhookNotifier{url: url})
+	}
+
+	return notifiers
+}
+
+// getComputedProperty returns the app value for a property, falling back to the global value and then the default
+func getComputedProperty(appName string, key string) string {
+	value := common.PropertyGet("cron", appName, key)
+	if value != "" {
+		return value
+	}
+
+	return common.PropertyGetDefault("cron", "--global", key, DefaultProperties[key])
+}
+
+// getHistoryLimit returns the number of runs to keep for each of an app's cron tasks
+func getHistoryLimit(appName string) int {
+	limit, err := strconv.Atoi(getComputedProperty(appName, "history-limit"))
+	if err != nil || limit < 0 {
+		limit, _ = strconv.Atoi(DefaultProperties["history-limit"])
+	}
+
+	return limit
+}
+
+func getHistoryDirectory(appName string) string {
+	return filepath.Join(common.GetAppDataDirectory("cron", appName), "history")
+}
+
+// truncateOutput keeps the tail of the output, as the end of the output is usually the most relevant on failure
+func truncateOutput(output string) (string, bool) {
+	if len(output) <= maxRecordedOutputBytes {
+		return output, false
+	}
+
+	return output[len(output)-maxRecordedOutputBytes:], true
+}
+
+// recordRun writes a cron task run to the app's history and prunes old runs of the same task
+func recordRun(run CronRun) error {
+	limit := getHistoryLimit(run.App)
+	if limit == 0 {
+		return nil
+	}
+
+	directory := getHistoryDirectory(run.App)
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	filename := filepath.Join(directory, fmt.Sprintf("%d-%s.json", run.StartedAt.UnixNano(), run.CronID))
+	if err := os.WriteFile(filename, b, 0644); err != nil {
+		return err
+	}
+
+	if err := common.SetPermissions(common.SetPermissionInput{
+		Filename: filename,
+		Mode:     os.FileMode(0644),
+	}); err != nil {
+		return err
+	}
+
+	return pruneRuns(run.App, run.CronID, limit)
+}
+
+// pruneRuns removes all but the most recent runs of a cron task
+func pruneRuns(appName string, cronID string, limit int) error {
+	files, err := filepath.Glob(filepath.Join(getHistoryDirectory(appName), fmt.Sprintf("*-%s.json", cronID)))
+	if err != nil {
+		return err
+	}
+
+	if len(files) <= limit {
+		return nil
+	}
+
+	sortRunFiles(files)
+	for _, file := range files[limit:] {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sortRunFiles sorts run files from newest to oldest
+func sortRunFiles(files []string) {
+	startedAt := func(file string) int64 {
+		parts := strings.SplitN(filepath.Base(file), "-", 2)
+		i, _ := strconv.ParseInt(parts[0], 10, 64)
+		return i
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return startedAt(files[i]) > startedAt(files[j])
+	})
+}
+
+// FetchCronRuns returns the recorded runs for an app, newest first, optionally filtered to a single cron task
+func FetchCronRuns(appName string, cronID string) ([]CronRun, error) {
+	runs := []CronRun{}
+	pattern := "*.json"
+	if cronID != "" {
+		pattern = fmt.Sprintf("*-%s.json", cronID)
+	}
+
+	files, err := filepath.Glob(filepath.Join(getHistoryDirectory(appName), pattern))
+	if err != nil {
+		return runs, err
+	}
+
+	sortRunFiles(files)
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return runs, err
+		}
+
+		var run CronRun
+		if err := json.Unmarshal(b, &run); err != nil {
+			common.LogWarn(fmt.Sprintf("Skipping invalid cron history file %s: %s", file, err.Error()))
+			continue
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, nil
+}
+
+// notifyFailure sends a failed cron task run to all configured notifiers
+func notifyFailure(run CronRun) {
+	for _, notifier := range getFailureNotifiers(run.App) {
+		if err := notifier.Notify(run); err != nil {
+			common.LogWarn(fmt.Sprintf("Unable to send cron failure notification via %s: %s", notifier.Name(), err.Error()))
+		}
+	}
+}
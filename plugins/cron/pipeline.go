@@ -0,0 +1,221 @@
+package cron
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dokku/dokku/plugins/common"
+)
+
+// CronPipeline is a struct that represents a cron task and the tasks that run after it
+type CronPipeline struct {
+	// ID is the id of the cron task that starts the pipeline
+	ID string `json:"id"`
+
+	// Name is the name of the cron task that starts the pipeline
+	Name string `json:"name,omitempty"`
+
+	// Status is the status of the last pipeline run, one of never-run, running, succeeded or failed
+	Status string `json:"status"`
+
+	// StartedAt is the time the last pipeline run started
+	StartedAt time.Time `json:"started_at,omitempty"`
+
+	// Steps are the cron tasks in the pipeline, in the order they are run
+	Steps []CronPipelineStep `json:"steps"`
+}
+
+// CronPipelineStep is a struct that represents a single cron task within a pipeline
+type CronPipelineStep struct {
+	// ID is the id of the cron task
+	ID string `json:"id"`
+
+	// Name is the name of the cron task
+	Name string `json:"name,omitempty"`
+
+	// Command is the command the cron task runs
+	Command string `json:"command"`
+
+	// Status is the status of the cron task in the last pipeline run, one of pending, succeeded, failed or skipped
+	Status string `json:"status"`
+}
+
+// resolveCronDependencies replaces the name or id in each task's after with the id of the task it runs after,
+// and ensures that the tasks do not depend on each other in a cycle
+func resolveCronDependencies(appName string, tasks []CronTask, warnToFailure bool) ([]CronTask, error) {
+	ids := map[string]bool{}
+	names := map[string]string{}
+	for _, task := range tasks {
+		ids[task.ID] = true
+		if task.Name == "" {
+			continue
+		}
+
+		if _, ok := names[task.Name]; ok {
+			return tasks, fmt.Errorf("Invalid cron task for app %s: name %s is used by more than one task", appName, task.Name)
+		}
+		names[task.Name] = task.ID
+	}
+
+	resolved := []CronTask{}
+	byID := map[string]CronTask{}
+	for _, task := range tasks {
+		if task.After != "" {
+			if id, ok := names[task.After]; ok {
+				task.After = id
+			} else if !ids[task.After] {
+				if warnToFailure {
+					return tasks, fmt.Errorf("Invalid cron task for app %s: no task matches after %s", appName, task.After)
+				}
+
+				common.LogWarn(fmt.Sprintf("Invalid cron task for app %s: no task matches after %s", appName, task.After))
+				continue
+			}
+		}
+
+		resolved = append(resolved, task)
+		byID[task.ID] = task
+	}
+
+	// a task that runs after a dropped task would never run, so it is dropped as well
+	for dropped := true; dropped; {
+		dropped = false
+		kept := []CronTask{}
+		for _, task := range resolved {
+			if _, ok := byID[task.After]; task.After != "" && !ok {
+				if warnToFailure {
+					return tasks, fmt.Errorf("Invalid cron task for app %s: task %s runs after invalid task %s", appName, task.ID, task.After)
+				}
+
+				common.LogWarn(fmt.Sprintf("Invalid cron task for app %s: task %s runs after invalid task %s", appName, task.ID, task.After))
+				delete(byID, task.ID)
+				dropped = true
+				continue
+			}
+
+			kept = append(kept, task)
+		}
+		resolved = kept
+	}
+
+	for _, task := range resolved {
+		seen := map[string]bool{task.ID: true}
+		for current := task; current.After != ""; {
+			if seen[current.After] {
+				return tasks, fmt.Errorf("Invalid cron task for app %s: task %s depends on itself via after", appName, task.ID)
+			}
+			seen[current.After] = true
+
+			next, ok := byID[current.After]
+			if !ok {
+				break
+			}
+			current = next
+		}
+	}
+
+	return resolved, nil
+}
+
+// PipelineSteps returns a cron task followed by every task that runs after it, in the order they are declared.
+// Tasks in maintenance are excluded, along with any tasks that run after them.
+func PipelineSteps(task CronTask, tasks []CronTask) []CronTask {
+	steps := []CronTask{task}
+	for _, t := range tasks {
+		if t.After == task.ID && !t.TaskInMaintenance {
+			steps = append(steps, PipelineSteps(t, tasks)...)
+		}
+	}
+
+	return steps
+}
+
+// FetchCronPipelines returns the pipelines for an app, along with the status of their last run
+func FetchCronPipelines(appName string) ([]CronPipeline, error) {
+	pipelines := []CronPipeline{}
+	tasks, err := FetchCronTasks(FetchCronTasksInput{AppName: appName})
+	if err != nil {
+		return pipelines, err
+	}
+
+	runs, err := FetchCronRuns(appName, "")
+	if err != nil {
+		return pipelines, err
+	}
+
+	for _, task := range tasks {
+		if task.After != "" {
+			continue
+		}
+
+		steps := PipelineSteps(task, tasks)
+		if len(steps) == 1 {
+			continue
+		}
+
+		// runs are sorted newest first, so the first matching run belongs to the last pipeline run
+		pipelineRunID := ""
+		stepRuns := map[string]CronRun{}
+		for _, run := range runs {
+			if run.PipelineID != task.ID {
+				continue
+			}
+			if pipelineRunID == "" {
+				pipelineRunID = run.PipelineRunID
+			}
+			if run.PipelineRunID == pipelineRunID {
+				stepRuns[run.CronID] = run
+			}
+		}
+
+		pipeline := CronPipeline{
+			ID:     task.ID,
+			Name:   task.Name,
+			Status: "never-run",
+			Steps:  []CronPipelineStep{},
+		}
+		if run, ok := stepRuns[task.ID]; ok {
+			pipeline.StartedAt = run.StartedAt
+		}
+
+		failed := false
+		succeeded := 0
+		for _, step := range steps {
+			status := "pending"
+			if run, ok := stepRuns[step.ID]; ok {
+				status = run.Status()
+			} else if failed {
+				status = "skipped"
+			}
+
+			switch status {
+			case "failed":
+				failed = true
+			case "succeeded":
+				succeeded++
+			}
+
+			pipeline.Steps = append(pipeline.Steps, CronPipelineStep{
+				ID:      step.ID,
+				Name:    step.Name,
+				Command: step.Command,
+				Status:  status,
+			})
+		}
+
+		if pipelineRunID != "" {
+			switch {
+			case failed:
+				pipeline.Status = "failed"
+			case succeeded == len(steps):
+				pipeline.Status = "succeeded"
+			default:
+				pipeline.Status = "running"
+			}
+		}
+
+		pipelines = append(pipelines, pipeline)
+	}
+
+	return pipelines, nil
+}
@@ -0,0 +1,60 @@
+package cron
+
+import (
+	"testing"
+)
+
+func taskIDs(tasks []CronTask) []string {
+	ids := []string{}
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func TestResolveCronDependencies(t *testing.T) {
+	tasks := []CronTask{
+		{ID: "a", Name: "build", Schedule: "@daily"},
+		{ID: "b", After: "build"},
+		{ID: "c", After: "b"},
+	}
+
+	resolved, err := resolveCronDependencies("test-app", tasks, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(resolved) != 3 || resolved[1].After != "a" || resolved[2].After != "b" {
+		t.Fatalf("unexpected tasks: %+v", resolved)
+	}
+
+	tasks = []CronTask{
+		{ID: "a", Schedule: "@daily", After: "a"},
+	}
+	if _, err := resolveCronDependencies("test-app", tasks, true); err == nil {
+		t.Fatal("expected an error for a task that runs after itself")
+	}
+}
+
+func TestResolveCronDependenciesDroppedTarget(t *testing.T) {
+	// b runs after a task that does not exist, so c - which runs after b - must not be kept without b
+	tasks := []CronTask{
+		{ID: "a", Schedule: "@daily"},
+		{ID: "b", After: "missing"},
+		{ID: "c", After: "b"},
+		{ID: "d", After: "c"},
+	}
+
+	if _, err := resolveCronDependencies("test-app", tasks, true); err == nil {
+		t.Fatal("expected an error for a task that runs after a missing task")
+	}
+
+	resolved, err := resolveCronDependencies("test-app", tasks, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ids := taskIDs(resolved)
+	if len(ids) != 1 || ids[0] != "a" {
+		t.Fatalf("expected only task a to be kept, got %v", ids)
+	}
+}
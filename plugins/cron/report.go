@@ -15,12 +15,22 @@ func ReportSingleApp(appName string, format string, infoFlag string) error {
 	}
 
 	flags := map[string]common.ReportFunc{
-		"--cron-mailfrom":             reportMailfrom,
-		"--cron-mailto":               reportMailto,
-		"--cron-task-count":           reportTasks,
-		"--cron-global-maintenance":   reportGlobalMaintenance,
-		"--cron-computed-maintenance": reportComputedMaintenance,
-		"--cron-maintenance":          reportMaintenance,
+		"--cron-computed-history-limit":      reportComputedHistoryLimit,
+		"--cron-global-history-limit":        reportGlobalHistoryLimit,
+		"--cron-history-limit":               reportHistoryLimit,
+		"--cron-computed-notify-webhook-url": reportComputedNotifyWebhookURL,
+		"--cron-global-notify-webhook-url":   reportGlobalNotifyWebhookURL,
+		"--cron-notify-webhook-url":          reportNotifyWebhookURL,
+		"--cron-global-runner":               reportGlobalRunner,
+		"--cron-computed-timezone":           reportComputedTimezone,
+		"--cron-global-timezone":             reportGlobalTimezone,
+		"--cron-timezone":                    reportTimezone,
+		"--cron-mailfrom":                    reportMailfrom,
+		"--cron-mailto":                      reportMailto,
+		"--cron-task-count":                  reportTasks,
+		"--cron-global-maintenance":          reportGlobalMaintenance,
+		"--cron-computed-maintenance":        reportComputedMaintenance,
+		"--cron-maintenance":                 reportMaintenance,
 	}
 
 	extraFlags := addCronMaintenanceFlags(appName, infoFlag)
@@ -86,3 +96,43 @@ func reportComputedMaintenance(appName string) string {
 func reportMaintenance(appName string) string {
 	return common.PropertyGet("cron", appName, "maintenance")
 }
+
+func reportComputedHistoryLimit(appName string) string {
+	return getComputedProperty(appName, "history-limit")
+}
+
+func reportGlobalHistoryLimit(_ string) string {
+	return common.PropertyGetDefault("cron", "--global", "history-limit", DefaultProperties["history-limit"])
+}
+
+func reportHistoryLimit(appName string) string {
+	return common.PropertyGet("cron", appName, "history-limit")
+}
+
+func reportComputedNotifyWebhookURL(appName string) string {
+	return getComputedProperty(appName, "notify-webhook-url")
+}
+
+func reportGlobalNotifyWebhookURL(_ string) string {
+	return common.PropertyGet("cron", "--global", "notify-webhook-url")
+}
+
+func reportNotifyWebhookURL(appName string) string {
+	return common.PropertyGet("cron", appName, "notify-webhook-url")
+}
+
+func reportGlobalRunner(_ string) string {
+	return GetRunner()
+}
+
+func reportComputedTimezone(appName string) string {
+	return getComputedProperty(appName, "timezone")
+}
+
+func reportGlobalTimezone(_ string) string {
+	return common.PropertyGet("cron", "--global", "timezone")
+}
+
+func reportTimezone(appName string) string {
+	return common.PropertyGet("cron", appName, "timezone")
+}
@@ -1,6 +1,12 @@
 package cron
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
 
 func validateSetValue(appName string, key string, value string) error {
 	if key == "mailfrom" && appName != "--global" {
@@ -11,5 +17,33 @@ func validateSetValue(appName string, key string, value string) error {
 		return errors.New("Property cannot be specified on a per-app basis")
 	}
 
+	if key == "runner" && appName != "--global" {
+		return errors.New("Property cannot be specified on a per-app basis")
+	}
+
+	if key == "runner" && value != "" && value != "crontab" && value != "daemon" {
+		return errors.New("Invalid runner value, must be one of: crontab, daemon")
+	}
+
+	if key == "timezone" && value != "" {
+		if _, err := time.LoadLocation(value); err != nil {
+			return fmt.Errorf("Invalid timezone value: %s", value)
+		}
+	}
+
+	if key == "history-limit" && value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return errors.New("Invalid history-limit value, must be a non-negative integer")
+		}
+	}
+
+	if key == "notify-webhook-url" && value != "" {
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("Invalid notify-webhook-url value, must be an http or https url")
+		}
+	}
+
 	return nil
 }
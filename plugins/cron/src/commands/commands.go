@@ -18,10 +18,13 @@ Manage scheduled cron tasks
 Additional commands:`
 
 	helpContent = `
+    cron:daemon, Run scheduled cron tasks in the foreground instead of via the system crontab
+    cron:history <app> [--cron-id <cron_id>] [--format json|stdout], List recorded runs of the cron tasks for an app
     cron:list <app> [--format json|stdout], List scheduled cron tasks for an app
+    cron:pipelines <app> [--format json|stdout], List cron task pipelines for an app and the status of their last run
     cron:report [<app>] [<flag>], Display report about an app
     cron:resume <app> <cron_id>, Resume a cron task
-    cron:run <app> <cron_id> [--detach], Run a cron task on the fly
+    cron:run <app> <cron_id|name> [--detach], Run a cron task on the fly
     cron:set [--global|<app>] <key> <value>, Set or clear a cron property for an app
     cron:suspend <app> <cron_id>, Suspend a cron task`
 )
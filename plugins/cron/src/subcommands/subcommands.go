@@ -18,6 +18,17 @@ func main() {
 
 	var err error
 	switch subcommand {
+	case "daemon":
+		args := flag.NewFlagSet("cron:daemon", flag.ExitOnError)
+		args.Parse(os.Args[2:])
+		err = cron.CommandDaemon()
+	case "history":
+		args := flag.NewFlagSet("cron:history", flag.ExitOnError)
+		cronID := args.String("cron-id", "", "--cron-id: only show runs of the specified cron task")
+		format := args.String("format", "stdout", "format: [ stdout | json ]")
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		err = cron.CommandHistory(appName, *cronID, *format)
 	case "list":
 		args := flag.NewFlagSet("cron:list", flag.ExitOnError)
 		global := args.Bool("global", false, "--global: set a global property")
@@ -28,6 +39,12 @@ func main() {
 			appName = "--global"
 		}
 		err = cron.CommandList(appName, *format)
+	case "pipelines":
+		args := flag.NewFlagSet("cron:pipelines", flag.ExitOnError)
+		format := args.String("format", "stdout", "format: [ stdout | json ]")
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		err = cron.CommandPipelines(appName, *format)
 	case "report":
 		args := flag.NewFlagSet("cron:report", flag.ExitOnError)
 		format := args.String("format", "stdout", "format: [ stdout | json ]")
@@ -46,10 +63,12 @@ func main() {
 	case "run":
 		args := flag.NewFlagSet("cron:run", flag.ExitOnError)
 		detached := args.Bool("detach", false, "--detach: run the container in a detached mode")
+		scheduled := args.Bool("scheduled", false, "--scheduled: record the run as invoked by the cron schedule")
+		ifDue := args.Bool("if-due", false, "--if-due: only run the task if it is due in its timezone")
 		args.Parse(os.Args[2:])
 		appName := args.Arg(0)
 		cronID := args.Arg(1)
-		err = cron.CommandRun(appName, cronID, *detached)
+		err = cron.CommandRun(appName, cronID, *detached, *scheduled, *ifDue)
 	case "set":
 		args := flag.NewFlagSet("cron:set", flag.ExitOnError)
 		global := args.Bool("global", false, "--global: set a global property")
@@ -41,6 +41,9 @@ func main() {
 		oldAppName := flag.Arg(0)
 		newAppName := flag.Arg(1)
 		err = cron.TriggerPostAppRenameSetup(oldAppName, newAppName)
+	case "post-create":
+		appName := flag.Arg(0)
+		err = cron.TriggerPostCreate(appName)
 	case "post-delete":
 		appName := flag.Arg(0)
 		err = cron.TriggerPostDelete(appName)
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dokku/dokku/plugins/common"
 
@@ -41,8 +43,23 @@ func CommandList(appName string, format string) error {
 		}
 	}
 
+	now := time.Now()
+	for i, task := range tasks {
+		schedule, err := task.ParsedSchedule()
+		if err != nil {
+			continue
+		}
+
+		if next := schedule.Next(now); !next.IsZero() {
+			tasks[i].NextRun = next.Format(time.RFC3339)
+		}
+		if previous, ok := PreviousRun(schedule, now); ok {
+			tasks[i].PreviousRun = previous.Format(time.RFC3339)
+		}
+	}
+
 	if format == "stdout" {
-		output := []string{"ID | Schedule | Concurrency | Maintenance | Command"}
+		output := []string{"ID | Schedule | Concurrency | Maintenance | Next Run | Previous Run | Command"}
 		for _, task := range tasks {
 			maintenance := "false"
 			if task.Maintenance {
@@ -52,7 +69,11 @@ func CommandList(appName string, format string) error {
 					maintenance = "true (app)"
 				}
 			}
-			output = append(output, fmt.Sprintf("%s | %s | %s | %t | %s", task.ID, task.Schedule, task.ConcurrencyPolicy, maintenance, task.Command))
+			schedule := task.Schedule
+			if task.After != "" {
+				schedule = "after " + task.After
+			}
+			output = append(output, fmt.Sprintf("%s | %s | %s | %s | %s | %s | %s", task.ID, schedule, task.ConcurrencyPolicy, maintenance, task.NextRun, task.PreviousRun, task.Command))
 		}
 
 		result := columnize.SimpleFormat(output)
@@ -69,6 +90,91 @@ func CommandList(appName string, format string) error {
 	return nil
 }
 
+// CommandHistory lists the recorded runs of the cron tasks for a given app
+func CommandHistory(appName string, cronID string, format string) error {
+	if format == "" {
+		format = "stdout"
+	}
+
+	if format != "stdout" && format != "json" {
+		return fmt.Errorf("Invalid format specified, supported formats: json, stdout")
+	}
+
+	if err := common.VerifyAppName(appName); err != nil {
+		return err
+	}
+
+	runs, err := FetchCronRuns(appName, cronID)
+	if err != nil {
+		return err
+	}
+
+	if format == "stdout" {
+		output := []string{"ID | Source | Started | Duration | Exit Code | Status | Command"}
+		for _, run := range runs {
+			output = append(output, fmt.Sprintf("%s | %s | %s | %s | %d | %s | %s", run.CronID, run.Source, run.StartedAt.Format(time.RFC3339), run.Duration(), run.ExitCode, run.Status(), run.Command))
+		}
+
+		result := columnize.SimpleFormat(output)
+		fmt.Println(result)
+		return nil
+	}
+
+	out, err := json.Marshal(runs)
+	if err != nil {
+		return err
+	}
+	common.Log(string(out))
+
+	return nil
+}
+
+// CommandPipelines lists the cron task pipelines for a given app along with the status of their last run
+func CommandPipelines(appName string, format string) error {
+	if format == "" {
+		format = "stdout"
+	}
+
+	if format != "stdout" && format != "json" {
+		return fmt.Errorf("Invalid format specified, supported formats: json, stdout")
+	}
+
+	if err := common.VerifyAppName(appName); err != nil {
+		return err
+	}
+
+	pipelines, err := FetchCronPipelines(appName)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		out, err := json.Marshal(pipelines)
+		if err != nil {
+			return err
+		}
+		common.Log(string(out))
+		return nil
+	}
+
+	for _, pipeline := range pipelines {
+		startedAt := "never"
+		if !pipeline.StartedAt.IsZero() {
+			startedAt = pipeline.StartedAt.Format(time.RFC3339)
+		}
+
+		common.LogInfo2Quiet(fmt.Sprintf("%s pipeline (%s, started %s)", pipeline.ID, pipeline.Status, startedAt))
+		output := []string{"Step | ID | Name | Status | Command"}
+		for i, step := range pipeline.Steps {
+			output = append(output, fmt.Sprintf("%d | %s | %s | %s | %s", i+1, step.ID, step.Name, step.Status, step.Command))
+		}
+
+		fmt.Println(columnize.SimpleFormat(output))
+	}
+
+	return nil
+}
+
 // CommandReport displays a cron report for one or more apps
 func CommandReport(appName string, format string, infoFlag string) error {
 	if len(appName) == 0 {
@@ -97,7 +203,7 @@ func CommandResume(appName string, cronID string) error {
 }
 
 // CommandRun executes a cron task on the fly
-func CommandRun(appName string, cronID string, detached bool) error {
+func CommandRun(appName string, cronID string, detached bool, scheduled bool, ifDue bool) error {
 	if err := common.VerifyAppName(appName); err != nil {
 		return err
 	}
@@ -111,24 +217,27 @@ func CommandRun(appName string, cronID string, detached bool) error {
 		return fmt.Errorf("Please specify a Cron ID from the output of 'dokku cron:list %s'", appName)
 	}
 
-	command := ""
-	concurrencyPolicy := "allow"
-	for _, task := range tasks {
-		if task.ID == cronID {
-			command = task.Command
-			concurrencyPolicy = task.ConcurrencyPolicy
+	var task CronTask
+	for _, t := range tasks {
+		if t.ID == cronID || (t.Name != "" && t.Name == cronID) {
+			task = t
 		}
 	}
 
-	if command == "" {
+	if task.Command == "" {
 		return fmt.Errorf("No matching Cron ID found. Please specify a Cron ID from the output of 'dokku cron:list %s'", appName)
 	}
 
-	fields, err := shell.Fields(command, func(name string) string {
-		return ""
-	})
-	if err != nil {
-		return fmt.Errorf("Could not parse command: %s", err)
+	if ifDue {
+		schedule, err := task.ParsedSchedule()
+		if err != nil {
+			return err
+		}
+
+		// the system crontab invokes tasks with a timezone on every candidate minute
+		if !IsDue(schedule, time.Now()) {
+			return nil
+		}
 	}
 
 	if detached {
@@ -136,22 +245,90 @@ func CommandRun(appName string, cronID string, detached bool) error {
 		os.Setenv("DOKKU_DISABLE_TTY", "true")
 	}
 
-	os.Setenv("DOKKU_CONCURRENCY_POLICY", concurrencyPolicy)
-	os.Setenv("DOKKU_CRON_ID", cronID)
+	source := "manual"
+	if scheduled {
+		source = "scheduled"
+	}
+
+	steps := PipelineSteps(task, tasks)
+	if detached && len(steps) > 1 {
+		common.LogWarn(fmt.Sprintf("Not running the %d cron tasks that run after %s as the run is detached", len(steps)-1, task.ID))
+		steps = steps[:1]
+	}
+
+	run := CronRun{Source: source}
+	if len(steps) > 1 {
+		run.PipelineID = task.ID
+		run.PipelineRunID = strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
+
+	for i, step := range steps {
+		if i > 0 {
+			common.LogInfo1(fmt.Sprintf("Running cron task %s after %s", step.ID, step.After))
+		}
+
+		if err := runTask(step, detached, run); err != nil {
+			if remaining := len(steps) - i - 1; remaining > 0 {
+				common.LogWarn(fmt.Sprintf("Cron task %s failed, skipping the %d cron tasks that run after it", step.ID, remaining))
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runTask runs a single cron task via the app's scheduler, recording the run unless it is detached
+func runTask(task CronTask, detached bool, run CronRun) error {
+	fields, err := shell.Fields(task.Command, func(name string) string {
+		return ""
+	})
+	if err != nil {
+		return fmt.Errorf("Could not parse command: %s", err)
+	}
+
+	os.Setenv("DOKKU_CONCURRENCY_POLICY", task.ConcurrencyPolicy)
+	os.Setenv("DOKKU_CRON_ID", task.ID)
 	os.Setenv("DOKKU_RM_CONTAINER", "1")
-	scheduler := common.GetAppScheduler(appName)
-	args := append([]string{scheduler, appName, "0", "--"}, fields...)
-	_, err = common.CallPlugnTrigger(common.PlugnTriggerInput{
+	scheduler := common.GetAppScheduler(task.App)
+	args := append([]string{scheduler, task.App, "0", "--"}, fields...)
+	startedAt := time.Now().UTC()
+	result, err := common.CallPlugnTrigger(common.PlugnTriggerInput{
 		Trigger:     "scheduler-run",
 		Args:        args,
 		StreamStdio: true,
 	})
+
+	// detached runs finish in the background, so there is no result to record
+	if !detached {
+		exitCode := result.ExitCode
+		if err != nil && exitCode == 0 {
+			exitCode = 1
+		}
+
+		output, truncated := truncateOutput(result.Stdout + result.Stderr)
+		run.CronID = task.ID
+		run.App = task.App
+		run.Command = task.Command
+		run.StartedAt = startedAt
+		run.FinishedAt = time.Now().UTC()
+		run.ExitCode = exitCode
+		run.Output = output
+		run.OutputTruncated = truncated
+		if recordErr := recordRun(run); recordErr != nil {
+			common.LogWarn(fmt.Sprintf("Unable to record cron task run: %s", recordErr.Error()))
+		}
+		if exitCode != 0 {
+			notifyFailure(run)
+		}
+	}
+
 	if err != nil {
 		// return an error with an empty message to avoid
 		// printing the error message twice
 		return errors.New("")
 	}
-	return err
+	return nil
 }
 
 // CommandSet set or clear a cron property for an app
@@ -191,6 +368,12 @@ func CommandSet(appName string, property string, value string) error {
 	}
 
 	common.CommandPropertySet("cron", appName, property, value, validProperties, globalProperties)
+	if property == "runner" {
+		if err := setDaemonServiceEnabled(GetRunner() == "daemon"); err != nil {
+			return err
+		}
+	}
+
 	scheduler := common.GetAppScheduler(appName)
 	_, err := common.CallPlugnTrigger(common.PlugnTriggerInput{
 		Trigger:     "scheduler-cron-write",
@@ -11,9 +11,13 @@ import (
 // TriggerCronGetProperty writes the cron key to stdout for a given app container
 func TriggerCronGetProperty(appName string, key string) error {
 	validProperties := map[string]bool{
-		"mailfrom":    true,
-		"mailto":      true,
-		"maintenance": true,
+		"history-limit":      true,
+		"mailfrom":           true,
+		"mailto":             true,
+		"maintenance":        true,
+		"notify-webhook-url": true,
+		"runner":             true,
+		"timezone":           true,
 	}
 	if !validProperties[key] {
 		return errors.New("Invalid cron property specified")
@@ -53,6 +57,10 @@ func TriggerInstall() error {
 		return fmt.Errorf("Unable to install the cron plugin: %s", err.Error())
 	}
 
+	if err := common.SetupAppData("cron"); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -63,7 +71,8 @@ func TriggerPostAppCloneSetup(oldAppName string, newAppName string) error {
 		return err
 	}
 
-	return nil
+	// run history is not carried over to the cloned app
+	return common.CreateAppDataDirectory("cron", newAppName)
 }
 
 // TriggerPostAppRenameSetup renames cron files
@@ -76,7 +85,12 @@ func TriggerPostAppRenameSetup(oldAppName string, newAppName string) error {
 		return err
 	}
 
-	return nil
+	return common.MigrateAppDataDirectory("cron", oldAppName, newAppName)
+}
+
+// TriggerPostCreate ensures apps have the correct data directory structure
+func TriggerPostCreate(appName string) error {
+	return common.CreateAppDataDirectory("cron", appName)
 }
 
 // TriggerPostDelete destroys the cron property for a given app container
@@ -91,11 +105,14 @@ func TriggerPostDelete(appName string) error {
 		return err
 	}
 
-	if err := common.PropertyDestroy("cron", appName); err != nil {
-		return err
+	dataErr := common.RemoveAppDataDirectory("cron", appName)
+	propertyErr := common.PropertyDestroy("cron", appName)
+
+	if dataErr != nil {
+		return dataErr
 	}
 
-	return nil
+	return propertyErr
 }
 
 // TriggerSchedulerStop stops the scheduler for a given app container
@@ -14,8 +14,9 @@ import (
 )
 
 type vectorConfig struct {
-	Sources map[string]vectorSource `json:"sources"`
-	Sinks   map[string]VectorSink   `json:"sinks"`
+	Sources    map[string]vectorSource    `json:"sources"`
+	Transforms map[string]VectorTransform `json:"transforms,omitempty"`
+	Sinks      map[string]VectorSink      `json:"sinks"`
 }
 
 type vectorSource struct {
@@ -169,44 +170,91 @@ func stopVectorContainer() error {
 func writeVectorConfig() error {
 	apps, _ := common.UnfilteredDokkuApps()
 	data := vectorConfig{
-		Sources: map[string]vectorSource{},
-		Sinks:   map[string]VectorSink{},
+		Sources:    map[string]vectorSource{},
+		Transforms: map[string]VectorTransform{},
+		Sinks:      map[string]VectorSink{},
 	}
 	for _, appName := range apps {
 		value := common.PropertyGet("logs", appName, "vector-sink")
-		if value == "" {
+		store := isStoreEnabled(appName)
+		namedSinks, err := FetchNamedSinks(appName)
+		if err != nil {
+			return err
+		}
+
+		if value == "" && !store && len(namedSinks) == 0 {
 			continue
 		}
 
 		inflectedAppName := strings.ReplaceAll(appName, ".", "-")
-		sink, err := SinkValueToConfig(inflectedAppName, value)
-		if err != nil {
+		sourceKey := fmt.Sprintf("docker-source:%s", inflectedAppName)
+		data.Sources[sourceKey] = vectorSource{
+			Type:          "docker_logs",
+			IncludeLabels: []string{fmt.Sprintf("%s=%s", reportComputedAppLabelAlias(appName), appName)},
+		}
+
+		// sinks receive parsed events for apps with structured logs, while the log store keeps the raw log lines
+		sinkSourceKey := sourceKey
+		if format := getLogFormat(appName); isStructuredLogFormat(format) && (value != "" || len(namedSinks) > 0) {
+			parseKey := fmt.Sprintf("docker-parse:%s", inflectedAppName)
+			data.Transforms[parseKey] = getParseTransform(appName, format, sourceKey)
+			sinkSourceKey = parseKey
+		}
+
+		if err := addNamedSinks(&data, namedSinks, sinkSourceKey, fmt.Sprintf("docker-sink:%s", inflectedAppName)); err != nil {
 			return err
 		}
 
-		data.Sources[fmt.Sprintf("docker-source:%s", inflectedAppName)] = vectorSource{
-			Type:          "docker_logs",
-			IncludeLabels: []string{fmt.Sprintf("%s=%s", reportComputedAppLabelAlias(appName), appName)},
+		if value != "" {
+			sink, err := SinkValueToConfig(inflectedAppName, value)
+			if err != nil {
+				return err
+			}
+
+			sink["inputs"] = []string{sinkSourceKey}
+			data.Sinks[fmt.Sprintf("docker-sink:%s", inflectedAppName)] = sink
 		}
 
-		data.Sinks[fmt.Sprintf("docker-sink:%s", inflectedAppName)] = sink
+		if store {
+			if err := ensureStoreDirectory(appName); err != nil {
+				return err
+			}
+
+			sink, err := getStoreSink(appName, inflectedAppName)
+			if err != nil {
+				return err
+			}
+
+			data.Sinks[fmt.Sprintf("docker-store-sink:%s", inflectedAppName)] = sink
+		}
 	}
 
 	value := common.PropertyGet("logs", "--global", "vector-sink")
-	if value != "" {
-		sink, err := SinkValueToConfig("--global", value)
-		if err != nil {
-			return err
-		}
+	globalSinks, err := FetchNamedSinks("--global")
+	if err != nil {
+		return err
+	}
 
+	if value != "" || len(globalSinks) > 0 {
 		data.Sources["docker-global-source"] = vectorSource{
 			Type:          "docker_logs",
 			IncludeLabels: []string{reportGlobalAppLabelAlias("global")},
 		}
+	}
+
+	if value != "" {
+		sink, err := SinkValueToConfig("--global", value)
+		if err != nil {
+			return err
+		}
 
 		data.Sinks["docker-global-sink"] = sink
 	}
 
+	if err := addNamedSinks(&data, globalSinks, "docker-global-source", "docker-global-sink"); err != nil {
+		return err
+	}
+
 	if len(data.Sources) == 0 {
 		// pull from no containers
 		data.Sources["docker-null-source"] = vectorSource{
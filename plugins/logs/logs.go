@@ -22,11 +22,17 @@ const MaxSize = "10m"
 // AppLabelAlias is the property key for the app label alias
 const AppLabelAlias = "com.dokku.app-name"
 
+// StoreRetention is the default number of days logs are kept in the log store
+const StoreRetention = "7"
+
 var (
 	// DefaultProperties is a map of all valid logs properties with corresponding default property values
 	DefaultProperties = map[string]string{
 		"app-label-alias": AppLabelAlias,
+		"log-format":      "text",
 		"max-size":        MaxSize,
+		"store":           "false",
+		"store-retention": StoreRetention,
 		"vector-sink":     "",
 	}
 
@@ -34,6 +40,8 @@ var (
 	GlobalProperties = map[string]bool{
 		"app-label-alias": true,
 		"max-size":        true,
+		"store":           true,
+		"store-retention": true,
 		"vector-image":    true,
 		"vector-sink":     true,
 	}
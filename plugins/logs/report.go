@@ -17,12 +17,20 @@ func ReportSingleApp(appName string, format string, infoFlag string) error {
 	os.Setenv("DOKKU_REPORT_FLAG", infoFlag)
 	flags := map[string]common.ReportFunc{
 		"--logs-computed-app-label-alias": reportComputedAppLabelAlias,
+		"--logs-computed-log-format":      reportComputedLogFormat,
 		"--logs-computed-max-size":        reportComputedMaxSize,
+		"--logs-computed-store":           reportComputedStore,
+		"--logs-computed-store-retention": reportComputedStoreRetention,
 		"--logs-global-app-label-alias":   reportGlobalAppLabelAlias,
 		"--logs-global-max-size":          reportGlobalMaxSize,
+		"--logs-global-store":             reportGlobalStore,
+		"--logs-global-store-retention":   reportGlobalStoreRetention,
 		"--logs-global-vector-sink":       reportGlobalVectorSink,
 		"--logs-app-label-alias":          reportAppLabelAlias,
+		"--logs-log-format":               reportLogFormat,
 		"--logs-max-size":                 reportMaxSize,
+		"--logs-store":                    reportStore,
+		"--logs-store-retention":          reportStoreRetention,
 		"--logs-vector-global-image":      reportVectorGlobalImage,
 		"--logs-vector-sink":              reportVectorSink,
 	}
@@ -55,6 +63,19 @@ func reportAppLabelAlias(appName string) string {
 	return common.PropertyGet("logs", appName, "app-label-alias")
 }
 
+func reportComputedLogFormat(appName string) string {
+	value := reportLogFormat(appName)
+	if value == "" {
+		value = DefaultProperties["log-format"]
+	}
+
+	return value
+}
+
+func reportLogFormat(appName string) string {
+	return common.PropertyGet("logs", appName, "log-format")
+}
+
 func reportComputedMaxSize(appName string) string {
 	value := reportMaxSize(appName)
 	if value == "" {
@@ -68,6 +89,40 @@ func reportGlobalMaxSize(appName string) string {
 	return common.PropertyGetDefault("logs", "--global", "max-size", MaxSize)
 }
 
+func reportComputedStore(appName string) string {
+	value := reportStore(appName)
+	if value == "" {
+		value = reportGlobalStore(appName)
+	}
+
+	return value
+}
+
+func reportGlobalStore(appName string) string {
+	return common.PropertyGetDefault("logs", "--global", "store", DefaultProperties["store"])
+}
+
+func reportStore(appName string) string {
+	return common.PropertyGet("logs", appName, "store")
+}
+
+func reportComputedStoreRetention(appName string) string {
+	value := reportStoreRetention(appName)
+	if value == "" {
+		value = reportGlobalStoreRetention(appName)
+	}
+
+	return value
+}
+
+func reportGlobalStoreRetention(appName string) string {
+	return common.PropertyGetDefault("logs", "--global", "store-retention", StoreRetention)
+}
+
+func reportStoreRetention(appName string) string {
+	return common.PropertyGet("logs", appName, "store-retention")
+}
+
 func reportVectorGlobalImage(appName string) string {
 	return getComputedVectorImage()
 }
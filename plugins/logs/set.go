@@ -7,10 +7,22 @@ import (
 )
 
 func validateSetValue(appName string, key string, value string) error {
+	if key == "log-format" {
+		return validateLogFormat(appName, value)
+	}
+
 	if key == "max-size" {
 		return validateMaxSize(appName, value)
 	}
 
+	if key == "store" {
+		return validateStore(appName, value)
+	}
+
+	if key == "store-retention" {
+		return validateStoreRetention(appName, value)
+	}
+
 	if key == "vector-sink" {
 		return validateVectorSink(appName, value)
 	}
@@ -18,6 +30,14 @@ func validateSetValue(appName string, key string, value string) error {
 	return nil
 }
 
+func validateLogFormat(appName string, value string) error {
+	if value == "" || LogFormats[value] {
+		return nil
+	}
+
+	return errors.New("Invalid log-format value, must be one of [json, logfmt, text]")
+}
+
 func validateMaxSize(appName string, value string) error {
 	if value == "" {
 		return nil
@@ -44,6 +64,27 @@ func validateMaxSize(appName string, value string) error {
 	return nil
 }
 
+func validateStore(appName string, value string) error {
+	if value == "" || value == "true" || value == "false" {
+		return nil
+	}
+
+	return errors.New("Invalid store value, must be either true or false")
+}
+
+func validateStoreRetention(appName string, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 {
+		return errors.New("Invalid store-retention value, must be a positive number of days")
+	}
+
+	return nil
+}
+
 func validateVectorSink(appName string, value string) error {
 	if value == "" {
 		return nil
@@ -0,0 +1,210 @@
+package logs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dokku/dokku/plugins/common"
+)
+
+// VectorTransform is a map of vector transform properties
+type VectorTransform map[string]interface{}
+
+// sinkNamePattern matches valid sink names
+var sinkNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// NamedSink is a vector sink configured via logs:sink:add, along with the transforms applied before it
+type NamedSink struct {
+	// Name is the name of the sink
+	Name string `json:"name"`
+
+	// DSN is the sink in DSN form, as accepted by the vector-sink property
+	DSN string `json:"dsn"`
+
+	// ProcessType only sends logs from this process type to the sink
+	ProcessType string `json:"process_type,omitempty"`
+
+	// Level only sends log lines matching this regular expression to the sink
+	Level string `json:"level,omitempty"`
+
+	// SampleRate only sends one out of every SampleRate log lines to the sink
+	SampleRate int `json:"sample_rate,omitempty"`
+
+	// Redact replaces every match of these regular expressions in a log line before it is sent to the sink
+	Redact []string `json:"redact,omitempty"`
+}
+
+func getSinkProperty(sinkName string) string {
+	return fmt.Sprintf("sink-%s.json", sinkName)
+}
+
+func validateSinkName(sinkName string) error {
+	if sinkName == "" {
+		return errors.New("Missing sink name")
+	}
+
+	if len(sinkName) > 32 || !sinkNamePattern.MatchString(sinkName) {
+		return fmt.Errorf("Invalid sink name, must be at most 32 lowercase alphanumeric characters and dashes and cannot start or end with a dash: %s", sinkName)
+	}
+
+	return nil
+}
+
+// validateSinkPattern ensures a pattern can be embedded in a vrl raw string
+func validateSinkPattern(flag string, pattern string) error {
+	if strings.Contains(pattern, "'") {
+		return fmt.Errorf("Invalid %s pattern, single quotes are not supported: %s", flag, pattern)
+	}
+
+	if _, err := regexp.Compile(pattern); err != nil {
+		return fmt.Errorf("Invalid %s pattern: %w", flag, err)
+	}
+
+	return nil
+}
+
+// Validate ensures the sink can be rendered into a valid vector config
+func (s NamedSink) Validate(appName string) error {
+	if err := validateSinkName(s.Name); err != nil {
+		return err
+	}
+
+	if s.DSN == "" {
+		return errors.New("Missing sink DSN")
+	}
+
+	if _, err := SinkValueToConfig(appName, s.DSN); err != nil {
+		return fmt.Errorf("Invalid sink DSN: %w", err)
+	}
+
+	if s.ProcessType != "" && !regexp.MustCompile(`^[a-zA-Z0-9_-]+$`).MatchString(s.ProcessType) {
+		return fmt.Errorf("Invalid process type: %s", s.ProcessType)
+	}
+
+	if s.Level != "" {
+		if err := validateSinkPattern("--level", s.Level); err != nil {
+			return err
+		}
+	}
+
+	if s.SampleRate <= 0 {
+		return errors.New("Invalid sample rate, must be a positive number")
+	}
+
+	for _, pattern := range s.Redact {
+		if err := validateSinkPattern("--redact", pattern); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renderVectorConfig returns the transforms and sink for the named sink, reading from the specified source.
+// Each transform is keyed by the sink key with a suffix, and the last transform feeds the sink.
+func (s NamedSink) renderVectorConfig(sourceKey string, sinkKey string) (map[string]VectorTransform, VectorSink, error) {
+	transforms := map[string]VectorTransform{}
+	sink, err := SinkValueToConfig("--null", s.DSN)
+	if err != nil {
+		return transforms, sink, err
+	}
+
+	input := sourceKey
+	conditions := []string{}
+	if s.ProcessType != "" {
+		conditions = append(conditions, fmt.Sprintf(`.label."com.dokku.process-type" == "%s"`, s.ProcessType))
+	}
+	if s.Level != "" {
+		conditions = append(conditions, fmt.Sprintf(`match(string(.message) ?? "", r'%s')`, s.Level))
+	}
+	if len(conditions) > 0 {
+		key := sinkKey + ":filter"
+		transforms[key] = VectorTransform{
+			"type":   "filter",
+			"inputs": []string{input},
+			"condition": map[string]string{
+				"type":   "vrl",
+				"source": strings.Join(conditions, " && "),
+			},
+		}
+		input = key
+	}
+
+	if s.SampleRate > 1 {
+		key := sinkKey + ":sample"
+		transforms[key] = VectorTransform{
+			"type":   "sample",
+			"inputs": []string{input},
+			"rate":   s.SampleRate,
+		}
+		input = key
+	}
+
+	if len(s.Redact) > 0 {
+		lines := []string{}
+		for _, pattern := range s.Redact {
+			lines = append(lines, fmt.Sprintf(`.message = replace(string(.message) ?? "", r'%s', "[REDACTED]")`, pattern))
+		}
+
+		key := sinkKey + ":redact"
+		transforms[key] = VectorTransform{
+			"type":   "remap",
+			"inputs": []string{input},
+			"source": strings.Join(lines, "\n"),
+		}
+		input = key
+	}
+
+	sink["inputs"] = []string{input}
+	return transforms, sink, nil
+}
+
+// FetchNamedSinks returns the sinks configured via logs:sink:add for an app, or globally via --global
+func FetchNamedSinks(appName string) ([]NamedSink, error) {
+	sinks := []NamedSink{}
+	properties, err := common.PropertyGetAllByPrefix("logs", appName, "sink-")
+	if err != nil {
+		return sinks, fmt.Errorf("Unable to get sinks: %w", err)
+	}
+
+	for property, data := range properties {
+		if !strings.HasSuffix(property, ".json") {
+			continue
+		}
+
+		var sink NamedSink
+		if err := json.Unmarshal([]byte(data), &sink); err != nil {
+			return sinks, fmt.Errorf("Unable to unmarshal sink %s: %w", property, err)
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	sort.SliceStable(sinks, func(i, j int) bool {
+		return sinks[i].Name < sinks[j].Name
+	})
+
+	return sinks, nil
+}
+
+// addNamedSinks renders named sinks reading from the specified source into the vector config
+func addNamedSinks(data *vectorConfig, sinks []NamedSink, sourceKey string, sinkKeyPrefix string) error {
+	for _, namedSink := range sinks {
+		sinkKey := fmt.Sprintf("%s:%s", sinkKeyPrefix, namedSink.Name)
+		transforms, sink, err := namedSink.renderVectorConfig(sourceKey, sinkKey)
+		if err != nil {
+			return fmt.Errorf("Invalid sink %s: %w", namedSink.Name, err)
+		}
+
+		for key, transform := range transforms {
+			data.Transforms[key] = transform
+		}
+		data.Sinks[sinkKey] = sink
+	}
+
+	return nil
+}
@@ -22,10 +22,16 @@ Manage log integration for an app
 Additional commands:`
 
 	helpContent = `
-    logs [-h|--help] [-t|--tail] [-n|--num num] [-q|--quiet] [-p|--ps process] <app>, Display recent log output
+    logs [-h|--help] [-t|--tail] [-n|--num num] [-q|--quiet] [-p|--ps process] [--where key=value] <app>, Display recent log output
+    logs:export <app> [--since time] [--until time] [--grep pattern] [--process process], Export persisted logs as newline delimited json
     logs:failed [--all|<app>], Shows the last failed deploy logs
     logs:report [<app>] [<flag>], Displays a logs report for one or more apps
+    logs:rotate [--all|<app>], Compress and prune persisted logs
+    logs:search <app> [--since time] [--until time] [--grep pattern] [--process process] [-q|--quiet], Search persisted logs
     logs:set [--global|<app>] <key> <value>, Set or clear a logs property for an app
+    logs:sink:add [--global|<app>] <name> <dsn> [--process process] [--level pattern] [--sample-rate n] [--redact pattern], Add a named vector sink
+    logs:sink:list [--global|<app>] [--format json|stdout], List the named vector sinks
+    logs:sink:remove [--global|<app>] <name>, Remove a named vector sink
     logs:vector-logs [--num num] [--tail], Display vector log output
     logs:vector-start, Start the vector logging container
     logs:vector-stop, Stop the vector logging container`
@@ -44,6 +50,7 @@ func main() {
 		ps := args.StringP("ps", "p", "", "only display logs from the given process")
 		tail := args.BoolP("tail", "t", false, "continually stream logs")
 		quiet := args.BoolP("quiet", "q", false, "display raw logs without colors, time and names")
+		where := args.StringArray("where", []string{}, "only display structured logs where the field equals the value")
 		args.Parse(os.Args[2:])
 		if *help {
 			usage()
@@ -51,7 +58,7 @@ func main() {
 		}
 
 		appName := args.Arg(0)
-		err := logs.CommandDefault(appName, *num, *ps, *tail, *quiet)
+		err := logs.CommandDefault(appName, *num, *ps, *tail, *quiet, *where)
 		if err != nil {
 			common.LogFailWithError(err)
 		}
@@ -18,6 +18,15 @@ func main() {
 
 	var err error
 	switch subcommand {
+	case "export":
+		args := flag.NewFlagSet("logs:export", flag.ExitOnError)
+		since := args.String("since", "", "--since: only export logs emitted at or after this time")
+		until := args.String("until", "", "--until: only export logs emitted before this time")
+		grep := args.String("grep", "", "--grep: only export logs matching this regular expression")
+		process := args.String("process", "", "--process: only export logs from the given process type")
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		err = logs.CommandExport(appName, *since, *until, *grep, *process)
 	case "failed":
 		args := flag.NewFlagSet("logs:failed", flag.ExitOnError)
 		allApps := args.Bool("all", false, "--all: restore all apps")
@@ -33,6 +42,22 @@ func main() {
 			appName := args.Arg(0)
 			err = logs.CommandReport(appName, *format, infoFlag)
 		}
+	case "rotate":
+		args := flag.NewFlagSet("logs:rotate", flag.ExitOnError)
+		allApps := args.Bool("all", false, "--all: rotate persisted logs for all apps")
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		err = logs.CommandRotate(appName, *allApps)
+	case "search":
+		args := flag.NewFlagSet("logs:search", flag.ExitOnError)
+		since := args.String("since", "", "--since: only display logs emitted at or after this time")
+		until := args.String("until", "", "--until: only display logs emitted before this time")
+		grep := args.String("grep", "", "--grep: only display logs matching this regular expression")
+		process := args.String("process", "", "--process: only display logs from the given process type")
+		quiet := args.BoolP("quiet", "q", false, "--quiet: display raw logs without time and names")
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		err = logs.CommandSearch(appName, *since, *until, *grep, *process, *quiet)
 	case "set":
 		args := flag.NewFlagSet("logs:set", flag.ExitOnError)
 		global := args.Bool("global", false, "--global: set a global property")
@@ -46,6 +71,44 @@ func main() {
 			value = args.Arg(1)
 		}
 		err = logs.CommandSet(appName, property, value)
+	case "sink:add":
+		args := flag.NewFlagSet("logs:sink:add", flag.ExitOnError)
+		global := args.Bool("global", false, "--global: add a global sink")
+		process := args.String("process", "", "--process: only send logs from the given process type")
+		level := args.String("level", "", "--level: only send log lines matching this regular expression")
+		sampleRate := args.Int("sample-rate", 1, "--sample-rate: only send one out of every n log lines")
+		redact := args.StringArray("redact", []string{}, "--redact: repeatable regular expression to redact from log lines")
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		sinkName := args.Arg(1)
+		dsn := args.Arg(2)
+		if *global {
+			appName = "--global"
+			sinkName = args.Arg(0)
+			dsn = args.Arg(1)
+		}
+		err = logs.CommandSinkAdd(appName, sinkName, dsn, *process, *level, *sampleRate, *redact)
+	case "sink:list":
+		args := flag.NewFlagSet("logs:sink:list", flag.ExitOnError)
+		global := args.Bool("global", false, "--global: list global sinks")
+		format := args.String("format", "stdout", "format: [ stdout | json ]")
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		if *global {
+			appName = "--global"
+		}
+		err = logs.CommandSinkList(appName, *format)
+	case "sink:remove":
+		args := flag.NewFlagSet("logs:sink:remove", flag.ExitOnError)
+		global := args.Bool("global", false, "--global: remove a global sink")
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		sinkName := args.Arg(1)
+		if *global {
+			appName = "--global"
+			sinkName = args.Arg(0)
+		}
+		err = logs.CommandSinkRemove(appName, sinkName)
 	case "vector-logs":
 		args := flag.NewFlagSet("logs:vector-logs", flag.ExitOnError)
 		num := args.Int("num", 100, "the number of lines to display")
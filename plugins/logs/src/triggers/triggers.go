@@ -18,6 +18,9 @@ func main() {
 
 	var err error
 	switch trigger {
+	case "cron-entries":
+		scheduler := flag.Arg(0)
+		err = logs.TriggerCronEntries(scheduler)
 	case "docker-args-process-deploy":
 		appName := flag.Arg(0)
 		err = logs.TriggerDockerArgsProcessDeploy(appName)
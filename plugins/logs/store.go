@@ -0,0 +1,414 @@
+package logs
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dokku/dokku/plugins/common"
+)
+
+// storeFileDateFormat is the date format used in the name of each log store file
+const storeFileDateFormat = "2006-01-02"
+
+// storeFileIdleTime is how long a log store file must go unmodified before it is compressed
+const storeFileIdleTime = 10 * time.Minute
+
+// LogRecord is a single log line written to the log store by vector
+type LogRecord struct {
+	// Timestamp is the time the log line was emitted
+	Timestamp time.Time `json:"timestamp"`
+
+	// Message is the log line
+	Message string `json:"message"`
+
+	// Stream is the stream the log line was written to, either stdout or stderr
+	Stream string `json:"stream"`
+
+	// ContainerName is the name of the container that emitted the log line
+	ContainerName string `json:"container_name"`
+
+	// Label contains the labels of the container that emitted the log line
+	Label map[string]string `json:"label"`
+}
+
+// ProcessType returns the process type of the container that emitted the log line
+func (r LogRecord) ProcessType() string {
+	return r.Label["com.dokku.process-type"]
+}
+
+// Dyno returns the dyno of the container that emitted the log line, falling back to the process type
+func (r LogRecord) Dyno() string {
+	if dyno := r.Label["com.dokku.dyno"]; dyno != "" {
+		return dyno
+	}
+
+	return r.ProcessType()
+}
+
+// LogExportRecord is a single log line as written by logs:export
+type LogExportRecord struct {
+	// Timestamp is the time the log line was emitted
+	Timestamp time.Time `json:"timestamp"`
+
+	// App is the app that emitted the log line
+	App string `json:"app"`
+
+	// ProcessType is the process type of the container that emitted the log line
+	ProcessType string `json:"process_type"`
+
+	// Dyno is the dyno of the container that emitted the log line
+	Dyno string `json:"dyno"`
+
+	// Stream is the stream the log line was written to, either stdout or stderr
+	Stream string `json:"stream"`
+
+	// Message is the log line
+	Message string `json:"message"`
+}
+
+// LogStoreQuery filters the log lines read from the log store
+type LogStoreQuery struct {
+	// Since only matches log lines emitted at or after this time
+	Since time.Time
+
+	// Until only matches log lines emitted before this time
+	Until time.Time
+
+	// Grep only matches log lines whose message matches this pattern
+	Grep *regexp.Regexp
+
+	// ProcessType only matches log lines emitted by this process type
+	ProcessType string
+}
+
+// Matches returns whether a log line matches the query
+func (q LogStoreQuery) Matches(record LogRecord) bool {
+	if !q.Since.IsZero() && record.Timestamp.Before(q.Since) {
+		return false
+	}
+
+	if !q.Until.IsZero() && !record.Timestamp.Before(q.Until) {
+		return false
+	}
+
+	if q.ProcessType != "" && record.ProcessType() != q.ProcessType {
+		return false
+	}
+
+	if q.Grep != nil && !q.Grep.MatchString(record.Message) {
+		return false
+	}
+
+	return true
+}
+
+// NewLogStoreQuery creates a query from the flag values passed to logs:search and logs:export
+func NewLogStoreQuery(since string, until string, grep string, processType string) (LogStoreQuery, error) {
+	var query LogStoreQuery
+	var err error
+	now := time.Now()
+	if query.Since, err = parseStoreTime(since, now); err != nil {
+		return query, fmt.Errorf("Invalid --since value: %w", err)
+	}
+
+	if query.Until, err = parseStoreTime(until, now); err != nil {
+		return query, fmt.Errorf("Invalid --until value: %w", err)
+	}
+
+	if grep != "" {
+		if query.Grep, err = regexp.Compile(grep); err != nil {
+			return query, fmt.Errorf("Invalid --grep pattern: %w", err)
+		}
+	}
+
+	query.ProcessType = processType
+	return query, nil
+}
+
+// parseStoreTime parses a timestamp, a date or a duration relative to now such as 30m, 12h or 7d
+func parseStoreTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation(storeFileDateFormat, value, time.UTC); err == nil {
+		return t, nil
+	}
+
+	if days, found := strings.CutSuffix(value, "d"); found {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return time.Time{}, fmt.Errorf("must be an RFC3339 timestamp, a date in YYYY-MM-DD format, or a duration such as 30m, 12h or 7d: %s", value)
+	}
+
+	return now.Add(-duration), nil
+}
+
+// getStoreDirectory returns the host directory vector writes an app's logs to
+func getStoreDirectory(appName string) string {
+	return filepath.Join(common.MustGetEnv("DOKKU_LOGS_DIR"), "apps", appName, "store")
+}
+
+// getStoreSink returns the vector file sink that writes an app's logs to the log store, with one file per day
+func getStoreSink(appName string, inflectedAppName string) (VectorSink, error) {
+	path := fmt.Sprintf("/var/log/dokku/apps/%s/store/%%Y-%%m-%%d.log", appName)
+	return SinkValueToConfig(inflectedAppName, fmt.Sprintf("file://?encoding[codec]=json&path=%s", url.QueryEscape(path)))
+}
+
+// ensureStoreDirectory creates the log store directory for an app so that it is owned by the dokku user
+func ensureStoreDirectory(appName string) error {
+	directory := getStoreDirectory(appName)
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return err
+	}
+
+	return common.SetPermissions(common.SetPermissionInput{
+		Filename: directory,
+		Mode:     os.FileMode(0755),
+	})
+}
+
+// isStoreEnabled returns whether logs for an app are persisted to the log store
+func isStoreEnabled(appName string) bool {
+	return reportComputedStore(appName) == "true"
+}
+
+// getStoreRetention returns the number of days logs are kept in the log store for an app
+func getStoreRetention(appName string) int {
+	days, err := strconv.Atoi(reportComputedStoreRetention(appName))
+	if err != nil || days < 1 {
+		days, _ = strconv.Atoi(StoreRetention)
+	}
+
+	return days
+}
+
+// storeFile is a single file in the log store
+type storeFile struct {
+	path       string
+	date       time.Time
+	compressed bool
+}
+
+// listStoreFiles returns the files in an app's log store, oldest first
+func listStoreFiles(appName string) ([]storeFile, error) {
+	files := []storeFile{}
+	entries, err := os.ReadDir(getStoreDirectory(appName))
+	if errors.Is(err, os.ErrNotExist) {
+		return files, nil
+	}
+	if err != nil {
+		return files, err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		compressed := strings.HasSuffix(name, ".log.gz")
+		if !compressed && !strings.HasSuffix(name, ".log") {
+			continue
+		}
+
+		date, err := time.ParseInLocation(storeFileDateFormat, strings.SplitN(name, ".", 2)[0], time.UTC)
+		if err != nil {
+			continue
+		}
+
+		files = append(files, storeFile{
+			path:       filepath.Join(getStoreDirectory(appName), name),
+			date:       date,
+			compressed: compressed,
+		})
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].date.Before(files[j].date)
+	})
+
+	return files, nil
+}
+
+// ReadStore calls fn for each log line in an app's log store that matches the query, oldest first
+func ReadStore(appName string, query LogStoreQuery, fn func(record LogRecord) error) error {
+	files, err := listStoreFiles(appName)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		// each file holds a single day of logs
+		if !query.Since.IsZero() && file.date.AddDate(0, 0, 1).Before(query.Since) {
+			continue
+		}
+		if !query.Until.IsZero() && !file.date.Before(query.Until) {
+			continue
+		}
+
+		if err := readStoreFile(file, query, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readStoreFile(file storeFile, query LogStoreQuery, fn func(record LogRecord) error) error {
+	f, err := os.Open(file.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var reader io.Reader = f
+	if file.compressed {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("Unable to read %s: %w", file.path, err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record LogRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+
+		if !query.Matches(record) {
+			continue
+		}
+
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// RotateStore compresses the log store files for previous days and removes files older than the retention period
+func RotateStore(appName string) error {
+	files, err := listStoreFiles(appName)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	today := now.Truncate(24 * time.Hour)
+	cutoff := today.AddDate(0, 0, -getStoreRetention(appName))
+	for _, file := range files {
+		if file.date.Before(cutoff) {
+			common.LogVerboseQuiet(fmt.Sprintf("Removing %s", filepath.Base(file.path)))
+			if err := os.Remove(file.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			continue
+		}
+
+		if file.compressed || !file.date.Before(today) {
+			continue
+		}
+
+		// vector may still flush the last lines of a day shortly after midnight
+		info, err := os.Stat(file.path)
+		if err != nil {
+			return err
+		}
+		if now.Sub(info.ModTime()) < storeFileIdleTime {
+			continue
+		}
+
+		common.LogVerboseQuiet(fmt.Sprintf("Compressing %s", filepath.Base(file.path)))
+		if err := compressStoreFile(file.path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// compressStoreFile gzips a log store file, removing the uncompressed file once done
+func compressStoreFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmpPath := path + ".gz.tmp"
+	out, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err := gz.Close(); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path+".gz"); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// rotateStoreForApp rotates the log store for an app if it has any persisted logs
+func rotateStoreForApp(appName string) error {
+	if _, err := os.Stat(getStoreDirectory(appName)); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	common.LogInfo1Quiet(fmt.Sprintf("Rotating persisted logs for %s", appName))
+	return RotateStore(appName)
+}
+
+// isStoreEnabledForAnyApp returns whether any app persists logs to the log store
+func isStoreEnabledForAnyApp() bool {
+	if reportGlobalStore("--global") == "true" {
+		return true
+	}
+
+	apps, _ := common.UnfilteredDokkuApps()
+	for _, appName := range apps {
+		if isStoreEnabled(appName) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,283 @@
+package logs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dokku/dokku/plugins/common"
+)
+
+// LogFormats is a map of all valid values for the log-format property
+var LogFormats = map[string]bool{
+	"json":   true,
+	"logfmt": true,
+	"text":   true,
+}
+
+// logLinePrefixPattern matches the timestamp and dyno prefix added to each line by the scheduler-logs trigger
+var logLinePrefixPattern = regexp.MustCompile(`^(.*?app\[[^\]]*\]:\s?(?:\x1b\[0m)?\s?)(.*)$`)
+
+// priorityFields are displayed before all other fields when pretty-printing a structured log line
+var priorityFields = []string{"time", "timestamp", "level", "msg", "message"}
+
+// LogWhereFilter only matches structured log lines where a field is equal to a value
+type LogWhereFilter struct {
+	// Key is the name of the field
+	Key string
+
+	// Value is the value the field must equal
+	Value string
+}
+
+// ParseLogWhereFilters parses the key=value pairs passed via --where
+func ParseLogWhereFilters(where []string) ([]LogWhereFilter, error) {
+	filters := []LogWhereFilter{}
+	for _, w := range where {
+		key, value, found := strings.Cut(w, "=")
+		if !found || key == "" {
+			return filters, fmt.Errorf("Invalid --where value, must be in key=value format: %s", w)
+		}
+
+		filters = append(filters, LogWhereFilter{Key: key, Value: value})
+	}
+
+	return filters, nil
+}
+
+// getLogFormat returns the format an app writes log lines in
+func getLogFormat(appName string) string {
+	return reportComputedLogFormat(appName)
+}
+
+// isStructuredLogFormat returns whether log lines in the format can be parsed into fields
+func isStructuredLogFormat(format string) bool {
+	return format == "json" || format == "logfmt"
+}
+
+// parseStructuredMessage parses a log line into fields, returning false if the line is not in the specified format
+func parseStructuredMessage(format string, message string) (map[string]string, bool) {
+	switch format {
+	case "json":
+		return parseJSONMessage(message)
+	case "logfmt":
+		return parseLogfmtMessage(message)
+	}
+
+	return nil, false
+}
+
+func parseJSONMessage(message string) (map[string]string, bool) {
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(message)), &data); err != nil {
+		return nil, false
+	}
+
+	fields := map[string]string{}
+	for key, value := range data {
+		switch v := value.(type) {
+		case string:
+			fields[key] = v
+		case nil:
+			fields[key] = ""
+		case map[string]interface{}, []interface{}:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, false
+			}
+			fields[key] = string(b)
+		default:
+			fields[key] = fmt.Sprint(v)
+		}
+	}
+
+	return fields, true
+}
+
+func parseLogfmtMessage(message string) (map[string]string, bool) {
+	fields := map[string]string{}
+	pairs := 0
+	message = strings.TrimSpace(message)
+	for len(message) > 0 {
+		end := strings.IndexAny(message, "= ")
+		if end == 0 {
+			return nil, false
+		}
+		if end == -1 || message[end] == ' ' {
+			// a key without a value is treated as a boolean flag
+			if end == -1 {
+				end = len(message)
+			}
+			fields[message[:end]] = "true"
+			message = strings.TrimLeft(message[end:], " ")
+			continue
+		}
+
+		key := message[:end]
+		message = message[end+1:]
+		value := ""
+		if strings.HasPrefix(message, `"`) {
+			closing := -1
+			for i := 1; i < len(message); i++ {
+				if message[i] == '\\' {
+					i++
+					continue
+				}
+				if message[i] == '"' {
+					closing = i
+					break
+				}
+			}
+			if closing == -1 {
+				return nil, false
+			}
+
+			var unquoted string
+			if err := json.Unmarshal([]byte(message[:closing+1]), &unquoted); err != nil {
+				return nil, false
+			}
+			value = unquoted
+			message = message[closing+1:]
+		} else {
+			valueEnd := strings.IndexByte(message, ' ')
+			if valueEnd == -1 {
+				valueEnd = len(message)
+			}
+			value = message[:valueEnd]
+			message = message[valueEnd:]
+		}
+
+		if message != "" && message[0] != ' ' {
+			return nil, false
+		}
+
+		fields[key] = value
+		pairs++
+		message = strings.TrimLeft(message, " ")
+	}
+
+	// plain text would otherwise parse as a series of boolean flags
+	return fields, pairs > 0
+}
+
+// formatStructuredFields renders fields as key=value pairs, with common fields such as level and msg first
+func formatStructuredFields(fields map[string]string) string {
+	keys := []string{}
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	priority := map[string]int{}
+	for i, key := range priorityFields {
+		priority[key] = i - len(priorityFields)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return priority[keys[i]] < priority[keys[j]]
+	})
+
+	pairs := []string{}
+	for _, key := range keys {
+		value := fields[key]
+		if value == "" || strings.ContainsAny(value, " =\"\t") {
+			b, _ := json.Marshal(value)
+			value = string(b)
+		}
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+// matchesWhereFilters returns whether the fields of a structured log line match every filter
+func matchesWhereFilters(fields map[string]string, filters []LogWhereFilter) bool {
+	for _, filter := range filters {
+		value, ok := fields[filter.Key]
+		if !ok || value != filter.Value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// writeStructuredLogs reads log lines from the scheduler-logs trigger, pretty-printing and filtering structured lines
+func writeStructuredLogs(reader io.Reader, writer io.Writer, format string, filters []LogWhereFilter, quiet bool) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		prefix := ""
+		message := line
+		// quiet output does not include a prefix
+		if matches := logLinePrefixPattern.FindStringSubmatch(line); !quiet && matches != nil {
+			prefix = matches[1]
+			message = matches[2]
+		}
+
+		fields, ok := parseStructuredMessage(format, message)
+		if !ok {
+			// lines that are not structured cannot match a filter
+			if len(filters) == 0 {
+				fmt.Fprintln(writer, line)
+			}
+			continue
+		}
+
+		if !matchesWhereFilters(fields, filters) {
+			continue
+		}
+
+		fmt.Fprintf(writer, "%s%s\n", prefix, formatStructuredFields(fields))
+	}
+
+	return scanner.Err()
+}
+
+// streamStructuredLogs calls the scheduler-logs trigger, processing each log line as it is emitted
+func streamStructuredLogs(writer io.Writer, format string, filters []LogWhereFilter, quiet bool, args []string) error {
+	reader, pipeWriter := io.Pipe()
+	errs := make(chan error, 1)
+	go func() {
+		_, err := common.CallPlugnTrigger(common.PlugnTriggerInput{
+			Args:               args,
+			DisableStdioBuffer: true,
+			StdoutWriter:       pipeWriter,
+			StreamStderr:       true,
+			Trigger:            "scheduler-logs",
+		})
+		pipeWriter.Close()
+		errs <- err
+	}()
+
+	if err := writeStructuredLogs(reader, writer, format, filters, quiet); err != nil {
+		reader.CloseWithError(err)
+		<-errs
+		return err
+	}
+
+	return <-errs
+}
+
+// getParseTransform returns the vector transform that parses an app's log lines into structured events
+// and merges the app and process labels into each event
+func getParseTransform(appName string, format string, sourceKey string) VectorTransform {
+	lines := []string{
+		fmt.Sprintf(`structured, err = parse_%s(string(.message) ?? "")`, format),
+		`if err == null && is_object(structured) {`,
+		`  . = merge(., object!(structured))`,
+		`}`,
+		fmt.Sprintf(`.app = .label."%s"`, reportComputedAppLabelAlias(appName)),
+		`.process_type = .label."com.dokku.process-type"`,
+	}
+
+	return VectorTransform{
+		"type":   "remap",
+		"inputs": []string{sourceKey},
+		"source": strings.Join(lines, "\n"),
+	}
+}
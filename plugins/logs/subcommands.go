@@ -1,17 +1,21 @@
 package logs
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dokku/dokku/plugins/common"
+	"github.com/ryanuber/columnize"
 )
 
 // CommandDefault displays recent log output
-func CommandDefault(appName string, num int64, process string, tail, quiet bool) error {
+func CommandDefault(appName string, num int64, process string, tail, quiet bool, where []string) error {
 	if err := common.VerifyAppName(appName); err != nil {
 		return err
 	}
@@ -20,12 +24,26 @@ func CommandDefault(appName string, num int64, process string, tail, quiet bool)
 		return fmt.Errorf("App %s has not been deployed", appName)
 	}
 
+	filters, err := ParseLogWhereFilters(where)
+	if err != nil {
+		return err
+	}
+
+	format := getLogFormat(appName)
+	if len(filters) > 0 && !isStructuredLogFormat(format) {
+		return fmt.Errorf("Filtering with --where requires the log-format property to be set to json or logfmt for %s", appName)
+	}
+
 	s := common.GetAppScheduler(appName)
 	t := strconv.FormatBool(tail)
 	q := strconv.FormatBool(quiet)
 	n := strconv.FormatInt(num, 10)
 
-	_, err := common.CallPlugnTrigger(common.PlugnTriggerInput{
+	if isStructuredLogFormat(format) {
+		return streamStructuredLogs(os.Stdout, format, filters, quiet, []string{s, appName, process, t, q, n})
+	}
+
+	_, err = common.CallPlugnTrigger(common.PlugnTriggerInput{
 		Args:               []string{s, appName, process, t, q, n},
 		DisableStdioBuffer: true,
 		StreamStdio:        true,
@@ -34,6 +52,30 @@ func CommandDefault(appName string, num int64, process string, tail, quiet bool)
 	return err
 }
 
+// CommandExport writes the persisted logs for an app as newline delimited json
+func CommandExport(appName string, since string, until string, grep string, process string) error {
+	if err := common.VerifyAppName(appName); err != nil {
+		return err
+	}
+
+	query, err := NewLogStoreQuery(since, until, grep, process)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	return ReadStore(appName, query, func(record LogRecord) error {
+		return encoder.Encode(LogExportRecord{
+			Timestamp:   record.Timestamp,
+			App:         appName,
+			ProcessType: record.ProcessType(),
+			Dyno:        record.Dyno(),
+			Stream:      record.Stream,
+			Message:     record.Message,
+		})
+	})
+}
+
 // CommandFailed shows the last failed deploy logs
 func CommandFailed(appName string, allApps bool) error {
 	if allApps {
@@ -69,6 +111,45 @@ func CommandReport(appName string, format string, infoFlag string) error {
 	return ReportSingleApp(appName, format, infoFlag)
 }
 
+// CommandRotate compresses and prunes the persisted logs for one or more apps
+func CommandRotate(appName string, allApps bool) error {
+	if allApps {
+		return common.RunCommandAgainstAllAppsSerially(rotateStoreForApp, "logs:rotate")
+	}
+
+	if err := common.VerifyAppName(appName); err != nil {
+		return err
+	}
+
+	return rotateStoreForApp(appName)
+}
+
+// CommandSearch displays the persisted logs for an app that match the specified filters
+func CommandSearch(appName string, since string, until string, grep string, process string, quiet bool) error {
+	if err := common.VerifyAppName(appName); err != nil {
+		return err
+	}
+
+	if !isStoreEnabled(appName) {
+		common.LogWarn(fmt.Sprintf("Logs are not being persisted for %s, enable this via 'dokku logs:set %s store true'", appName, appName))
+	}
+
+	query, err := NewLogStoreQuery(since, until, grep, process)
+	if err != nil {
+		return err
+	}
+
+	return ReadStore(appName, query, func(record LogRecord) error {
+		if quiet {
+			fmt.Println(record.Message)
+			return nil
+		}
+
+		fmt.Printf("%s %s[%s]: %s\n", record.Timestamp.Format(time.RFC3339Nano), appName, record.Dyno(), record.Message)
+		return nil
+	})
+}
+
 // CommandSet sets or clears a logs property for an app
 func CommandSet(appName string, property string, value string) error {
 	if err := validateSetValue(appName, property, value); err != nil {
@@ -79,9 +160,22 @@ func CommandSet(appName string, property string, value string) error {
 
 	vectorProperties := map[string]bool{
 		"app-label-alias": true,
+		"log-format":      true,
+		"store":           true,
 		"vector-sink":     true,
 	}
 
+	if property == "store" {
+		// the log store is rotated by an injected cron task
+		if _, err := common.CallPlugnTrigger(common.PlugnTriggerInput{
+			Trigger:     "scheduler-cron-write",
+			Args:        []string{"docker-local", appName},
+			StreamStdio: true,
+		}); err != nil {
+			return err
+		}
+	}
+
 	if _, ok := vectorProperties[property]; ok {
 		common.LogVerboseQuiet(fmt.Sprintf("Writing updated vector config to %s", filepath.Join(common.GetDataDirectory("logs"), "vector.json")))
 		return writeVectorConfig()
@@ -89,6 +183,120 @@ func CommandSet(appName string, property string, value string) error {
 	return nil
 }
 
+// CommandSinkAdd adds a named vector sink for an app or globally
+func CommandSinkAdd(appName string, sinkName string, dsn string, processType string, level string, sampleRate int, redact []string) error {
+	if appName != "--global" {
+		if err := common.VerifyAppName(appName); err != nil {
+			return err
+		}
+	}
+
+	sink := NamedSink{
+		Name:        sinkName,
+		DSN:         dsn,
+		ProcessType: processType,
+		Level:       level,
+		SampleRate:  sampleRate,
+		Redact:      redact,
+	}
+	if err := sink.Validate(appName); err != nil {
+		return err
+	}
+
+	if common.PropertyExists("logs", appName, getSinkProperty(sinkName)) {
+		return fmt.Errorf("Sink %s already exists, remove it before adding it again", sinkName)
+	}
+
+	b, err := json.Marshal(sink)
+	if err != nil {
+		return fmt.Errorf("Unable to marshal sink to json: %w", err)
+	}
+
+	if err := common.PropertyWrite("logs", appName, getSinkProperty(sinkName), string(b)); err != nil {
+		return fmt.Errorf("Unable to write sink: %w", err)
+	}
+
+	common.LogInfo1(fmt.Sprintf("Sink %s added", sinkName))
+	common.LogVerboseQuiet(fmt.Sprintf("Writing updated vector config to %s", filepath.Join(common.GetDataDirectory("logs"), "vector.json")))
+	return writeVectorConfig()
+}
+
+// CommandSinkList lists the named vector sinks for an app or globally
+func CommandSinkList(appName string, format string) error {
+	if format == "" {
+		format = "stdout"
+	}
+
+	if format != "stdout" && format != "json" {
+		return fmt.Errorf("Invalid format specified, supported formats: json, stdout")
+	}
+
+	if appName != "--global" {
+		if err := common.VerifyAppName(appName); err != nil {
+			return err
+		}
+	}
+
+	sinks, err := FetchNamedSinks(appName)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		b, err := json.Marshal(sinks)
+		if err != nil {
+			return fmt.Errorf("Unable to marshal json: %w", err)
+		}
+
+		fmt.Println(string(b))
+		return nil
+	}
+
+	// only show the sink type, as the dsn may contain credentials
+	lines := []string{"Name | Type | Process Type | Level | Sample Rate | Redact"}
+	for _, sink := range sinks {
+		sinkType := ""
+		if config, err := SinkValueToConfig(appName, sink.DSN); err == nil {
+			sinkType = fmt.Sprint(config["type"])
+		}
+
+		sampleRate := ""
+		if sink.SampleRate > 1 {
+			sampleRate = strconv.Itoa(sink.SampleRate)
+		}
+
+		lines = append(lines, fmt.Sprintf("%s | %s | %s | %s | %s | %s", sink.Name, sinkType, sink.ProcessType, sink.Level, sampleRate, strings.Join(sink.Redact, ", ")))
+	}
+
+	fmt.Println(columnize.SimpleFormat(lines))
+	return nil
+}
+
+// CommandSinkRemove removes a named vector sink for an app or globally
+func CommandSinkRemove(appName string, sinkName string) error {
+	if appName != "--global" {
+		if err := common.VerifyAppName(appName); err != nil {
+			return err
+		}
+	}
+
+	if err := validateSinkName(sinkName); err != nil {
+		return err
+	}
+
+	if !common.PropertyExists("logs", appName, getSinkProperty(sinkName)) {
+		return fmt.Errorf("Sink %s does not exist", sinkName)
+	}
+
+	if err := common.PropertyDelete("logs", appName, getSinkProperty(sinkName)); err != nil {
+		return fmt.Errorf("Unable to delete sink: %w", err)
+	}
+
+	common.LogInfo1(fmt.Sprintf("Sink %s removed", sinkName))
+	common.LogVerboseQuiet(fmt.Sprintf("Writing updated vector config to %s", filepath.Join(common.GetDataDirectory("logs"), "vector.json")))
+	return writeVectorConfig()
+}
+
 // CommandVectorLogs tails the log output for the vector container
 func CommandVectorLogs(lines int, tail bool) error {
 	if !common.ContainerExists(vectorContainerName) {
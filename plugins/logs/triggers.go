@@ -12,6 +12,21 @@ import (
 	dockeroptions "github.com/dokku/dokku/plugins/docker-options"
 )
 
+// TriggerCronEntries outputs the cron task that rotates the log store
+func TriggerCronEntries(scheduler string) error {
+	if scheduler != "docker-local" {
+		return nil
+	}
+
+	if !isStoreEnabledForAnyApp() {
+		return nil
+	}
+
+	logFile := filepath.Join(common.MustGetEnv("DOKKU_LOGS_DIR"), "logs-rotate.log")
+	fmt.Printf("15 * * * *;dokku logs:rotate --all;%s\n", logFile)
+	return nil
+}
+
 // TriggerDockerArgsProcessDeploy outputs the logs plugin docker options for an app
 func TriggerDockerArgsProcessDeploy(appName string) error {
 	stdin, err := io.ReadAll(os.Stdin)
@@ -118,7 +133,19 @@ func TriggerPostAppCloneSetup(oldAppName string, newAppName string) error {
 
 // TriggerPostAppRename removes the old app data
 func TriggerPostAppRename(oldAppName string, newAppName string) error {
-	return common.MigrateAppDataDirectory("logs", oldAppName, newAppName)
+	if err := common.MigrateAppDataDirectory("logs", oldAppName, newAppName); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(getStoreDirectory(oldAppName)); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(getStoreDirectory(newAppName)), 0755); err != nil {
+		return err
+	}
+
+	return os.Rename(getStoreDirectory(oldAppName), getStoreDirectory(newAppName))
 }
 
 // TriggerPostAppRenameSetup renames logs files
@@ -143,10 +170,15 @@ func TriggerPostCreate(appName string) error {
 func TriggerPostDelete(appName string) error {
 	dataErr := common.RemoveAppDataDirectory("logs", appName)
 	propertyErr := common.PropertyDestroy("logs", appName)
+	storeErr := os.RemoveAll(getStoreDirectory(appName))
 
 	if dataErr != nil {
 		return dataErr
 	}
 
+	if storeErr != nil {
+		return storeErr
+	}
+
 	return propertyErr
 }
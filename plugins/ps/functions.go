@@ -301,6 +301,15 @@ func scaleSet(input scaleSetInput) error {
 		return err
 	}
 
+	_, err = common.CallPlugnTrigger(common.PlugnTriggerInput{
+		Trigger:     "post-ps-scale",
+		Args:        append([]string{input.appName}, input.processTuples...),
+		StreamStdio: true,
+	})
+	if err != nil {
+		return err
+	}
+
 	if input.skipDeploy {
 		return nil
 	}
@@ -110,15 +110,18 @@ func isPushEnabled(appName string) bool {
 }
 
 func incrementTagVersion(appName string) (int, error) {
-	tag := common.PropertyGet("registry", appName, "tag-version")
-	if tag == "" {
-		tag = "0"
+	version, err := getTagVersion(appName, "tag-version")
+	if err != nil {
+		return 0, err
 	}
 
-	tag = strings.TrimSpace(tag)
-	version, err := strconv.Atoi(tag)
+	// a rollback may have moved the tag version backwards, so never reuse a pushed tag
+	pushedVersion, err := getTagVersion(appName, "pushed-tag-version")
 	if err != nil {
-		return 0, fmt.Errorf("Unable to convert existing tag version (%s) to integer: %v", tag, err)
+		return 0, err
+	}
+	if pushedVersion > version {
+		version = pushedVersion
 	}
 
 	version++
@@ -127,9 +130,53 @@ func incrementTagVersion(appName string) (int, error) {
 		return 0, err
 	}
 
+	if err := common.PropertyDelete("registry", appName, "pushed-tag-version"); err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+func getTagVersion(appName string, property string) (int, error) {
+	tag := common.PropertyGet("registry", appName, property)
+	if tag == "" {
+		tag = "0"
+	}
+
+	tag = strings.TrimSpace(tag)
+	version, err := strconv.Atoi(tag)
+	if err != nil {
+		return 0, fmt.Errorf("Unable to convert existing tag version (%s) to integer: %v", tag, err)
+	}
+
 	return version, nil
 }
 
+// SetDeployedTagVersion records the tag version of an image that was redeployed, such as during a rollback
+func SetDeployedTagVersion(appName string, tagVersion int) error {
+	if tagVersion <= 0 {
+		return fmt.Errorf("Invalid tag version: %d", tagVersion)
+	}
+
+	version, err := getTagVersion(appName, "tag-version")
+	if err != nil {
+		return err
+	}
+
+	pushedVersion, err := getTagVersion(appName, "pushed-tag-version")
+	if err != nil {
+		return err
+	}
+
+	if version > pushedVersion {
+		if err := common.PropertyWrite("registry", appName, "pushed-tag-version", strconv.Itoa(version)); err != nil {
+			return err
+		}
+	}
+
+	return common.PropertyWrite("registry", appName, "tag-version", strconv.Itoa(tagVersion))
+}
+
 func getRegistryPushExtraTagsForApp(appName string) string {
 	value := common.PropertyGet("registry", appName, "push-extra-tags")
 	if value == "" {
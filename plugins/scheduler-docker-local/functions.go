@@ -9,9 +9,6 @@ import (
 
 	"github.com/dokku/dokku/plugins/common"
 	"github.com/dokku/dokku/plugins/cron"
-	"golang.org/x/sync/errgroup"
-
-	base36 "github.com/multiformats/go-base36"
 )
 
 func deleteCrontab() error {
@@ -38,96 +35,40 @@ func deleteCrontab() error {
 	return nil
 }
 
-func generateCronTasks() ([]cron.CronTask, error) {
-	apps, _ := common.UnfilteredDokkuApps()
-
-	g := new(errgroup.Group)
-	results := make(chan []cron.CronTask, len(apps)+1)
-	for _, appName := range apps {
-		appName := appName
-		g.Go(func() error {
-			scheduler := common.GetAppScheduler(appName)
-			if scheduler != "docker-local" {
-				results <- []cron.CronTask{}
-				return nil
-			}
-
-			c, err := cron.FetchCronTasks(cron.FetchCronTasksInput{AppName: appName})
-			if err != nil {
-				results <- []cron.CronTask{}
-				common.LogWarn(err.Error())
-				return nil
-			}
-
-			results <- c
-			return nil
-		})
-	}
-
-	g.Go(func() error {
-		tasks := []cron.CronTask{}
-		response, _ := common.CallPlugnTrigger(common.PlugnTriggerInput{
-			Trigger: "cron-entries",
-			Args:    []string{"docker-local"},
-		})
-		for _, line := range strings.Split(response.StdoutContents(), "\n") {
-			if strings.TrimSpace(line) == "" {
-				results <- []cron.CronTask{}
-				return nil
-			}
-
-			parts := strings.Split(line, ";")
-			if len(parts) != 2 && len(parts) != 3 {
-				results <- []cron.CronTask{}
-				return fmt.Errorf("Invalid injected cron task: %v", line)
-			}
-
-			id := base36.EncodeToStringLc([]byte(strings.Join(parts, ";;;")))
-			task := cron.CronTask{
-				ID:          id,
-				Schedule:    parts[0],
-				AltCommand:  parts[1],
-				Maintenance: false,
-			}
-			if len(parts) == 3 {
-				task.LogFile = parts[2]
-			}
-			tasks = append(tasks, task)
-		}
-		results <- tasks
+func writeCronTab(scheduler string) error {
+	// allow empty scheduler, which means all apps (used by letsencrypt)
+	if scheduler != "docker-local" && scheduler != "" {
 		return nil
-	})
-
-	err := g.Wait()
-	close(results)
-
-	tasks := []cron.CronTask{}
-	if err != nil {
-		return tasks, err
 	}
 
-	for result := range results {
-		for _, task := range result {
-			if !task.Maintenance {
-				tasks = append(tasks, task)
-			}
-		}
-	}
+	runnerResults, _ := common.CallPlugnTrigger(common.PlugnTriggerInput{
+		Trigger: "cron-get-property",
+		Args:    []string{"--global", "runner"},
+	})
 
-	return tasks, nil
-}
+	// a running cron daemon either picks up the changed tasks or, when
+	// the crontab runner is selected, stops scheduling tasks altogether
+	if err := cron.SignalDaemonReload(); err != nil {
+		return err
+	}
 
-func writeCronTab(scheduler string) error {
-	// allow empty scheduler, which means all apps (used by letsencrypt)
-	if scheduler != "docker-local" && scheduler != "" {
-		return nil
+	if runnerResults.StdoutContents() == "daemon" {
+		return deleteCrontab()
 	}
 
-	tasks, err := generateCronTasks()
+	allTasks, err := cron.FetchSchedulerCronTasks("docker-local")
 	if err != nil {
 		return err
 	}
 
+	// tasks that run after another task are run by cron:run once that task succeeds
+	tasks := []cron.CronTask{}
+	for _, task := range allTasks {
+		if task.After == "" {
+			tasks = append(tasks, task)
+		}
+	}
+
 	if len(tasks) == 0 {
 		return deleteCrontab()
 	}
@@ -18,9 +18,11 @@ import (
 
 	appjson "github.com/dokku/dokku/plugins/app-json"
 	"github.com/dokku/dokku/plugins/common"
+	"github.com/dokku/dokku/plugins/config"
 	dockeroptions "github.com/dokku/dokku/plugins/docker-options"
 	"github.com/dokku/dokku/plugins/logs"
 	nginxvhosts "github.com/dokku/dokku/plugins/nginx-vhosts"
+	"github.com/dokku/dokku/plugins/storage"
 	resty "github.com/go-resty/resty/v2"
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"golang.org/x/sync/errgroup"
@@ -84,6 +86,38 @@ func (n Node) String() string {
 	return fmt.Sprintf("%s|%s|%s|%s", n.Name, strconv.FormatBool(n.Ready), strings.Join(n.Roles, ","), n.Version)
 }
 
+// AppRelease is a single helm release revision of an app
+type AppRelease struct {
+	// Revision is the helm revision number
+	Revision int `json:"revision"`
+
+	// Image is the full image name deployed in the revision
+	Image string `json:"image"`
+
+	// ImageTag is the tag of the image deployed in the revision
+	ImageTag string `json:"image_tag"`
+
+	// DeploymentID is the dokku deployment id of the revision
+	DeploymentID string `json:"deployment_id"`
+
+	// Status is the helm status of the revision
+	Status string `json:"status"`
+
+	// Description is the helm description of the revision
+	Description string `json:"description"`
+
+	// DeployedAt is the time the revision was deployed
+	DeployedAt time.Time `json:"deployed_at"`
+
+	// Rollout is whether the revision was deployed as part of an in-progress rollout
+	Rollout bool `json:"rollout"`
+}
+
+// String returns a string representation of the app release
+func (r AppRelease) String() string {
+	return fmt.Sprintf("%d|%s|%s|%s|%s", r.Revision, r.ImageTag, r.DeploymentID, r.Status, r.DeployedAt.Format(time.RFC3339))
+}
+
 // StartCommandInput contains all the information needed to get the start command
 type StartCommandInput struct {
 	// AppName is the name of the app
@@ -520,6 +554,12 @@ func getAnnotations(appName string, processType string) (ProcessAnnotations, err
 	}
 	annotations.PodAnnotations = podAnnotations
 
+	podDisruptionBudgetAnnotations, err := getAnnotation(appName, processType, "pod_disruption_budget")
+	if err != nil {
+		return annotations, err
+	}
+	annotations.PodDisruptionBudgetAnnotations = podDisruptionBudgetAnnotations
+
 	secretAnnotations, err := getAnnotation(appName, processType, "secret")
 	if err != nil {
 		return annotations, err
@@ -1098,6 +1138,12 @@ func getLabels(appName string, processType string) (ProcessLabels, error) {
 	}
 	labels.PodLabels = podLabels
 
+	podDisruptionBudgetLabels, err := getLabel(appName, processType, "pod_disruption_budget")
+	if err != nil {
+		return labels, err
+	}
+	labels.PodDisruptionBudgetLabels = podDisruptionBudgetLabels
+
 	secretLabels, err := getLabel(appName, processType, "secret")
 	if err != nil {
 		return labels, err
@@ -1156,6 +1202,249 @@ func getLabel(appName string, processType string, resourceType string) (map[stri
 	return labels, nil
 }
 
+// getScheduling retrieves the scheduling constraints for a given app and process type
+func getScheduling(appName string, processType string, matchLabels map[string]string) (ProcessScheduling, error) {
+	scheduling := ProcessScheduling{}
+	affinity, err := parseSchedulingAffinity(getSchedulingProperty(appName, processType, "affinity"))
+	if err != nil {
+		return scheduling, err
+	}
+	scheduling.Affinity = affinity
+
+	nodeSelector, err := parseSchedulingNodeSelector(getSchedulingProperty(appName, processType, "node-selector"))
+	if err != nil {
+		return scheduling, err
+	}
+	scheduling.NodeSelector = nodeSelector
+
+	tolerations, err := parseSchedulingTolerations(getSchedulingProperty(appName, processType, "tolerations"))
+	if err != nil {
+		return scheduling, err
+	}
+	scheduling.Tolerations = tolerations
+
+	topologySpreadConstraints, err := parseSchedulingTopologySpread(getSchedulingProperty(appName, processType, "topology-spread"), matchLabels)
+	if err != nil {
+		return scheduling, err
+	}
+	scheduling.TopologySpreadConstraints = topologySpreadConstraints
+
+	// server nodes added with --taint-scheduling reject app workloads unless the taint is tolerated
+	if schedulingTargetsServerNodes(scheduling) {
+		serverTolerations, err := parseSchedulingTolerations(ServerNodeTaint)
+		if err != nil {
+			return scheduling, err
+		}
+
+		tolerated := false
+		for _, toleration := range scheduling.Tolerations {
+			if toleration.Key == serverTolerations[0].Key {
+				tolerated = true
+			}
+		}
+		if !tolerated {
+			scheduling.Tolerations = append(scheduling.Tolerations, serverTolerations...)
+		}
+	}
+
+	return scheduling, nil
+}
+
+// getSchedulingProperty retrieves a scheduling property for a given app and process type, falling back to the app-wide value
+func getSchedulingProperty(appName string, processType string, property string) string {
+	value := common.PropertyGet("scheduler-k3s", appName, fmt.Sprintf("%s%s.%s", SchedulingPropertyPrefix, processType, property))
+	if value == "" && processType != GlobalProcessType {
+		value = common.PropertyGet("scheduler-k3s", appName, fmt.Sprintf("%s%s.%s", SchedulingPropertyPrefix, GlobalProcessType, property))
+	}
+
+	return value
+}
+
+// parseSchedulingAffinity parses a comma-separated list of node affinity expressions
+func parseSchedulingAffinity(value string) (*ProcessAffinity, error) {
+	expressions := []ProcessNodeSelectorRequirement{}
+	for _, expression := range strings.Split(value, ",") {
+		expression = strings.TrimSpace(expression)
+		if expression == "" {
+			continue
+		}
+
+		requirement := ProcessNodeSelectorRequirement{}
+		if key, values, ok := strings.Cut(expression, "!="); ok {
+			requirement.Key = key
+			requirement.Operator = "NotIn"
+			requirement.Values = strings.Split(values, "|")
+		} else if key, values, ok := strings.Cut(expression, "="); ok {
+			requirement.Key = key
+			requirement.Operator = "In"
+			requirement.Values = strings.Split(values, "|")
+		} else if strings.HasPrefix(expression, "!") {
+			requirement.Key = strings.TrimPrefix(expression, "!")
+			requirement.Operator = "DoesNotExist"
+		} else {
+			requirement.Key = expression
+			requirement.Operator = "Exists"
+		}
+
+		if requirement.Key == "" {
+			return nil, fmt.Errorf("Invalid affinity expression: %s", expression)
+		}
+		for _, v := range requirement.Values {
+			if v == "" {
+				return nil, fmt.Errorf("Invalid affinity expression: %s", expression)
+			}
+		}
+
+		expressions = append(expressions, requirement)
+	}
+
+	if len(expressions) == 0 {
+		return nil, nil
+	}
+
+	return &ProcessAffinity{
+		NodeAffinity: ProcessNodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: ProcessNodeSelector{
+				NodeSelectorTerms: []ProcessNodeSelectorTerm{
+					{MatchExpressions: expressions},
+				},
+			},
+		},
+	}, nil
+}
+
+// parseSchedulingNodeSelector parses a comma-separated list of key=value node labels
+func parseSchedulingNodeSelector(value string) (map[string]string, error) {
+	nodeSelector := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, labelValue, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("Invalid node selector, must be in key=value format: %s", pair)
+		}
+
+		nodeSelector[key] = labelValue
+	}
+
+	if len(nodeSelector) == 0 {
+		return nil, nil
+	}
+
+	return nodeSelector, nil
+}
+
+// parseSchedulingTolerations parses a comma-separated list of key[=value][:effect] tolerations
+func parseSchedulingTolerations(value string) ([]ProcessToleration, error) {
+	tolerations := []ProcessToleration{}
+	for _, taint := range strings.Split(value, ",") {
+		taint = strings.TrimSpace(taint)
+		if taint == "" {
+			continue
+		}
+
+		keyValue, effect, _ := strings.Cut(taint, ":")
+		if effect != "" && effect != "NoSchedule" && effect != "PreferNoSchedule" && effect != "NoExecute" {
+			return nil, fmt.Errorf("Invalid toleration effect %s, must be one of NoSchedule, PreferNoSchedule, NoExecute", effect)
+		}
+
+		key, taintValue, hasValue := strings.Cut(keyValue, "=")
+		if key == "" {
+			return nil, fmt.Errorf("Invalid toleration, must be in key[=value][:effect] format: %s", taint)
+		}
+
+		toleration := ProcessToleration{
+			Effect:   effect,
+			Key:      key,
+			Operator: "Exists",
+		}
+		if hasValue {
+			toleration.Operator = "Equal"
+			toleration.Value = taintValue
+		}
+
+		tolerations = append(tolerations, toleration)
+	}
+
+	if len(tolerations) == 0 {
+		return nil, nil
+	}
+
+	return tolerations, nil
+}
+
+// parseSchedulingTopologySpread parses a comma-separated list of topology-key[:max-skew[:when-unsatisfiable]] constraints
+func parseSchedulingTopologySpread(value string, matchLabels map[string]string) ([]ProcessTopologySpreadConstraint, error) {
+	constraints := []ProcessTopologySpreadConstraint{}
+	for _, constraint := range strings.Split(value, ",") {
+		constraint = strings.TrimSpace(constraint)
+		if constraint == "" {
+			continue
+		}
+
+		parts := strings.Split(constraint, ":")
+		if parts[0] == "" || len(parts) > 3 {
+			return nil, fmt.Errorf("Invalid topology spread constraint, must be in topology-key[:max-skew[:when-unsatisfiable]] format: %s", constraint)
+		}
+
+		maxSkew := 1
+		if len(parts) > 1 {
+			var err error
+			maxSkew, err = strconv.Atoi(parts[1])
+			if err != nil || maxSkew < 1 {
+				return nil, fmt.Errorf("Invalid topology spread max skew %s, must be a positive integer", parts[1])
+			}
+		}
+
+		whenUnsatisfiable := "ScheduleAnyway"
+		if len(parts) > 2 {
+			whenUnsatisfiable = parts[2]
+			if whenUnsatisfiable != "DoNotSchedule" && whenUnsatisfiable != "ScheduleAnyway" {
+				return nil, fmt.Errorf("Invalid topology spread when-unsatisfiable value %s, must be one of DoNotSchedule, ScheduleAnyway", whenUnsatisfiable)
+			}
+		}
+
+		constraints = append(constraints, ProcessTopologySpreadConstraint{
+			LabelSelector:     ProcessLabelSelector{MatchLabels: matchLabels},
+			MaxSkew:           maxSkew,
+			TopologyKey:       parts[0],
+			WhenUnsatisfiable: whenUnsatisfiable,
+		})
+	}
+
+	if len(constraints) == 0 {
+		return nil, nil
+	}
+
+	return constraints, nil
+}
+
+// schedulingTargetsServerNodes returns whether the scheduling constraints select k3s server nodes
+func schedulingTargetsServerNodes(scheduling ProcessScheduling) bool {
+	for _, label := range ServerRoleLabels {
+		if _, ok := scheduling.NodeSelector[label]; ok {
+			return true
+		}
+
+		if scheduling.Affinity == nil {
+			continue
+		}
+
+		for _, term := range scheduling.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+			for _, expression := range term.MatchExpressions {
+				if expression.Key == label && (expression.Operator == "In" || expression.Operator == "Exists") {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
 func getLetsencryptServer(appName string) string {
 	return common.PropertyGetDefault("scheduler-k3s", appName, "letsencrypt-server", "")
 }
@@ -1203,6 +1492,66 @@ func getComputedKustomizeRootPath(appName string) string {
 	return kustomizeRootPath
 }
 
+func getMinAvailable(appName string) string {
+	return common.PropertyGetDefault("scheduler-k3s", appName, "min-available", "")
+}
+
+func getGlobalMinAvailable() string {
+	return common.PropertyGetDefault("scheduler-k3s", "--global", "min-available", "")
+}
+
+func getComputedMinAvailable(appName string) string {
+	minAvailable := getMinAvailable(appName)
+	if minAvailable == "" {
+		minAvailable = getGlobalMinAvailable()
+	}
+
+	return minAvailable
+}
+
+// getProcessMinAvailable returns the min available value for a process type's pod disruption budget
+func getProcessMinAvailable(appName string, processType string, formation appjson.Formation, replicas int) (string, error) {
+	minAvailable := string(formation.MinAvailable)
+	if minAvailable == "" {
+		minAvailable = getComputedMinAvailable(appName)
+	}
+
+	if minAvailable == "" || replicas == 0 {
+		return "", nil
+	}
+
+	if err := validateMinAvailable(minAvailable); err != nil {
+		return "", err
+	}
+
+	if minAvailable == "0" || minAvailable == "0%" {
+		return "", nil
+	}
+
+	if quantity, err := strconv.Atoi(minAvailable); (err == nil && quantity >= replicas) || minAvailable == "100%" {
+		common.LogWarn(fmt.Sprintf("The min available value of %s for the %s process type leaves no pods to evict, nodes running it cannot be drained", minAvailable, processType))
+	}
+
+	return minAvailable, nil
+}
+
+// validateMinAvailable validates that a min available value is either a non-negative integer or a percentage
+func validateMinAvailable(value string) error {
+	if percentage, ok := strings.CutSuffix(value, "%"); ok {
+		if quantity, err := strconv.Atoi(percentage); err != nil || quantity < 0 || quantity > 100 {
+			return fmt.Errorf("Invalid min-available percentage %s, must be between 0%% and 100%%", value)
+		}
+
+		return nil
+	}
+
+	if quantity, err := strconv.Atoi(value); err != nil || quantity < 0 {
+		return fmt.Errorf("Invalid min-available value %s, must be a non-negative integer or a percentage", value)
+	}
+
+	return nil
+}
+
 func getNamespace(appName string) string {
 	return common.PropertyGetDefault("scheduler-k3s", appName, "namespace", "")
 }
@@ -1241,6 +1590,40 @@ func getComputedRollbackOnFailure(appName string) string {
 	return rollbackOnFailure
 }
 
+func getRolloutStrategy(appName string) string {
+	return common.PropertyGetDefault("scheduler-k3s", appName, "rollout-strategy", "")
+}
+
+func getGlobalRolloutStrategy() string {
+	return common.PropertyGetDefault("scheduler-k3s", "--global", "rollout-strategy", DefaultRolloutStrategy)
+}
+
+func getComputedRolloutStrategy(appName string) string {
+	rolloutStrategy := getRolloutStrategy(appName)
+	if rolloutStrategy == "" {
+		rolloutStrategy = getGlobalRolloutStrategy()
+	}
+
+	return rolloutStrategy
+}
+
+func getCanarySteps(appName string) string {
+	return common.PropertyGetDefault("scheduler-k3s", appName, "canary-steps", "")
+}
+
+func getGlobalCanarySteps() string {
+	return common.PropertyGetDefault("scheduler-k3s", "--global", "canary-steps", DefaultCanarySteps)
+}
+
+func getComputedCanarySteps(appName string) string {
+	canarySteps := getCanarySteps(appName)
+	if canarySteps == "" {
+		canarySteps = getGlobalCanarySteps()
+	}
+
+	return canarySteps
+}
+
 func getShmSize(appName string) string {
 	return common.PropertyGetDefault("scheduler-k3s", appName, "shm-size", "")
 }
@@ -1258,6 +1641,123 @@ func getComputedShmSize(appName string) string {
 	return shmSize
 }
 
+func getStorageClass(appName string) string {
+	return common.PropertyGetDefault("scheduler-k3s", appName, "storage-class", "")
+}
+
+func getGlobalStorageClass() string {
+	return common.PropertyGetDefault("scheduler-k3s", "--global", "storage-class", "")
+}
+
+func getComputedStorageClass(appName string) string {
+	storageClass := getStorageClass(appName)
+	if storageClass == "" {
+		storageClass = getGlobalStorageClass()
+	}
+	if storageClass == "" {
+		storageClass = DefaultStorageClass
+	}
+
+	return storageClass
+}
+
+// persistentVolume is a persistent volume of an app along with its rendered chart values
+type persistentVolume struct {
+	// Global contains the values used to render the persistent volume claim
+	Global GlobalVolume
+
+	// Volume is the persistent volume as configured by the storage plugin
+	Volume storage.Volume
+}
+
+// deletePersistentVolumeClaims deletes the persistent volume claims of an app, optionally limited to a single volume
+func deletePersistentVolumeClaims(ctx context.Context, appName string, volumeName string) error {
+	clientset, err := NewKubernetesClient()
+	if err != nil {
+		return fmt.Errorf("Error creating kubernetes client: %w", err)
+	}
+
+	labelSelector := fmt.Sprintf("app.kubernetes.io/part-of=%s", appName)
+	if volumeName != "" {
+		labelSelector = fmt.Sprintf("%s,dokku.com/volume-name=%s", labelSelector, volumeName)
+	}
+
+	namespace := getComputedNamespace(appName)
+	claims, err := clientset.ListPersistentVolumeClaims(ctx, ListPersistentVolumeClaimsInput{
+		Namespace:     namespace,
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return fmt.Errorf("Error listing persistent volume claims: %w", err)
+	}
+
+	for _, claim := range claims {
+		common.LogVerboseQuiet(fmt.Sprintf("Deleting persistent volume claim %s", claim.Name))
+		err := clientset.DeletePersistentVolumeClaim(ctx, DeletePersistentVolumeClaimInput{
+			Name:      claim.Name,
+			Namespace: namespace,
+		})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("Error deleting persistent volume claim %s: %w", claim.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// getPersistentVolumes returns the persistent volumes configured for an app
+func getPersistentVolumes(appName string) ([]persistentVolume, error) {
+	persistentVolumes := []persistentVolume{}
+	volumes, err := storage.FetchVolumes(appName)
+	if err != nil {
+		return persistentVolumes, err
+	}
+
+	for _, volume := range volumes {
+		storageClass := volume.StorageClass
+		if storageClass == "" {
+			storageClass = getComputedStorageClass(appName)
+		}
+
+		persistentVolumes = append(persistentVolumes, persistentVolume{
+			Global: GlobalVolume{
+				AccessMode:   volume.AccessMode,
+				ClaimName:    fmt.Sprintf("%s-%s", appName, volume.Name),
+				Name:         volume.Name,
+				Size:         volume.Size,
+				StorageClass: storageClass,
+			},
+			Volume: volume,
+		})
+	}
+
+	return persistentVolumes, nil
+}
+
+// getProcessVolumes returns the volumes to mount into a process type
+func getProcessVolumes(processType string, replicas int, volumes []ProcessVolume, persistentVolumes []persistentVolume) []ProcessVolume {
+	processVolumes := append([]ProcessVolume{}, volumes...)
+	for _, volume := range persistentVolumes {
+		if !volume.Volume.MountedInto(processType) {
+			continue
+		}
+
+		if replicas > 1 && volume.Volume.AccessMode == "ReadWriteOnce" {
+			common.LogWarn(fmt.Sprintf("Volume %s uses the ReadWriteOnce access mode, and all %s replicas must be scheduled onto the same node", volume.Volume.Name, processType))
+		}
+
+		processVolumes = append(processVolumes, ProcessVolume{
+			Name:      fmt.Sprintf("volume-%s", volume.Volume.Name),
+			MountPath: volume.Volume.ContainerPath,
+			PersistentVolumeClaim: &ProcessVolumePersistentVolumeClaim{
+				ClaimName: volume.Global.ClaimName,
+			},
+		})
+	}
+
+	return processVolumes
+}
+
 func getGlobalGlobalToken() string {
 	return common.PropertyGet("scheduler-k3s", "--global", "token")
 }
@@ -1308,6 +1808,56 @@ func helmValuesForApp(appName string) (AppValues, error) {
 	return appValues, nil
 }
 
+func getAppReleases(appName string) ([]AppRelease, error) {
+	namespace := getComputedNamespace(appName)
+	helmAgent, err := NewHelmAgent(namespace, DevNullPrinter)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating helm agent: %w", err)
+	}
+
+	revisions, err := helmAgent.ListRevisions(ListRevisionsInput{
+		ReleaseName: appName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	releases := []AppRelease{}
+	for _, revision := range revisions {
+		b, err := yaml.Marshal(revision.Values)
+		if err != nil {
+			return nil, fmt.Errorf("Error marshaling helm values for revision %d: %w", revision.Revision, err)
+		}
+
+		var appValues AppValues
+		if err := yaml.Unmarshal(b, &appValues); err != nil {
+			return nil, fmt.Errorf("Error unmarshaling helm values for revision %d: %w", revision.Revision, err)
+		}
+
+		releases = append(releases, AppRelease{
+			Revision:     revision.Revision,
+			Image:        appValues.Global.Image.Name,
+			ImageTag:     getImageTag(appValues.Global.Image.Name),
+			DeploymentID: appValues.Global.DeploymentID,
+			Status:       revision.Status.String(),
+			Description:  revision.Description,
+			DeployedAt:   revision.LastDeployed,
+			Rollout:      appValues.Global.Rollout != nil,
+		})
+	}
+
+	return releases, nil
+}
+
+func getImageTag(image string) string {
+	index := strings.LastIndex(image, ":")
+	if index == -1 || strings.Contains(image[index:], "/") {
+		return "latest"
+	}
+
+	return image[index+1:]
+}
+
 func isAppDeployed(appName string) bool {
 	namespace := getComputedNamespace(appName)
 	helmAgent, err := NewHelmAgent(namespace, DevNullPrinter)
@@ -1412,6 +1962,32 @@ func getProcessHealtchecks(healthchecks []appjson.Healthcheck, primaryPort int32
 	return processHealthchecks
 }
 
+// getProcessSecrets returns the base64-encoded values of the config vars set for a process type,
+// along with the app environment merged with the environment for the process type
+func getProcessSecrets(appName string, processType string, appEnv *config.Env) (map[string]string, map[string]string, error) {
+	secrets := map[string]string{}
+	processEnv, err := config.LoadProcessEnv(appName, processType)
+	if err != nil {
+		return secrets, appEnv.Map(), err
+	}
+
+	if processEnv.Len() == 0 {
+		return secrets, appEnv.Map(), nil
+	}
+
+	env, err := config.LoadMergedProcessEnv(appName, processType)
+	if err != nil {
+		return secrets, appEnv.Map(), err
+	}
+
+	for _, key := range processEnv.Keys() {
+		value, _ := env.Get(key)
+		secrets[key] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+
+	return secrets, env.Map(), nil
+}
+
 func getProcessResources(appName string, processType string) (ProcessResourcesMap, error) {
 	processResources := ProcessResourcesMap{
 		Limits: ProcessResources{},
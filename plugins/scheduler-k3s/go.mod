@@ -13,6 +13,7 @@ require (
 	github.com/dokku/dokku/plugins/logs v0.0.0-20250618161309-8d0c35f1333c
 	github.com/dokku/dokku/plugins/nginx-vhosts v0.0.0-20250618161309-8d0c35f1333c
 	github.com/dokku/dokku/plugins/registry v0.0.0-20250618161309-8d0c35f1333c
+	github.com/dokku/dokku/plugins/storage v0.0.0-20250618161309-8d0c35f1333c
 	github.com/fatih/color v1.19.0
 	github.com/fluxcd/pkg/kustomize v1.24.0
 	github.com/go-openapi/jsonpointer v0.22.5
@@ -192,6 +193,8 @@ replace github.com/dokku/dokku/plugins/nginx-vhosts => ../nginx-vhosts
 
 replace github.com/dokku/dokku/plugins/registry => ../registry
 
+replace github.com/dokku/dokku/plugins/storage => ../storage
+
 replace github.com/joho/godotenv => github.com/joho/godotenv v1.2.0
 
 replace github.com/imdario/mergo => github.com/imdario/mergo v0.3.16
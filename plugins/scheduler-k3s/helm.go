@@ -19,6 +19,7 @@ import (
 	"gopkg.in/yaml.v3"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/kube"
@@ -85,12 +86,15 @@ type ChartInput struct {
 }
 
 type Release struct {
-	AppVersion string
-	Name       string
-	Namespace  string
-	Revision   int
-	Status     release.Status
-	Version    string
+	AppVersion   string
+	Description  string
+	LastDeployed time.Time
+	Name         string
+	Namespace    string
+	Revision     int
+	Status       release.Status
+	Values       map[string]interface{}
+	Version      string
 }
 
 type HelmAgent struct {
@@ -354,13 +358,22 @@ func (h *HelmAgent) ListRevisions(input ListRevisionsInput) ([]Release, error) {
 			appVersion = release.Chart.AppVersion()
 		}
 
+		// dokku writes the values into the chart itself, so merge them with any supplied values
+		values, err := chartutil.CoalesceValues(release.Chart, release.Config)
+		if err != nil {
+			return nil, fmt.Errorf("Error getting values for revision %d: %w", release.Version, err)
+		}
+
 		releases = append(releases, Release{
-			AppVersion: appVersion,
-			Name:       release.Name,
-			Namespace:  release.Namespace,
-			Revision:   release.Version,
-			Status:     release.Info.Status,
-			Version:    release.Chart.Metadata.Version,
+			AppVersion:   appVersion,
+			Description:  release.Info.Description,
+			LastDeployed: release.Info.LastDeployed.Time,
+			Name:         release.Name,
+			Namespace:    release.Namespace,
+			Revision:     release.Version,
+			Status:       release.Info.Status,
+			Values:       values,
+			Version:      release.Chart.Metadata.Version,
 		})
 	}
 
@@ -430,6 +443,100 @@ func (h *HelmAgent) UpgradeChart(ctx context.Context, input ChartInput) error {
 	return nil
 }
 
+// UpgradeReleaseValuesInput is the input for the UpgradeReleaseValues function
+type UpgradeReleaseValuesInput struct {
+	// KustomizeRootPath is the path to the kustomize root path to use
+	KustomizeRootPath string
+
+	// ReleaseName is the name of the release to upgrade
+	ReleaseName string
+
+	// Timeout is the timeout for the upgrade
+	Timeout time.Duration
+
+	// Values replaces the values of the chart stored in the release
+	Values map[string]interface{}
+
+	// Wait is whether to wait for the upgrade to complete
+	Wait bool
+}
+
+// UpgradeReleaseValues upgrades a release using the chart of the currently deployed revision with new values
+func (h *HelmAgent) UpgradeReleaseValues(ctx context.Context, input UpgradeReleaseValuesInput) error {
+	if input.ReleaseName == "" {
+		return fmt.Errorf("Release name is required")
+	}
+
+	getClient := action.NewGet(h.Configuration)
+	currentRelease, err := getClient.Run(input.ReleaseName)
+	if err != nil {
+		return fmt.Errorf("Error getting release: %w", err)
+	}
+
+	if currentRelease.Chart == nil {
+		return fmt.Errorf("Release %s has no chart", input.ReleaseName)
+	}
+
+	kustomizeRenderer := KustomizeRenderer{
+		ReleaseName:       input.ReleaseName,
+		KustomizeRootPath: input.KustomizeRootPath,
+	}
+
+	client := action.NewUpgrade(h.Configuration)
+	client.CleanupOnFail = true
+	client.MaxHistory = 10
+	client.Namespace = h.Namespace
+	client.PostRenderer = &kustomizeRenderer
+	client.Timeout = input.Timeout
+	client.Wait = input.Wait
+
+	currentRelease.Chart.Values = input.Values
+	_, err = client.RunWithContext(ctx, input.ReleaseName, currentRelease.Chart, map[string]interface{}{})
+	if err != nil {
+		return fmt.Errorf("Error upgrading: %w", err)
+	}
+
+	return nil
+}
+
+// RollbackChartInput is the input for the RollbackChart function
+type RollbackChartInput struct {
+	// ReleaseName is the name of the release to rollback
+	ReleaseName string
+
+	// Revision is the revision to rollback to
+	Revision int
+
+	// Timeout is the timeout for the rollback
+	Timeout time.Duration
+
+	// Wait is whether to wait for the rollback to complete
+	Wait bool
+}
+
+// RollbackChart rolls back a release to a previous revision, waiting up to the input timeout for it to complete
+func (h *HelmAgent) RollbackChart(input RollbackChartInput) error {
+	if input.ReleaseName == "" {
+		return fmt.Errorf("Release name is required")
+	}
+	if input.Revision <= 0 {
+		return fmt.Errorf("Revision is required")
+	}
+
+	client := action.NewRollback(h.Configuration)
+	client.CleanupOnFail = true
+	client.MaxHistory = 10
+	client.Timeout = input.Timeout
+	client.Version = input.Revision
+	client.Wait = input.Wait
+
+	if err := client.Run(input.ReleaseName); err != nil {
+		return fmt.Errorf("Error rolling back: %w", err)
+	}
+
+	return nil
+}
+
 func (h *HelmAgent) UninstallChart(releaseName string) error {
 	exists, err := h.ChartExists(releaseName)
 	if err != nil {
@@ -35,6 +35,7 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/kubectl/pkg/drain"
 	"k8s.io/kubectl/pkg/util/term"
 	"k8s.io/utils/ptr"
 )
@@ -242,6 +243,35 @@ func (k KubernetesClient) ApplyKubernetesManifest(ctx context.Context, input App
 	return nil
 }
 
+// CordonNodeInput contains all the information needed to cordon or uncordon a Kubernetes node
+type CordonNodeInput struct {
+	// Name is the Kubernetes node name
+	Name string
+
+	// Unschedulable is whether the node should be marked as unschedulable
+	Unschedulable bool
+}
+
+// CordonNode marks a Kubernetes node as unschedulable, or schedulable again
+func (k KubernetesClient) CordonNode(ctx context.Context, input CordonNodeInput) error {
+	node, err := k.Client.CoreV1().Nodes().Get(ctx, input.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if node == nil {
+		return &NotFoundError{"node is nil"}
+	}
+
+	helper := &drain.Helper{
+		Ctx:    ctx,
+		Client: &k.Client,
+		Out:    os.Stdout,
+		ErrOut: os.Stderr,
+	}
+	return drain.RunCordonOrUncordon(helper, node, input.Unschedulable)
+}
+
 // CreateJobInput contains all the information needed to create a Kubernetes job
 type CreateJobInput struct {
 	// Job is the Kubernetes job
@@ -337,6 +367,20 @@ func (k KubernetesClient) DeleteNode(ctx context.Context, input DeleteNodeInput)
 	return k.Client.CoreV1().Nodes().Delete(ctx, input.Name, metav1.DeleteOptions{})
 }
 
+// DeletePersistentVolumeClaimInput contains all the information needed to delete a Kubernetes persistent volume claim
+type DeletePersistentVolumeClaimInput struct {
+	// Name is the Kubernetes persistent volume claim name
+	Name string
+
+	// Namespace is the Kubernetes namespace
+	Namespace string
+}
+
+// DeletePersistentVolumeClaim deletes a Kubernetes persistent volume claim
+func (k KubernetesClient) DeletePersistentVolumeClaim(ctx context.Context, input DeletePersistentVolumeClaimInput) error {
+	return k.Client.CoreV1().PersistentVolumeClaims(input.Namespace).Delete(ctx, input.Name, metav1.DeleteOptions{})
+}
+
 // DeletePodInput contains all the information needed to delete a Kubernetes pod
 type DeletePodInput struct {
 	// Name is the Kubernetes pod name
@@ -396,6 +440,35 @@ func (k KubernetesClient) DeleteSecret(ctx context.Context, input DeleteSecretIn
 	return k.Client.CoreV1().Secrets(input.Namespace).Delete(ctx, input.Name, metav1.DeleteOptions{})
 }
 
+// DrainNodeInput contains all the information needed to drain a Kubernetes node
+type DrainNodeInput struct {
+	// Force deletes pods that are not managed by a controller, which are not recreated elsewhere
+	Force bool
+
+	// Name is the Kubernetes node name
+	Name string
+
+	// Timeout is the maximum amount of time to wait for pods to be evicted
+	Timeout time.Duration
+}
+
+// DrainNode evicts all pods from a Kubernetes node, respecting any pod disruption budgets.
+// Unless Force is set, the drain fails if the node runs pods that are not managed by a controller.
+func (k KubernetesClient) DrainNode(ctx context.Context, input DrainNodeInput) error {
+	helper := &drain.Helper{
+		Ctx:                 ctx,
+		Client:              &k.Client,
+		DeleteEmptyDirData:  true,
+		Force:               input.Force,
+		GracePeriodSeconds:  -1,
+		IgnoreAllDaemonSets: true,
+		Out:                 os.Stdout,
+		ErrOut:              os.Stderr,
+		Timeout:             input.Timeout,
+	}
+	return drain.RunNodeDrain(helper, input.Name)
+}
+
 // ExecCommandInput contains all the information needed to execute a command in a Kubernetes pod
 type ExecCommandInput struct {
 	// Command is the command to execute
@@ -803,6 +876,30 @@ func (k KubernetesClient) ListNodes(ctx context.Context, input ListNodesInput) (
 	return nodeList.Items, err
 }
 
+// ListPersistentVolumeClaimsInput contains all the information needed to list Kubernetes persistent volume claims
+type ListPersistentVolumeClaimsInput struct {
+	// Namespace is the Kubernetes namespace
+	Namespace string
+
+	// LabelSelector is the Kubernetes label selector
+	LabelSelector string
+}
+
+// ListPersistentVolumeClaims lists Kubernetes persistent volume claims
+func (k KubernetesClient) ListPersistentVolumeClaims(ctx context.Context, input ListPersistentVolumeClaimsInput) ([]corev1.PersistentVolumeClaim, error) {
+	listOptions := metav1.ListOptions{LabelSelector: input.LabelSelector}
+	claimList, err := k.Client.CoreV1().PersistentVolumeClaims(input.Namespace).List(ctx, listOptions)
+	if err != nil {
+		return []corev1.PersistentVolumeClaim{}, err
+	}
+
+	if claimList == nil {
+		return []corev1.PersistentVolumeClaim{}, &NilResponseError{"persistent volume claim list is nil"}
+	}
+
+	return claimList.Items, nil
+}
+
 // ListPodsInput contains all the information needed to list Kubernetes pods
 type ListPodsInput struct {
 	// Namespace is the Kubernetes namespace
@@ -869,6 +966,42 @@ func (k KubernetesClient) ListTriggerAuthentications(ctx context.Context, input
 	return triggerAuthentications, nil
 }
 
+// PatchDeploymentInput contains all the information needed to patch a Kubernetes deployment
+type PatchDeploymentInput struct {
+	// Name is the Kubernetes deployment name
+	Name string
+
+	// Namespace is the Kubernetes namespace
+	Namespace string
+
+	// Patch is the json merge patch to apply
+	Patch []byte
+}
+
+// PatchDeployment applies a json merge patch to a Kubernetes deployment
+func (k KubernetesClient) PatchDeployment(ctx context.Context, input PatchDeploymentInput) error {
+	_, err := k.Client.AppsV1().Deployments(input.Namespace).Patch(ctx, input.Name, types.MergePatchType, input.Patch, metav1.PatchOptions{})
+	return err
+}
+
+// PatchSecretInput contains all the information needed to patch a Kubernetes secret
+type PatchSecretInput struct {
+	// Name is the Kubernetes secret name
+	Name string
+
+	// Namespace is the Kubernetes namespace
+	Namespace string
+
+	// Patch is the json merge patch to apply
+	Patch []byte
+}
+
+// PatchSecret applies a json merge patch to a Kubernetes secret
+func (k KubernetesClient) PatchSecret(ctx context.Context, input PatchSecretInput) error {
+	_, err := k.Client.CoreV1().Secrets(input.Namespace).Patch(ctx, input.Name, types.MergePatchType, input.Patch, metav1.PatchOptions{})
+	return err
+}
+
 // ResumeCronJobsInput contains all the information needed to resume a Kubernetes cron job
 type ResumeCronJobsInput struct {
 	// LabelSelector is the Kubernetes label selector
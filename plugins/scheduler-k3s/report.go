@@ -14,6 +14,9 @@ func ReportSingleApp(appName string, format string, infoFlag string) error {
 	}
 
 	flags := map[string]common.ReportFunc{
+		"--scheduler-k3s-computed-canary-steps":         reportComputedCanarySteps,
+		"--scheduler-k3s-canary-steps":                  reportCanarySteps,
+		"--scheduler-k3s-global-canary-steps":           reportGlobalCanarySteps,
 		"--scheduler-k3s-computed-deploy-timeout":       reportComputedDeployTimeout,
 		"--scheduler-k3s-deploy-timeout":                reportDeployTimeout,
 		"--scheduler-k3s-global-deploy-timeout":         reportGlobalDeployTimeout,
@@ -31,6 +34,9 @@ func ReportSingleApp(appName string, format string, infoFlag string) error {
 		"--scheduler-k3s-computed-kustomize-root-path":  reportComputedKustomizeRootPath,
 		"--scheduler-k3s-kustomize-root-path":           reportKustomizeRootPath,
 		"--scheduler-k3s-global-kustomize-root-path":    reportGlobalKustomizeRootPath,
+		"--scheduler-k3s-computed-min-available":        reportComputedMinAvailable,
+		"--scheduler-k3s-min-available":                 reportMinAvailable,
+		"--scheduler-k3s-global-min-available":          reportGlobalMinAvailable,
 		"--scheduler-k3s-computed-namespace":            reportComputedNamespace,
 		"--scheduler-k3s-namespace":                     reportNamespace,
 		"--scheduler-k3s-global-namespace":              reportGlobalNamespace,
@@ -38,9 +44,15 @@ func ReportSingleApp(appName string, format string, infoFlag string) error {
 		"--scheduler-k3s-computed-rollback-on-failure":  reportComputedRollbackOnFailure,
 		"--scheduler-k3s-rollback-on-failure":           reportRollbackOnFailure,
 		"--scheduler-k3s-global-rollback-on-failure":    reportGlobalRollbackOnFailure,
+		"--scheduler-k3s-computed-rollout-strategy":     reportComputedRolloutStrategy,
+		"--scheduler-k3s-rollout-strategy":              reportRolloutStrategy,
+		"--scheduler-k3s-global-rollout-strategy":       reportGlobalRolloutStrategy,
 		"--scheduler-k3s-computed-shm-size":             reportComputedShmSize,
 		"--scheduler-k3s-global-shm-size":               reportGlobalShmSize,
 		"--scheduler-k3s-shm-size":                      reportShmSize,
+		"--scheduler-k3s-computed-storage-class":        reportComputedStorageClass,
+		"--scheduler-k3s-global-storage-class":          reportGlobalStorageClass,
+		"--scheduler-k3s-storage-class":                 reportStorageClass,
 	}
 
 	chartProperties, err := common.PropertyGetAllByPrefix("scheduler-k3s", "--global", "chart.")
@@ -96,6 +108,18 @@ func ReportAutoscalingAuthSingleApp(appName string, format string, includeMetada
 	return common.ReportSingleApp("scheduler-k3s", appName, "", infoFlags, flagKeys, format, trimPrefix, uppercaseFirstCharacter)
 }
 
+func reportComputedCanarySteps(appName string) string {
+	return getComputedCanarySteps(appName)
+}
+
+func reportCanarySteps(appName string) string {
+	return getCanarySteps(appName)
+}
+
+func reportGlobalCanarySteps(appName string) string {
+	return getGlobalCanarySteps()
+}
+
 func reportComputedDeployTimeout(appName string) string {
 	return getComputedDeployTimeout(appName)
 }
@@ -163,6 +187,18 @@ func reportGlobalKustomizeRootPath(appName string) string {
 	return getGlobalKustomizeRootPath()
 }
 
+func reportComputedMinAvailable(appName string) string {
+	return getComputedMinAvailable(appName)
+}
+
+func reportMinAvailable(appName string) string {
+	return getMinAvailable(appName)
+}
+
+func reportGlobalMinAvailable(appName string) string {
+	return getGlobalMinAvailable()
+}
+
 func reportComputedNamespace(appName string) string {
 	return getComputedNamespace(appName)
 }
@@ -191,6 +227,18 @@ func reportGlobalRollbackOnFailure(appName string) string {
 	return getGlobalRollbackOnFailure()
 }
 
+func reportComputedRolloutStrategy(appName string) string {
+	return getComputedRolloutStrategy(appName)
+}
+
+func reportRolloutStrategy(appName string) string {
+	return getRolloutStrategy(appName)
+}
+
+func reportGlobalRolloutStrategy(appName string) string {
+	return getGlobalRolloutStrategy()
+}
+
 func reportComputedShmSize(appName string) string {
 	return getComputedShmSize(appName)
 }
@@ -202,3 +250,15 @@ func reportGlobalShmSize(appName string) string {
 func reportShmSize(appName string) string {
 	return getShmSize(appName)
 }
+
+func reportComputedStorageClass(appName string) string {
+	return getComputedStorageClass(appName)
+}
+
+func reportGlobalStorageClass(appName string) string {
+	return getGlobalStorageClass()
+}
+
+func reportStorageClass(appName string) string {
+	return getStorageClass(appName)
+}
@@ -0,0 +1,553 @@
+package scheduler_k3s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dokku/dokku/plugins/common"
+	"github.com/dokku/dokku/plugins/registry"
+	"gopkg.in/yaml.v3"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/ptr"
+)
+
+// RolloutStrategies are the supported rollout strategies
+var RolloutStrategies = []string{"blue-green", "canary", "rolling"}
+
+// RolloutProcessType is the process type that blue-green and canary rollouts apply to
+const RolloutProcessType = "web"
+
+// rolloutKeepPatch instructs helm to leave a resource in place when it is removed from a release
+var rolloutKeepPatch = []byte(`{"metadata":{"annotations":{"helm.sh/resource-policy":"keep"}}}`)
+
+// rolloutReleasePatch returns a resource to being fully managed by helm
+var rolloutReleasePatch = []byte(`{"metadata":{"annotations":{"helm.sh/resource-policy":null}}}`)
+
+// GetRolloutValuesInput contains all the information needed to start a rollout
+type GetRolloutValuesInput struct {
+	// AppName is the name of the app
+	AppName string
+
+	// Clientset is the kubernetes client
+	Clientset KubernetesClient
+
+	// Namespace is the namespace of the app
+	Namespace string
+
+	// Values are the chart values for the deploy
+	Values *AppValues
+}
+
+// validateRolloutStrategy validates a rollout strategy
+func validateRolloutStrategy(strategy string) error {
+	for _, s := range RolloutStrategies {
+		if s == strategy {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Invalid rollout strategy %s, supported strategies: %s", strategy, strings.Join(RolloutStrategies, ", "))
+}
+
+// parseCanarySteps parses a comma-separated list of weight:duration canary steps
+func parseCanarySteps(value string) ([]GlobalRolloutStep, error) {
+	steps := []GlobalRolloutStep{}
+	previousWeight := 0
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		weightValue, duration, _ := strings.Cut(part, ":")
+		weight, err := strconv.Atoi(strings.TrimSuffix(weightValue, "%"))
+		if err != nil || weight < 1 || weight > 99 {
+			return []GlobalRolloutStep{}, fmt.Errorf("Invalid canary step weight %s, must be a percentage between 1 and 99", weightValue)
+		}
+
+		if weight <= previousWeight {
+			return []GlobalRolloutStep{}, fmt.Errorf("Canary step weights must be increasing: %s", value)
+		}
+		previousWeight = weight
+
+		if duration == "pause" {
+			duration = ""
+		}
+		if duration != "" {
+			if _, err := time.ParseDuration(duration); err != nil {
+				return []GlobalRolloutStep{}, fmt.Errorf("Invalid canary step duration %s: %w", duration, err)
+			}
+		}
+
+		steps = append(steps, GlobalRolloutStep{
+			Duration: duration,
+			Weight:   weight,
+		})
+	}
+
+	if len(steps) == 0 {
+		return steps, errors.New("No canary steps specified")
+	}
+
+	return steps, nil
+}
+
+func getRolloutSteps(appName string, strategy string) ([]GlobalRolloutStep, error) {
+	switch strategy {
+	case "blue-green":
+		return []GlobalRolloutStep{{Weight: 0}}, nil
+	case "canary":
+		return parseCanarySteps(getComputedCanarySteps(appName))
+	}
+
+	return []GlobalRolloutStep{}, fmt.Errorf("Invalid rollout strategy: %s", strategy)
+}
+
+// getCurrentAppRelease returns the currently deployed release of an app
+func getCurrentAppRelease(releases []AppRelease) *AppRelease {
+	for i := len(releases) - 1; i >= 0; i-- {
+		if releases[i].Status == "deployed" {
+			return &releases[i]
+		}
+	}
+
+	return nil
+}
+
+// getRolloutInProgress returns the rollout that is in progress for an app, if any
+func getRolloutInProgress(appName string) (*GlobalRollout, error) {
+	if !isAppDeployed(appName) {
+		return nil, nil
+	}
+
+	appValues, err := helmValuesForApp(appName)
+	if err != nil {
+		return nil, err
+	}
+
+	return appValues.Global.Rollout, nil
+}
+
+// verifyRolloutInProgress returns the in-progress rollout for an app, erroring if there is none
+func verifyRolloutInProgress(appName string) (*GlobalRollout, error) {
+	if err := common.VerifyAppName(appName); err != nil {
+		return nil, err
+	}
+
+	if scheduler := common.GetAppScheduler(appName); scheduler != "k3s" {
+		return nil, fmt.Errorf("App %s is not using the k3s scheduler", appName)
+	}
+
+	rollout, err := getRolloutInProgress(appName)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to check for an in-progress rollout: %w", err)
+	}
+
+	if rollout == nil {
+		return nil, fmt.Errorf("No rollout in progress for %s", appName)
+	}
+
+	return rollout, nil
+}
+
+// getRolloutValues configures the chart values to deploy the web process alongside the running deployment,
+// leaving them untouched when a standard rolling update should be performed instead
+func getRolloutValues(ctx context.Context, input GetRolloutValuesInput) error {
+	strategy := getComputedRolloutStrategy(input.AppName)
+	if strategy == "rolling" {
+		return nil
+	}
+
+	processValues, ok := input.Values.Processes[RolloutProcessType]
+	if !ok || processValues.Replicas == 0 {
+		return nil
+	}
+
+	if processValues.Autoscaling.Enabled && processValues.Autoscaling.HttpTrigger.Type == "http" {
+		common.LogWarn(fmt.Sprintf("Skipping %s rollout as http autoscaling is enabled for the %s process type", strategy, RolloutProcessType))
+		return nil
+	}
+
+	steps, err := getRolloutSteps(input.AppName, strategy)
+	if err != nil {
+		return err
+	}
+
+	deploymentName := fmt.Sprintf("%s-%s", input.AppName, RolloutProcessType)
+	deployments, err := input.Clientset.ListDeployments(ctx, ListDeploymentsInput{
+		Namespace:     input.Namespace,
+		LabelSelector: fmt.Sprintf("app.kubernetes.io/instance=%s", deploymentName),
+	})
+	if err != nil {
+		return fmt.Errorf("Error listing deployments: %w", err)
+	}
+
+	if len(deployments) == 0 {
+		common.LogVerbose(fmt.Sprintf("Skipping %s rollout as the %s process type is not running", strategy, RolloutProcessType))
+		return nil
+	}
+
+	releases, err := getAppReleases(input.AppName)
+	if err != nil {
+		return fmt.Errorf("Error listing releases: %w", err)
+	}
+
+	currentRelease := getCurrentAppRelease(releases)
+	if currentRelease == nil {
+		common.LogVerbose(fmt.Sprintf("Skipping %s rollout as there is no deployed release", strategy))
+		return nil
+	}
+
+	secretNames := map[string]bool{}
+	podSpec := deployments[0].Spec.Template.Spec
+	for _, container := range podSpec.Containers {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil {
+				secretNames[envFrom.SecretRef.Name] = true
+			}
+		}
+	}
+	for _, imagePullSecret := range podSpec.ImagePullSecrets {
+		secretNames[imagePullSecret.Name] = true
+	}
+
+	// only secrets created by the app's release are removed by helm, so leave any others alone
+	stableSecrets := []string{}
+	for secretName := range secretNames {
+		secret, err := input.Clientset.GetSecret(ctx, GetSecretInput{
+			Name:      secretName,
+			Namespace: input.Namespace,
+		})
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("Error getting secret %s: %w", secretName, err)
+		}
+
+		if secret.Annotations["meta.helm.sh/release-name"] == input.AppName {
+			stableSecrets = append(stableSecrets, secretName)
+		}
+	}
+	sort.Strings(stableSecrets)
+
+	input.Values.Global.Rollout = &GlobalRollout{
+		ProcessType:    RolloutProcessType,
+		StableRevision: currentRelease.Revision,
+		StableSecrets:  stableSecrets,
+		Step:           0,
+		Steps:          steps,
+		Strategy:       strategy,
+		Weight:         steps[0].Weight,
+	}
+
+	return nil
+}
+
+// retainRolloutResources keeps helm from removing the stable deployment and its secrets when the rollout is deployed
+func retainRolloutResources(ctx context.Context, clientset KubernetesClient, namespace string, appName string, rollout *GlobalRollout) error {
+	common.LogInfo1(fmt.Sprintf("Starting %s rollout of the %s process type", rollout.Strategy, rollout.ProcessType))
+	deploymentName := fmt.Sprintf("%s-%s", appName, rollout.ProcessType)
+	err := clientset.PatchDeployment(ctx, PatchDeploymentInput{
+		Name:      deploymentName,
+		Namespace: namespace,
+		Patch:     rolloutKeepPatch,
+	})
+	if err != nil {
+		return fmt.Errorf("Error retaining deployment %s: %w", deploymentName, err)
+	}
+
+	for _, secretName := range rollout.StableSecrets {
+		err := clientset.PatchSecret(ctx, PatchSecretInput{
+			Name:      secretName,
+			Namespace: namespace,
+			Patch:     rolloutKeepPatch,
+		})
+		if err != nil {
+			return fmt.Errorf("Error retaining secret %s: %w", secretName, err)
+		}
+	}
+
+	return nil
+}
+
+// advanceRollout runs through the timed steps of a rollout until it is paused, promoted or aborted
+func advanceRollout(ctx context.Context, appName string) error {
+	for {
+		appValues, err := helmValuesForApp(appName)
+		if err != nil {
+			return err
+		}
+
+		rollout := appValues.Global.Rollout
+		if rollout == nil {
+			return nil
+		}
+
+		if rollout.Promoted {
+			return promoteRollout(ctx, appName, appValues)
+		}
+
+		step := rollout.Steps[rollout.Step]
+		if step.Duration == "" {
+			common.LogInfo1(fmt.Sprintf("Rollout paused with %d%% of traffic routed to the new release", rollout.Weight))
+			common.LogVerbose(fmt.Sprintf("Run 'dokku scheduler-k3s:promote %s' to continue the rollout", appName))
+			common.LogVerbose(fmt.Sprintf("Run 'dokku scheduler-k3s:abort %s' to restore the previous release", appName))
+			return nil
+		}
+
+		duration, err := time.ParseDuration(step.Duration)
+		if err != nil {
+			return fmt.Errorf("Invalid rollout step duration %s: %w", step.Duration, err)
+		}
+
+		common.LogInfo1(fmt.Sprintf("Waiting %s with %d%% of traffic routed to the new release", step.Duration, rollout.Weight))
+		if err := waitForRolloutHealth(ctx, appName, rollout, duration); err != nil {
+			common.LogWarn(fmt.Sprintf("Aborting rollout: %s", err.Error()))
+			if abortErr := abortRollout(appName, rollout); abortErr != nil {
+				return abortErr
+			}
+
+			return fmt.Errorf("Rollout aborted: %w", err)
+		}
+
+		if err := promoteRollout(ctx, appName, appValues); err != nil {
+			return err
+		}
+	}
+}
+
+// waitForRolloutHealth waits for the given duration, failing if the new release stops passing its readiness checks
+func waitForRolloutHealth(ctx context.Context, appName string, rollout *GlobalRollout, duration time.Duration) error {
+	clientset, err := NewKubernetesClient()
+	if err != nil {
+		return fmt.Errorf("Error creating kubernetes client: %w", err)
+	}
+
+	namespace := getComputedNamespace(appName)
+	deploymentName := fmt.Sprintf("%s-%s-canary", appName, rollout.ProcessType)
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	deadline := time.After(duration)
+	for {
+		deployments, err := clientset.ListDeployments(ctx, ListDeploymentsInput{
+			Namespace:     namespace,
+			LabelSelector: fmt.Sprintf("app.kubernetes.io/instance=%s", deploymentName),
+		})
+		if err != nil {
+			return fmt.Errorf("Error listing deployments: %w", err)
+		}
+
+		if len(deployments) == 0 {
+			return fmt.Errorf("Deployment %s not found", deploymentName)
+		}
+
+		replicas := ptr.Deref(deployments[0].Spec.Replicas, 1)
+		if deployments[0].Status.ReadyReplicas < replicas {
+			return fmt.Errorf("Deployment %s has %d of %d replicas ready", deploymentName, deployments[0].Status.ReadyReplicas, replicas)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// promoteRollout moves a rollout to its next step, completing the rollout after the last step
+func promoteRollout(ctx context.Context, appName string, appValues AppValues) error {
+	rollout := appValues.Global.Rollout
+	if !rollout.Promoted && rollout.Step+1 < len(rollout.Steps) {
+		rollout.Step++
+		rollout.Weight = rollout.Steps[rollout.Step].Weight
+		common.LogInfo1(fmt.Sprintf("Routing %d%% of traffic to the new release", rollout.Weight))
+		return upgradeAppValues(ctx, appName, appValues)
+	}
+
+	clientset, err := NewKubernetesClient()
+	if err != nil {
+		return fmt.Errorf("Error creating kubernetes client: %w", err)
+	}
+
+	namespace := getComputedNamespace(appName)
+	if !rollout.Promoted {
+		// update the stable deployment while all traffic is served by the new release
+		rollout.Promoted = true
+		rollout.Weight = 100
+		common.LogInfo1("Routing all traffic to the new release")
+		if err := upgradeAppValues(ctx, appName, appValues); err != nil {
+			return err
+		}
+
+		if err := releaseRolloutResources(ctx, clientset, namespace, appName, rollout); err != nil {
+			return err
+		}
+	}
+
+	common.LogInfo1("Removing the canary deployment")
+	appValues.Global.Rollout = nil
+	if err := upgradeAppValues(ctx, appName, appValues); err != nil {
+		return err
+	}
+
+	for _, secretName := range rollout.StableSecrets {
+		err := clientset.DeleteSecret(ctx, DeleteSecretInput{
+			Name:      secretName,
+			Namespace: namespace,
+		})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("Error deleting secret %s: %w", secretName, err)
+		}
+	}
+
+	common.LogInfo1("Rollout complete")
+	return nil
+}
+
+// abortRollout restores the release that was deployed before the rollout started
+func abortRollout(appName string, rollout *GlobalRollout) error {
+	// the deploy context may already be cancelled, so cleanup uses a fresh context
+	ctx := context.Background()
+	clientset, err := NewKubernetesClient()
+	if err != nil {
+		return fmt.Errorf("Error creating kubernetes client: %w", err)
+	}
+
+	timeout, err := getRolloutTimeout(appName)
+	if err != nil {
+		return err
+	}
+
+	namespace := getComputedNamespace(appName)
+	helmAgent, err := NewHelmAgent(namespace, DeployLogPrinter)
+	if err != nil {
+		return fmt.Errorf("Error creating helm agent: %w", err)
+	}
+
+	common.LogInfo1(fmt.Sprintf("Restoring revision %d", rollout.StableRevision))
+	err = helmAgent.RollbackChart(RollbackChartInput{
+		ReleaseName: appName,
+		Revision:    rollout.StableRevision,
+		Timeout:     timeout,
+		Wait:        true,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := releaseRolloutResources(ctx, clientset, namespace, appName, rollout); err != nil {
+		return err
+	}
+
+	imageTag, err := getDeployedAppImageTag(appName)
+	if err != nil {
+		return fmt.Errorf("Error getting deployed image: %w", err)
+	}
+
+	if err := recordDeployedImageTag(appName, getImageTag(imageTag)); err != nil {
+		return err
+	}
+
+	common.LogInfo1("Rollout aborted")
+	return nil
+}
+
+// releaseRolloutResources removes the helm resource policy from the resources retained for a rollout
+func releaseRolloutResources(ctx context.Context, clientset KubernetesClient, namespace string, appName string, rollout *GlobalRollout) error {
+	deploymentName := fmt.Sprintf("%s-%s", appName, rollout.ProcessType)
+	err := clientset.PatchDeployment(ctx, PatchDeploymentInput{
+		Name:      deploymentName,
+		Namespace: namespace,
+		Patch:     rolloutReleasePatch,
+	})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("Error releasing deployment %s: %w", deploymentName, err)
+	}
+
+	for _, secretName := range rollout.StableSecrets {
+		err := clientset.PatchSecret(ctx, PatchSecretInput{
+			Name:      secretName,
+			Namespace: namespace,
+			Patch:     rolloutReleasePatch,
+		})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("Error releasing secret %s: %w", secretName, err)
+		}
+	}
+
+	return nil
+}
+
+// upgradeAppValues upgrades the app's release with new values, reusing the deployed chart
+func upgradeAppValues(ctx context.Context, appName string, appValues AppValues) error {
+	b, err := yaml.Marshal(appValues)
+	if err != nil {
+		return fmt.Errorf("Error marshaling helm values: %w", err)
+	}
+
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(b, &values); err != nil {
+		return fmt.Errorf("Error unmarshaling helm values: %w", err)
+	}
+
+	timeout, err := getRolloutTimeout(appName)
+	if err != nil {
+		return err
+	}
+
+	helmAgent, err := NewHelmAgent(getComputedNamespace(appName), DeployLogPrinter)
+	if err != nil {
+		return fmt.Errorf("Error creating helm agent: %w", err)
+	}
+
+	kustomizeRootPath := ""
+	if hasKustomizeDirectory(appName) {
+		kustomizeRootPath = getProcessSpecificKustomizeRootPath(appName)
+	}
+
+	return helmAgent.UpgradeReleaseValues(ctx, UpgradeReleaseValuesInput{
+		KustomizeRootPath: kustomizeRootPath,
+		ReleaseName:       appName,
+		Timeout:           timeout,
+		Values:            values,
+		Wait:              true,
+	})
+}
+
+func getRolloutTimeout(appName string) (time.Duration, error) {
+	deployTimeout := getComputedDeployTimeout(appName)
+	if _, err := strconv.Atoi(deployTimeout); err == nil {
+		deployTimeout = fmt.Sprintf("%ss", deployTimeout)
+	}
+
+	timeout, err := time.ParseDuration(deployTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("Error parsing deploy timeout duration: %w", err)
+	}
+
+	return timeout, nil
+}
+
+// recordDeployedImageTag records the image tag of a restored release with the registry plugin
+func recordDeployedImageTag(appName string, imageTag string) error {
+	tagVersion, err := strconv.Atoi(imageTag)
+	if err != nil {
+		common.LogWarn(fmt.Sprintf("Image tag %s is not a registry tag version, the next restart will deploy the latest image", imageTag))
+		return nil
+	}
+
+	if err := registry.SetDeployedTagVersion(appName, tagVersion); err != nil {
+		return fmt.Errorf("Error recording deployed image tag: %w", err)
+	}
+
+	return nil
+}
@@ -18,17 +18,22 @@ import (
 var (
 	// DefaultProperties is a map of all valid k3s properties with corresponding default property values
 	DefaultProperties = map[string]string{
+		"canary-steps":        "",
 		"deploy-timeout":      "",
 		"letsencrypt-server":  "",
 		"kustomize-root-path": "",
 		"image-pull-secrets":  "",
+		"min-available":       "",
 		"namespace":           "",
 		"rollback-on-failure": "",
+		"rollout-strategy":    "",
 		"shm-size":            "",
+		"storage-class":       "",
 	}
 
 	// GlobalProperties is a map of all valid global k3s properties
 	GlobalProperties = map[string]bool{
+		"canary-steps":           true,
 		"deploy-timeout":         true,
 		"image-pull-secrets":     true,
 		"ingress-class":          true,
@@ -38,17 +43,33 @@ var (
 		"letsencrypt-server":     true,
 		"letsencrypt-email-prod": true,
 		"letsencrypt-email-stag": true,
+		"min-available":          true,
 		"namespace":              true,
 		"network-interface":      true,
 		"rollback-on-failure":    true,
+		"rollout-strategy":       true,
 		"shm-size":               true,
+		"storage-class":          true,
 		"token":                  true,
 	}
+
+	// SchedulingProperties is a map of all valid scheduling properties
+	SchedulingProperties = map[string]bool{
+		"affinity":        true,
+		"node-selector":   true,
+		"tolerations":     true,
+		"topology-spread": true,
+	}
 )
 
+const DefaultCanarySteps = "10:1m,50:1m"
 const DefaultIngressClass = "nginx"
+const DefaultRolloutStrategy = "rolling"
+const DefaultStorageClass = "longhorn"
 const GlobalProcessType = "--global"
 const KubeConfigPath = "/etc/rancher/k3s/k3s.yaml"
+const SchedulingPropertyPrefix = "scheduling."
+const ServerNodeTaint = "CriticalAddonsOnly=true:NoSchedule"
 const DefaultKubeContext = ""
 const TriggerAuthPropertyPrefix = "trigger-auth."
 
@@ -183,6 +204,12 @@ var ServerLabels = map[string]string{
 	"svccontroller.k3s.cattle.io/enablelb": "true",
 }
 
+// ServerRoleLabels are the labels k3s adds to server nodes
+var ServerRoleLabels = []string{
+	"node-role.kubernetes.io/control-plane",
+	"node-role.kubernetes.io/master",
+}
+
 // WorkerLabels are the labels for a worker node
 var WorkerLabels = map[string]string{
 	"node-role.kubernetes.io/worker": "worker",
@@ -17,18 +17,23 @@ Manage scheduler-k3s settings for an app
 Additional commands:`
 
 	helpContent = `
+    scheduler-k3s:abort <app>, Aborts an in-progress rollout and restores the previous release
     scheduler-k3s:autoscaling-auth:set <app|--global> <trigger> [<--metadata key=value>...], Set or clear a scheduler-k3s autoscaling keda trigger authentication resource for an app
     scheduler-k3s:annotations:set <app|--global> <property> (<value>) [--process-type PROCESS_TYPE] <--resource-type RESOURCE_TYPE>, Set or clear an annotation for a given app/process-type/resource-type combination
     scheduler-k3s:cluster:add [--profile PROFILE] [--role ROLE] [--insecure-allow-unknown-hosts] [--server-ip SERVER_IP] [--taint-scheduling] [--kubelet-args KUBELET_ARGS] <ssh://user@host:port>, Adds a server node to a Dokku-managed cluster
     scheduler-k3s:cluster:list [--format json|stdout], Lists all nodes in a Dokku-managed cluster
-    scheduler-k3s:cluster:remove [node-id], Removes client node to a Dokku-managed cluster
+    scheduler-k3s:cluster:remove [--drain-timeout DURATION] [--force] [node-id], Removes client node to a Dokku-managed cluster
     scheduler-k3s:ensure-charts, Ensures the k3s charts are installed
     scheduler-k3s:initialize [--server-ip SERVER_IP] [--taint-scheduling], Initializes a cluster
     scheduler-k3s:labels:set <app|--global> <property> (<value>) [--process-type PROCESS_TYPE] <--resource-type RESOURCE_TYPE>, Set or clear a label for a given app/process-type/resource-type combination
     scheduler-k3s:profiles:add <profile> [--role ROLE] [--insecure-allow-unknown-hosts] [--taint-scheduling] [--kubelet-args KUBELET_ARGS], Adds a node profile to the k3s cluster
     scheduler-k3s:profiles:list [--format json|stdout], Lists all node profiles in the k3s cluster
     scheduler-k3s:profiles:remove <profile>, Removes a node profile from the k3s cluster
+    scheduler-k3s:promote <app>, Continues an in-progress rollout
+    scheduler-k3s:releases <app> [--format json|stdout], Lists the deployed releases for an app
     scheduler-k3s:report [<app>] [<flag>], Displays a scheduler-k3s report for one or more apps
+    scheduler-k3s:rollback <app> [<revision>], Rolls an app back to a previous release
+    scheduler-k3s:scheduling:set <app> <property> (<value>) [--process-type PROCESS_TYPE], Set or clear a scheduling constraint for a given app/process-type combination
     scheduler-k3s:set <app> <property> (<value>), Set or clear a scheduler-k3s property for an app
     scheduler-k3s:show-kubeconfig, Displays the kubeconfig for remote usage
     scheduler-k3s:uninstall, Uninstalls k3s from the Dokku server`
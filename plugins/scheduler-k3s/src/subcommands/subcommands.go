@@ -18,6 +18,11 @@ func main() {
 
 	var err error
 	switch subcommand {
+	case "abort":
+		args := flag.NewFlagSet("scheduler-k3s:abort", flag.ExitOnError)
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		err = scheduler_k3s.CommandAbort(appName)
 	case "annotations:set":
 		args := flag.NewFlagSet("scheduler-k3s:annotations:set", flag.ExitOnError)
 		global := args.Bool("global", false, "--global: set a global property")
@@ -72,9 +77,11 @@ func main() {
 		err = scheduler_k3s.CommandClusterList(*format)
 	case "cluster:remove":
 		args := flag.NewFlagSet("scheduler-k3s:cluster:remove", flag.ExitOnError)
+		drainTimeout := args.String("drain-timeout", "300s", "drain-timeout: maximum time to wait for pods to be evicted from the node")
+		force := args.Bool("force", false, "--force: delete pods that are not managed by a controller when draining the node")
 		args.Parse(os.Args[2:])
 		nodeName := args.Arg(0)
-		err = scheduler_k3s.CommandClusterRemove(nodeName)
+		err = scheduler_k3s.CommandClusterRemove(nodeName, *drainTimeout, *force)
 	case "ensure-charts":
 		args := flag.NewFlagSet("scheduler-k3s:ensure-charts", flag.ExitOnError)
 		forceInstall := args.Bool("force", false, "--force: force install all charts")
@@ -123,6 +130,17 @@ func main() {
 		args.Parse(os.Args[2:])
 		profileName := args.Arg(0)
 		err = scheduler_k3s.CommandProfilesRemove(profileName)
+	case "promote":
+		args := flag.NewFlagSet("scheduler-k3s:promote", flag.ExitOnError)
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		err = scheduler_k3s.CommandPromote(appName)
+	case "releases":
+		args := flag.NewFlagSet("scheduler-k3s:releases", flag.ExitOnError)
+		format := args.String("format", "stdout", "format: [ stdout | json ]")
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		err = scheduler_k3s.CommandReleases(appName, *format)
 	case "report":
 		args := flag.NewFlagSet("scheduler-k3s:report", flag.ExitOnError)
 		format := args.String("format", "stdout", "format: [ stdout | json ]")
@@ -132,6 +150,20 @@ func main() {
 			appName := args.Arg(0)
 			err = scheduler_k3s.CommandReport(appName, *format, infoFlag)
 		}
+	case "rollback":
+		args := flag.NewFlagSet("scheduler-k3s:rollback", flag.ExitOnError)
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		revision := args.Arg(1)
+		err = scheduler_k3s.CommandRollback(appName, revision)
+	case "scheduling:set":
+		args := flag.NewFlagSet("scheduler-k3s:scheduling:set", flag.ExitOnError)
+		processType := args.String("process-type", "", "--process-type: scope to process-type")
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		property := args.Arg(1)
+		value := args.Arg(2)
+		err = scheduler_k3s.CommandSchedulingSet(appName, *processType, property, value)
 	case "set":
 		args := flag.NewFlagSet("scheduler-k3s:set", flag.ExitOnError)
 		global := args.Bool("global", false, "--global: set a global property")
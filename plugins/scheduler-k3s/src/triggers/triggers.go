@@ -129,6 +129,15 @@ func main() {
 		scheduler := flag.Arg(0)
 		appName := flag.Arg(1)
 		err = scheduler_k3s.TriggerSchedulerStop(scheduler, appName)
+	case "scheduler-storage-volume-destroy":
+		scheduler := flag.Arg(0)
+		appName := flag.Arg(1)
+		volumeName := flag.Arg(2)
+		err = scheduler_k3s.TriggerSchedulerStorageVolumeDestroy(scheduler, appName, volumeName)
+	case "scheduler-storage-volume-status":
+		scheduler := flag.Arg(0)
+		appName := flag.Arg(1)
+		err = scheduler_k3s.TriggerSchedulerStorageVolumeStatus(scheduler, appName)
 	case "scheduler-cron-write":
 		scheduler := flag.Arg(0)
 		appName := flag.Arg(1)
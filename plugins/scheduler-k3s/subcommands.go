@@ -12,14 +12,26 @@ import (
 	"regexp"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/dokku/dokku/plugins/common"
 	resty "github.com/go-resty/resty/v2"
 	"github.com/ryanuber/columnize"
 )
 
+// CommandAbort aborts an in-progress rollout and restores the previous release
+func CommandAbort(appName string) error {
+	rollout, err := verifyRolloutInProgress(appName)
+	if err != nil {
+		return err
+	}
+
+	return abortRollout(appName, rollout)
+}
+
 // CommandAnnotationsSet set or clear a scheduler-k3s annotation for an app
 func CommandAnnotationsSet(appName string, processType string, resourceType string, key string, value string) error {
 	if resourceType == "" {
@@ -308,7 +320,7 @@ func CommandInitialize(ingressClass string, serverIP string, taintScheduling boo
 		"--token", token,
 	}
 	if taintScheduling {
-		args = append(args, "--node-taint", "CriticalAddonsOnly=true:NoSchedule")
+		args = append(args, "--node-taint", ServerNodeTaint)
 	}
 
 	common.CommandPropertySet("scheduler-k3s", "--global", "ingress-class", ingressClass, DefaultProperties, GlobalProperties)
@@ -693,7 +705,7 @@ export INSTALL_K3S_VERSION=%s
 	}
 
 	if incomingProfile.TaintScheduling {
-		args = append(args, "--node-taint", "CriticalAddonsOnly=true:NoSchedule")
+		args = append(args, "--node-taint", ServerNodeTaint)
 	}
 
 	for _, kubeletArg := range incomingProfile.KubeletArgs {
@@ -821,11 +833,16 @@ func CommandClusterList(format string) error {
 }
 
 // CommandClusterRemove removes a node from the k3s cluster
-func CommandClusterRemove(nodeName string) error {
+func CommandClusterRemove(nodeName string, drainTimeout string, force bool) error {
 	if err := isK3sInstalled(); err != nil {
 		return fmt.Errorf("k3s not installed, cannot remove node from cluster: %w", err)
 	}
 
+	timeout, err := time.ParseDuration(drainTimeout)
+	if err != nil {
+		return fmt.Errorf("Error parsing drain timeout duration: %w", err)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, os.Interrupt, syscall.SIGHUP,
@@ -860,6 +877,37 @@ func CommandClusterRemove(nodeName string) error {
 		return fmt.Errorf("Node %s is not a remote node managed by Dokku", nodeName)
 	}
 
+	common.LogVerboseQuiet("Cordoning node")
+	err = clientset.CordonNode(ctx, CordonNodeInput{
+		Name:          nodeName,
+		Unschedulable: true,
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to cordon node: %w", err)
+	}
+
+	common.LogVerboseQuiet("Draining node")
+	if force {
+		common.LogWarn("Deleting any pods on the node that are not managed by a controller, these will not be recreated")
+	}
+	err = clientset.DrainNode(ctx, DrainNodeInput{
+		Force:   force,
+		Name:    nodeName,
+		Timeout: timeout,
+	})
+	if err != nil {
+		// the node stays in the cluster, so allow workloads to be scheduled onto it again
+		uncordonErr := clientset.CordonNode(context.Background(), CordonNodeInput{
+			Name:          nodeName,
+			Unschedulable: false,
+		})
+		if uncordonErr != nil {
+			common.LogWarn(fmt.Sprintf("Unable to uncordon node: %s", uncordonErr.Error()))
+		}
+
+		return fmt.Errorf("Unable to drain node: %w", err)
+	}
+
 	common.LogVerboseQuiet("Uninstalling k3s on remote host")
 	removeCmd, err := common.CallSshCommand(common.SshCommandInput{
 		Command:          "/usr/local/bin/k3s-uninstall.sh",
@@ -1139,6 +1187,74 @@ func CommandProfilesRemove(profileName string) error {
 	return nil
 }
 
+// CommandPromote continues an in-progress rollout
+func CommandPromote(appName string) error {
+	if _, err := verifyRolloutInProgress(appName); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGHUP,
+		syscall.SIGINT,
+		syscall.SIGQUIT,
+		syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	appValues, err := helmValuesForApp(appName)
+	if err != nil {
+		return err
+	}
+
+	if err := promoteRollout(ctx, appName, appValues); err != nil {
+		return err
+	}
+
+	return advanceRollout(ctx, appName)
+}
+
+// CommandReleases lists the helm release history for an app
+func CommandReleases(appName string, format string) error {
+	if format != "stdout" && format != "json" {
+		return fmt.Errorf("Invalid format: %s", format)
+	}
+
+	if err := common.VerifyAppName(appName); err != nil {
+		return err
+	}
+
+	releases, err := getAppReleases(appName)
+	if err != nil {
+		return fmt.Errorf("Unable to list releases: %w", err)
+	}
+
+	sort.Slice(releases, func(i, j int) bool {
+		return releases[i].Revision > releases[j].Revision
+	})
+
+	if format == "stdout" {
+		lines := []string{"revision|image tag|deployment id|status|deployed at"}
+		for _, release := range releases {
+			lines = append(lines, release.String())
+		}
+
+		columnized := columnize.SimpleFormat(lines)
+		fmt.Println(columnized)
+		return nil
+	}
+
+	b, err := json.Marshal(releases)
+	if err != nil {
+		return fmt.Errorf("Unable to marshal json: %w", err)
+	}
+
+	fmt.Println(string(b))
+	return nil
+}
+
 // CommandReport displays a scheduler-k3s report for one or more apps
 func CommandReport(appName string, format string, infoFlag string) error {
 	if len(appName) == 0 {
@@ -1161,6 +1277,158 @@ func CommandReport(appName string, format string, infoFlag string) error {
 	return ReportSingleApp(appName, format, infoFlag)
 }
 
+// CommandRollback rolls an app back to a previous helm release revision
+func CommandRollback(appName string, revision string) error {
+	if err := common.VerifyAppName(appName); err != nil {
+		return err
+	}
+
+	if scheduler := common.GetAppScheduler(appName); scheduler != "k3s" {
+		return fmt.Errorf("App %s is not using the k3s scheduler", appName)
+	}
+
+	rollout, err := getRolloutInProgress(appName)
+	if err != nil {
+		return fmt.Errorf("Unable to check for an in-progress rollout: %w", err)
+	}
+	if rollout != nil {
+		return fmt.Errorf("A %s rollout is in progress, run scheduler-k3s:promote or scheduler-k3s:abort first", rollout.Strategy)
+	}
+
+	releases, err := getAppReleases(appName)
+	if err != nil {
+		return fmt.Errorf("Unable to list releases: %w", err)
+	}
+
+	current := getCurrentAppRelease(releases)
+	if current == nil {
+		return fmt.Errorf("App %s has no deployed release", appName)
+	}
+
+	var target *AppRelease
+	if revision == "" {
+		for i := len(releases) - 1; i >= 0; i-- {
+			if releases[i].Revision < current.Revision && releases[i].Status == "superseded" && !releases[i].Rollout {
+				target = &releases[i]
+				break
+			}
+		}
+
+		if target == nil {
+			return fmt.Errorf("App %s has no previous release to roll back to", appName)
+		}
+	} else {
+		revisionNumber, err := strconv.Atoi(revision)
+		if err != nil {
+			return fmt.Errorf("Invalid revision: %s", revision)
+		}
+
+		for i := range releases {
+			if releases[i].Revision == revisionNumber {
+				target = &releases[i]
+				break
+			}
+		}
+
+		if target == nil {
+			return fmt.Errorf("Revision %d not found, run scheduler-k3s:releases %s to list available revisions", revisionNumber, appName)
+		}
+	}
+
+	if target.Revision == current.Revision {
+		return fmt.Errorf("Revision %d is already deployed", target.Revision)
+	}
+
+	if target.Status != "superseded" {
+		return fmt.Errorf("Revision %d has status %s and cannot be rolled back to", target.Revision, target.Status)
+	}
+
+	if target.Rollout {
+		return fmt.Errorf("Revision %d was deployed during a rollout and cannot be rolled back to", target.Revision)
+	}
+
+	timeoutDuration, err := getRolloutTimeout(appName)
+	if err != nil {
+		return err
+	}
+
+	helmAgent, err := NewHelmAgent(getComputedNamespace(appName), DeployLogPrinter)
+	if err != nil {
+		return fmt.Errorf("Error creating helm agent: %w", err)
+	}
+
+	common.LogInfo1(fmt.Sprintf("Rolling back %s to revision %d", appName, target.Revision))
+	common.LogVerbose(fmt.Sprintf("Image: %s", target.Image))
+	err = helmAgent.RollbackChart(RollbackChartInput{
+		ReleaseName: appName,
+		Revision:    target.Revision,
+		Timeout:     timeoutDuration,
+		Wait:        true,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := recordDeployedImageTag(appName, target.ImageTag); err != nil {
+		return err
+	}
+
+	common.LogInfo1(fmt.Sprintf("Rolled back %s to revision %d", appName, target.Revision))
+	return nil
+}
+
+// CommandSchedulingSet set or clear a scheduling constraint for a given app/process-type combination
+func CommandSchedulingSet(appName string, processType string, property string, value string) error {
+	if err := common.VerifyAppName(appName); err != nil {
+		return err
+	}
+
+	if !SchedulingProperties[property] {
+		validProperties := []string{}
+		for key := range SchedulingProperties {
+			validProperties = append(validProperties, key)
+		}
+		sort.Strings(validProperties)
+		return fmt.Errorf("Invalid scheduling property specified, valid properties include: %s", strings.Join(validProperties, ", "))
+	}
+
+	if processType == "" {
+		processType = GlobalProcessType
+	}
+
+	var err error
+	switch property {
+	case "affinity":
+		_, err = parseSchedulingAffinity(value)
+	case "node-selector":
+		_, err = parseSchedulingNodeSelector(value)
+	case "tolerations":
+		_, err = parseSchedulingTolerations(value)
+	case "topology-spread":
+		_, err = parseSchedulingTopologySpread(value, map[string]string{})
+	}
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("%s%s.%s", SchedulingPropertyPrefix, processType, property)
+	if value == "" {
+		common.LogInfo2Quiet(fmt.Sprintf("Unsetting %s", property))
+		if err := common.PropertyDelete("scheduler-k3s", appName, key); err != nil {
+			return fmt.Errorf("Unable to delete property: %w", err)
+		}
+
+		return nil
+	}
+
+	common.LogInfo2Quiet(fmt.Sprintf("Setting %s to %s", property, value))
+	if err := common.PropertyWrite("scheduler-k3s", appName, key, value); err != nil {
+		return fmt.Errorf("Unable to write property: %w", err)
+	}
+
+	return nil
+}
+
 // CommandSet set or clear a scheduler-k3s property for an app
 func CommandSet(appName string, property string, value string) error {
 	validProperties := DefaultProperties
@@ -1193,6 +1461,24 @@ func CommandSet(appName string, property string, value string) error {
 		}
 	}
 
+	if property == "rollout-strategy" && value != "" {
+		if err := validateRolloutStrategy(value); err != nil {
+			return err
+		}
+	}
+
+	if property == "canary-steps" && value != "" {
+		if _, err := parseCanarySteps(value); err != nil {
+			return err
+		}
+	}
+
+	if property == "min-available" && value != "" {
+		if err := validateMinAvailable(value); err != nil {
+			return err
+		}
+	}
+
 	common.CommandPropertySet("scheduler-k3s", appName, property, value, validProperties, globalProperties)
 
 	letsencryptProperties := map[string]bool{
@@ -50,8 +50,10 @@ type GlobalValues struct {
 	Keda            GlobalKedaValues   `yaml:"keda"`
 	Namespace       string             `yaml:"namespace"`
 	Network         GlobalNetwork      `yaml:"network"`
+	Rollout         *GlobalRollout     `yaml:"rollout,omitempty"`
 	Secrets         map[string]string  `yaml:"secrets,omitempty"`
 	SecurityContext SecurityContext    `yaml:"security_context,omitempty"`
+	Volumes         []GlobalVolume     `yaml:"volumes,omitempty"`
 }
 
 type GlobalImage struct {
@@ -62,6 +64,60 @@ type GlobalImage struct {
 	WorkingDir       string `yaml:"working_dir"`
 }
 
+// GlobalVolume contains the configuration for a persistent volume claim
+type GlobalVolume struct {
+	// AccessMode is the access mode of the persistent volume claim
+	AccessMode string `yaml:"access_mode"`
+
+	// ClaimName is the name of the persistent volume claim
+	ClaimName string `yaml:"claim_name"`
+
+	// Name is the name of the volume
+	Name string `yaml:"name"`
+
+	// Size is the requested size of the persistent volume claim
+	Size string `yaml:"size"`
+
+	// StorageClass is the storage class used to provision the persistent volume claim
+	StorageClass string `yaml:"storage_class"`
+}
+
+// GlobalRollout contains the state of an in-progress blue-green or canary rollout
+type GlobalRollout struct {
+	// ProcessType is the process type being rolled out
+	ProcessType string `yaml:"process_type"`
+
+	// Promoted is whether the rollout has been promoted and all traffic is routed to the new release
+	Promoted bool `yaml:"promoted,omitempty"`
+
+	// StableRevision is the helm revision that was deployed before the rollout started
+	StableRevision int `yaml:"stable_revision"`
+
+	// StableSecrets are the secrets used by the stable deployment that are kept during the rollout
+	StableSecrets []string `yaml:"stable_secrets,omitempty"`
+
+	// Step is the index of the current rollout step
+	Step int `yaml:"step"`
+
+	// Steps are the steps of the rollout
+	Steps []GlobalRolloutStep `yaml:"steps"`
+
+	// Strategy is the rollout strategy
+	Strategy string `yaml:"strategy"`
+
+	// Weight is the percentage of traffic routed to the canary deployment
+	Weight int `yaml:"weight"`
+}
+
+// GlobalRolloutStep is a single step of a rollout
+type GlobalRolloutStep struct {
+	// Duration is how long to wait before moving to the next step, with an empty duration pausing the rollout until it is promoted
+	Duration string `yaml:"duration,omitempty"`
+
+	// Weight is the percentage of traffic routed to the canary deployment
+	Weight int `yaml:"weight"`
+}
+
 type GlobalNetwork struct {
 	// IngressClass is the default ingress class to use
 	IngressClass string `yaml:"ingress_class"`
@@ -111,17 +167,76 @@ type ProcessValues struct {
 	Cron         ProcessCron         `yaml:"cron,omitempty"`
 	Healthchecks ProcessHealthchecks `yaml:"healthchecks,omitempty"`
 	Labels       ProcessLabels       `yaml:"labels,omitempty"`
+	MinAvailable string              `yaml:"min_available,omitempty"`
 	ProcessType  ProcessType         `yaml:"process_type"`
 	Replicas     int32               `yaml:"replicas"`
 	Resources    ProcessResourcesMap `yaml:"resources,omitempty"`
+	Scheduling   ProcessScheduling   `yaml:"scheduling,omitempty"`
+	Secrets      map[string]string   `yaml:"secrets,omitempty"`
 	Web          ProcessWeb          `yaml:"web,omitempty"`
 	Volumes      []ProcessVolume     `yaml:"volumes,omitempty"`
 }
 
+// ProcessScheduling contains the pod scheduling constraints for a process
+type ProcessScheduling struct {
+	// Affinity contains the node affinity rules for the process
+	Affinity *ProcessAffinity `yaml:"affinity,omitempty"`
+
+	// NodeSelector is a map of node labels the process must be scheduled onto
+	NodeSelector map[string]string `yaml:"node_selector,omitempty"`
+
+	// Tolerations is a list of node taints the process tolerates
+	Tolerations []ProcessToleration `yaml:"tolerations,omitempty"`
+
+	// TopologySpreadConstraints controls how pods of the process are spread across the cluster
+	TopologySpreadConstraints []ProcessTopologySpreadConstraint `yaml:"topology_spread_constraints,omitempty"`
+}
+
+type ProcessAffinity struct {
+	NodeAffinity ProcessNodeAffinity `yaml:"node_affinity"`
+}
+
+type ProcessNodeAffinity struct {
+	RequiredDuringSchedulingIgnoredDuringExecution ProcessNodeSelector `yaml:"required_during_scheduling_ignored_during_execution"`
+}
+
+type ProcessNodeSelector struct {
+	NodeSelectorTerms []ProcessNodeSelectorTerm `yaml:"node_selector_terms"`
+}
+
+type ProcessNodeSelectorTerm struct {
+	MatchExpressions []ProcessNodeSelectorRequirement `yaml:"match_expressions"`
+}
+
+type ProcessNodeSelectorRequirement struct {
+	Key      string   `yaml:"key"`
+	Operator string   `yaml:"operator"`
+	Values   []string `yaml:"values,omitempty"`
+}
+
+type ProcessToleration struct {
+	Effect   string `yaml:"effect,omitempty"`
+	Key      string `yaml:"key"`
+	Operator string `yaml:"operator"`
+	Value    string `yaml:"value,omitempty"`
+}
+
+type ProcessTopologySpreadConstraint struct {
+	LabelSelector     ProcessLabelSelector `yaml:"label_selector"`
+	MaxSkew           int                  `yaml:"max_skew"`
+	TopologyKey       string               `yaml:"topology_key"`
+	WhenUnsatisfiable string               `yaml:"when_unsatisfiable"`
+}
+
+type ProcessLabelSelector struct {
+	MatchLabels map[string]string `yaml:"match_labels"`
+}
+
 type ProcessVolume struct {
-	Name      string                 `yaml:"name"`
-	MountPath string                 `yaml:"mount_path"`
-	EmptyDir  *ProcessVolumeEmptyDir `yaml:"empty_dir,omitempty"`
+	Name                  string                              `yaml:"name"`
+	MountPath             string                              `yaml:"mount_path"`
+	EmptyDir              *ProcessVolumeEmptyDir              `yaml:"empty_dir,omitempty"`
+	PersistentVolumeClaim *ProcessVolumePersistentVolumeClaim `yaml:"persistent_volume_claim,omitempty"`
 }
 
 type ProcessVolumeEmptyDir struct {
@@ -129,6 +244,11 @@ type ProcessVolumeEmptyDir struct {
 	SizeLimit string `yaml:"size_limit"`
 }
 
+// ProcessVolumePersistentVolumeClaim references a persistent volume claim rendered by the app chart
+type ProcessVolumePersistentVolumeClaim struct {
+	ClaimName string `yaml:"claim_name"`
+}
+
 type ProcessAnnotations struct {
 	CertificateAnnotations               map[string]string `yaml:"certificate,omitempty"`
 	CronJobAnnotations                   map[string]string `yaml:"cronjob,omitempty"`
@@ -141,6 +261,7 @@ type ProcessAnnotations struct {
 	KedaSecretAnnotations                map[string]string `yaml:"keda_secret,omitempty"`
 	KedaTriggerAuthenticationAnnotations map[string]string `yaml:"keda_trigger_authentication,omitempty"`
 	PodAnnotations                       map[string]string `yaml:"pod,omitempty"`
+	PodDisruptionBudgetAnnotations       map[string]string `yaml:"pod_disruption_budget,omitempty"`
 	SecretAnnotations                    map[string]string `yaml:"secret,omitempty"`
 	ServiceAccountAnnotations            map[string]string `yaml:"serviceaccount,omitempty"`
 	ServiceAnnotations                   map[string]string `yaml:"service,omitempty"`
@@ -260,6 +381,7 @@ type ProcessLabels struct {
 	KedaSecretLabels                map[string]string `yaml:"keda_secret,omitempty"`
 	KedaTriggerAuthenticationLabels map[string]string `yaml:"keda_trigger_authentication,omitempty"`
 	PodLabels                       map[string]string `yaml:"pod,omitempty"`
+	PodDisruptionBudgetLabels       map[string]string `yaml:"pod_disruption_budget,omitempty"`
 	SecretLabels                    map[string]string `yaml:"secret,omitempty"`
 	ServiceAccountLabels            map[string]string `yaml:"serviceaccount,omitempty"`
 	ServiceLabels                   map[string]string `yaml:"service,omitempty"`
@@ -304,6 +426,13 @@ type ProcessCron struct {
 	Suffix            string                       `yaml:"suffix"`
 	Suspend           bool                         `yaml:"suspend"`
 	ConcurrencyPolicy ProcessCronConcurrencyPolicy `yaml:"concurrency_policy"`
+	TimeZone          string                       `yaml:"time_zone,omitempty"`
+	Pipeline          []ProcessCronPipelineStep    `yaml:"pipeline,omitempty"`
+}
+
+// ProcessCronPipelineStep is a cron task that runs before the main container of a cron job
+type ProcessCronPipelineStep struct {
+	Args []string `yaml:"args"`
 }
 
 type ProcessCronConcurrencyPolicy string
@@ -47,3 +47,54 @@
 {{- get $found "any" -}}
 {{- end -}}
 {{- end -}}
+
+{{- define "print.scheduling.affinity" -}}
+nodeAffinity:
+  requiredDuringSchedulingIgnoredDuringExecution:
+    nodeSelectorTerms:
+    {{- range $term := .node_affinity.required_during_scheduling_ignored_during_execution.node_selector_terms }}
+    - matchExpressions:
+      {{- range $expression := $term.match_expressions }}
+      - key: {{ $expression.key | quote }}
+        operator: {{ $expression.operator }}
+        {{- if $expression.values }}
+        values:
+        {{- range $value := $expression.values }}
+        - {{ $value | quote }}
+        {{- end }}
+        {{- end }}
+      {{- end }}
+    {{- end }}
+{{- end -}}
+
+{{- define "print.scheduling.node_selector" -}}
+{{- range $k, $v := . }}
+{{ $k }}: {{ $v | quote }}
+{{- end }}
+{{- end -}}
+
+{{- define "print.scheduling.tolerations" -}}
+{{- range $toleration := . }}
+- key: {{ $toleration.key | quote }}
+  operator: {{ $toleration.operator }}
+  {{- if $toleration.value }}
+  value: {{ $toleration.value | quote }}
+  {{- end }}
+  {{- if $toleration.effect }}
+  effect: {{ $toleration.effect }}
+  {{- end }}
+{{- end }}
+{{- end -}}
+
+{{- define "print.scheduling.topology_spread_constraints" -}}
+{{- range $constraint := . }}
+- labelSelector:
+    matchLabels:
+      {{- range $k, $v := $constraint.label_selector.match_labels }}
+      {{ $k }}: {{ $v | quote }}
+      {{- end }}
+  maxSkew: {{ $constraint.max_skew }}
+  topologyKey: {{ $constraint.topology_key | quote }}
+  whenUnsatisfiable: {{ $constraint.when_unsatisfiable }}
+{{- end }}
+{{- end -}}
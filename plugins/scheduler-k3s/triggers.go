@@ -24,6 +24,7 @@ import (
 	"github.com/dokku/dokku/plugins/cron"
 	nginxvhosts "github.com/dokku/dokku/plugins/nginx-vhosts"
 	"github.com/dokku/dokku/plugins/registry"
+	"github.com/dokku/dokku/plugins/storage"
 	"github.com/fatih/color"
 	"github.com/gosimple/slug"
 	"github.com/kballard/go-shellquote"
@@ -523,6 +524,14 @@ func TriggerSchedulerDeploy(scheduler string, appName string, imageTag string) e
 		return fmt.Errorf("kubernetes api not available: %w", err)
 	}
 
+	rollout, err := getRolloutInProgress(appName)
+	if err != nil {
+		return fmt.Errorf("Error checking for an in-progress rollout: %w", err)
+	}
+	if rollout != nil {
+		return fmt.Errorf("A %s rollout is in progress, run scheduler-k3s:promote or scheduler-k3s:abort before deploying", rollout.Strategy)
+	}
+
 	kedaValues, err := getKedaValues(ctx, clientset, appName)
 	if err != nil {
 		return fmt.Errorf("Error getting keda values: %w", err)
@@ -591,10 +600,40 @@ func TriggerSchedulerDeploy(scheduler string, appName string, imageTag string) e
 		})
 	}
 
+	persistentVolumes, err := getPersistentVolumes(appName)
+	if err != nil {
+		return fmt.Errorf("Error getting persistent volumes: %w", err)
+	}
+	for _, volume := range persistentVolumes {
+		values.Global.Volumes = append(values.Global.Volumes, volume.Global)
+	}
+
+	if len(values.Global.Volumes) > 0 {
+		b, err := templates.ReadFile("templates/chart/persistent-volume-claim.yaml")
+		if err != nil {
+			return fmt.Errorf("Error reading persistent-volume-claim template: %w", err)
+		}
+
+		filename := filepath.Join(chartDir, "templates", "persistent-volume-claim.yaml")
+		err = os.WriteFile(filename, b, os.FileMode(0644))
+		if err != nil {
+			return fmt.Errorf("Error writing persistent-volume-claim template: %w", err)
+		}
+
+		if os.Getenv("DOKKU_TRACE") == "1" {
+			common.CatFile(filename)
+		}
+	}
+
+	if bindMounts, err := storage.GetBindMounts(appName, "deploy"); err == nil {
+		for _, bindMount := range bindMounts {
+			common.LogWarn(fmt.Sprintf("Skipping bind mount %s as bind mounts are not supported by the k3s scheduler, use storage:create instead", bindMount))
+		}
+	}
+
 	for processType, processCount := range processes {
 		// todo: implement deployment annotations
 		// todo: implement pod annotations
-		// todo: implement volumes
 
 		healthchecks, ok := appJSON.Healthchecks[processType]
 		if !ok {
@@ -602,12 +641,17 @@ func TriggerSchedulerDeploy(scheduler string, appName string, imageTag string) e
 		}
 		processHealthchecks := getProcessHealtchecks(healthchecks, primaryPort)
 
+		processSecrets, processEnv, err := getProcessSecrets(appName, processType, env)
+		if err != nil {
+			return fmt.Errorf("Error loading environment for process type %s: %w", processType, err)
+		}
+
 		startCommand, err := getStartCommand(StartCommandInput{
 			AppName:         appName,
 			ProcessType:     processType,
 			ImageSourceType: imageSourceType,
 			Port:            primaryPort,
-			Env:             env.Map(),
+			Env:             processEnv,
 		})
 		if err != nil {
 			return fmt.Errorf("Error getting start command for deployment: %w", err)
@@ -629,6 +673,14 @@ func TriggerSchedulerDeploy(scheduler string, appName string, imageTag string) e
 			return fmt.Errorf("Error getting process labels: %w", err)
 		}
 
+		scheduling, err := getScheduling(appName, processType, map[string]string{
+			"app.kubernetes.io/name":    processType,
+			"app.kubernetes.io/part-of": appName,
+		})
+		if err != nil {
+			return fmt.Errorf("Error getting process scheduling: %w", err)
+		}
+
 		autoscaling, err := getAutoscaling(GetAutoscalingInput{
 			AppName:     appName,
 			ProcessType: processType,
@@ -639,16 +691,24 @@ func TriggerSchedulerDeploy(scheduler string, appName string, imageTag string) e
 			return fmt.Errorf("Error getting autoscaling: %w", err)
 		}
 
+		minAvailable, err := getProcessMinAvailable(appName, processType, appJSON.Formation[processType], int(processCount))
+		if err != nil {
+			return fmt.Errorf("Error getting process min available: %w", err)
+		}
+
 		processValues := ProcessValues{
 			Annotations:  annotations,
 			Autoscaling:  autoscaling,
 			Args:         args,
 			Healthchecks: processHealthchecks,
 			Labels:       labels,
+			MinAvailable: minAvailable,
 			ProcessType:  ProcessType_Worker,
 			Replicas:     int32(processCount),
 			Resources:    processResources,
-			Volumes:      processVolumes,
+			Scheduling:   scheduling,
+			Secrets:      processSecrets,
+			Volumes:      getProcessVolumes(processType, int(processCount), processVolumes, persistentVolumes),
 		}
 
 		if processType == "web" {
@@ -732,7 +792,7 @@ func TriggerSchedulerDeploy(scheduler string, appName string, imageTag string) e
 
 		values.Processes[processType] = processValues
 
-		templateFiles := []string{"deployment", "keda-scaled-object"}
+		templateFiles := []string{"deployment", "keda-scaled-object", "pod-disruption-budget"}
 		if processType == "web" {
 			templateFiles = append(templateFiles, "service", "certificate", "ingress", "ingress-route", "https-redirect-middleware", "keda-http-scaled-object", "keda-interceptor-proxy-service")
 		}
@@ -762,6 +822,16 @@ func TriggerSchedulerDeploy(scheduler string, appName string, imageTag string) e
 		return fmt.Errorf("Error listing cron jobs: %w", err)
 	}
 	for _, cronTask := range cronTasks {
+		// tasks that run after another task are run as part of that task's cron job
+		if cronTask.After != "" {
+			continue
+		}
+
+		if cronTask.HasSecondsField() {
+			common.LogWarn(fmt.Sprintf("Skipping cron task %s, kubernetes cron jobs do not support schedules with a seconds field", cronTask.ID))
+			continue
+		}
+
 		// todo: implement deployment annotations
 		// todo: implement pod annotations
 		// todo: implement volumes
@@ -789,6 +859,19 @@ func TriggerSchedulerDeploy(scheduler string, appName string, imageTag string) e
 			return fmt.Errorf("Error parsing cron task command: %w", err)
 		}
 
+		// init containers run in order and stop the job on the first failure,
+		// so every step but the last runs as an init container
+		pipeline := []ProcessCronPipelineStep{}
+		for _, step := range cron.PipelineSteps(cronTask, cronTasks)[1:] {
+			stepWords, err := shellquote.Split(step.Command)
+			if err != nil {
+				return fmt.Errorf("Error parsing cron task command: %w", err)
+			}
+
+			pipeline = append(pipeline, ProcessCronPipelineStep{Args: words})
+			words = stepWords
+		}
+
 		processResources, err := getProcessResources(appName, cronTask.ID)
 		if err != nil {
 			return fmt.Errorf("Error getting process resources: %w", err)
@@ -804,6 +887,15 @@ func TriggerSchedulerDeploy(scheduler string, appName string, imageTag string) e
 			return fmt.Errorf("Error getting process labels: %w", err)
 		}
 
+		// scheduling constraints are shared by all cron tasks via the cron process type
+		scheduling, err := getScheduling(appName, string(ProcessType_Cron), map[string]string{
+			"app.kubernetes.io/part-of": appName,
+			"dokku.com/cron-id":         cronTask.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("Error getting process scheduling: %w", err)
+		}
+
 		concurrencyPolicy := strings.ToUpper(cronTask.ConcurrencyPolicy)
 		switch concurrencyPolicy {
 		case "ALLOW":
@@ -824,11 +916,14 @@ func TriggerSchedulerDeploy(scheduler string, appName string, imageTag string) e
 				Suffix:            suffix,
 				Suspend:           cronTask.Maintenance,
 				ConcurrencyPolicy: ProcessCronConcurrencyPolicy(concurrencyPolicy),
+				TimeZone:          cronTask.Timezone,
+				Pipeline:          pipeline,
 			},
 			Labels:      labels,
 			ProcessType: ProcessType_Cron,
 			Replicas:    1,
 			Resources:   processResources,
+			Scheduling:  scheduling,
 			Volumes:     processVolumes,
 		}
 		values.Processes[cronTask.ID] = processValues
@@ -870,6 +965,16 @@ func TriggerSchedulerDeploy(scheduler string, appName string, imageTag string) e
 		common.CatFile(helpersFile)
 	}
 
+	err = getRolloutValues(ctx, GetRolloutValuesInput{
+		AppName:   appName,
+		Clientset: clientset,
+		Namespace: namespace,
+		Values:    values,
+	})
+	if err != nil {
+		return fmt.Errorf("Error configuring rollout: %w", err)
+	}
+
 	err = writeYaml(WriteYamlInput{
 		Object: values,
 		Path:   filepath.Join(chartDir, "values.yaml"),
@@ -930,6 +1035,12 @@ func TriggerSchedulerDeploy(scheduler string, appName string, imageTag string) e
 		kustomizeRootPath = getProcessSpecificKustomizeRootPath(appName)
 	}
 
+	if values.Global.Rollout != nil {
+		if err := retainRolloutResources(ctx, clientset, namespace, appName, values.Global.Rollout); err != nil {
+			return err
+		}
+	}
+
 	common.LogInfo2(fmt.Sprintf("Installing %s", appName))
 	err = helmAgent.InstallOrUpgradeChart(ctx, ChartInput{
 		ChartPath:         chartPath,
@@ -941,9 +1052,21 @@ func TriggerSchedulerDeploy(scheduler string, appName string, imageTag string) e
 		Wait:              true,
 	})
 	if err != nil {
+		if values.Global.Rollout != nil {
+			common.LogWarn("Deploy failed, restoring the previous release")
+			if abortErr := abortRollout(appName, values.Global.Rollout); abortErr != nil {
+				common.LogWarn(abortErr.Error())
+			}
+		}
 		return err
 	}
 
+	if values.Global.Rollout != nil {
+		if err := advanceRollout(ctx, appName); err != nil {
+			return err
+		}
+	}
+
 	common.LogInfo1("Running post-deploy")
 	_, err = common.CallPlugnTrigger(common.PlugnTriggerInput{
 		Args:        []string{appName, "", "", imageTag},
@@ -1723,6 +1846,11 @@ func TriggerSchedulerPostDelete(scheduler string, appName string) error {
 		common.LogWarn(fmt.Sprintf("Error deleting TLS secret for %s: %v", appName, err))
 	}
 
+	// persistent volume claims are kept by helm on uninstall, so remove them alongside the app
+	if err := deletePersistentVolumeClaims(ctx, appName, ""); err != nil {
+		common.LogWarn(fmt.Sprintf("Error deleting persistent volume claims for %s: %v", appName, err))
+	}
+
 	return nil
 }
 
@@ -1794,3 +1922,51 @@ func TriggerSchedulerStop(scheduler string, appName string) error {
 
 	return nil
 }
+
+// TriggerSchedulerStorageVolumeDestroy deletes the persistent volume claim for an app's volume
+func TriggerSchedulerStorageVolumeDestroy(scheduler string, appName string, volumeName string) error {
+	if scheduler != "k3s" {
+		return nil
+	}
+
+	if err := isKubernetesAvailable(); err != nil {
+		return fmt.Errorf("kubernetes api not available: %w", err)
+	}
+
+	return deletePersistentVolumeClaims(context.Background(), appName, volumeName)
+}
+
+// TriggerSchedulerStorageVolumeStatus outputs the binding status of the persistent volume claims for an app
+func TriggerSchedulerStorageVolumeStatus(scheduler string, appName string) error {
+	if scheduler != "k3s" {
+		return nil
+	}
+
+	clientset, err := NewKubernetesClient()
+	if err != nil {
+		return fmt.Errorf("Error creating kubernetes client: %w", err)
+	}
+
+	if err := clientset.Ping(); err != nil {
+		return fmt.Errorf("kubernetes api not available: %w", err)
+	}
+
+	claims, err := clientset.ListPersistentVolumeClaims(context.Background(), ListPersistentVolumeClaimsInput{
+		Namespace:     getComputedNamespace(appName),
+		LabelSelector: fmt.Sprintf("app.kubernetes.io/part-of=%s", appName),
+	})
+	if err != nil {
+		return fmt.Errorf("Error listing persistent volume claims: %w", err)
+	}
+
+	for _, claim := range claims {
+		volumeName, ok := claim.Labels["dokku.com/volume-name"]
+		if !ok {
+			continue
+		}
+
+		fmt.Printf("%s %s\n", volumeName, claim.Status.Phase)
+	}
+
+	return nil
+}
@@ -11,6 +11,8 @@ func ReportSingleApp(appName string, format string, infoFlag string) error {
 		"--storage-build-mounts":  reportBuildMounts,
 		"--storage-deploy-mounts": reportDeployMounts,
 		"--storage-run-mounts":    reportRunMounts,
+		"--storage-volumes":       reportVolumes,
+		"--storage-volume-status": reportVolumeStatus,
 	}
 
 	flagKeys := []string{}
@@ -35,3 +37,11 @@ func reportDeployMounts(appName string) string {
 func reportRunMounts(appName string) string {
 	return GetBindMountsForDisplay(appName, "run")
 }
+
+func reportVolumes(appName string) string {
+	return GetVolumesForDisplay(appName)
+}
+
+func reportVolumeStatus(appName string) string {
+	return GetVolumeStatusForDisplay(appName)
+}
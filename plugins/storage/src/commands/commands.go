@@ -18,6 +18,8 @@ Manage mounted volumes
 Additional commands:`
 
 	helpContent = `
+    storage:create <app> <name> <container-path> [--size <size>] [--access-mode <mode>] [--storage-class <class>] [--process-type <types>], Create a persistent volume for an app
+    storage:destroy <app> <name> [--force], Destroy a persistent volume for an app
     storage:ensure-directory [--chown option] <directory>, Creates a persistent storage directory in the recommended storage path
     storage:list <app> [--format text|json], List bind mounts for app's container(s) (host:container)
     storage:mount <app> <host-dir:container-dir>, Create a new bind mount
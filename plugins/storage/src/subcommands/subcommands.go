@@ -19,6 +19,24 @@ func main() {
 	switch subcommand {
 	case "default":
 		err = storage.CommandHelp()
+	case "create":
+		args := flag.NewFlagSet("storage:create", flag.ExitOnError)
+		size := args.String("size", storage.DefaultVolumeSize, "--size: the size of the volume")
+		accessMode := args.String("access-mode", storage.DefaultVolumeAccessMode, "--access-mode: the access mode of the volume")
+		storageClass := args.String("storage-class", "", "--storage-class: the storage class used to provision the volume")
+		processTypes := args.String("process-type", "", "--process-type: a comma-separated list of process types to mount the volume into")
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		name := args.Arg(1)
+		containerPath := args.Arg(2)
+		err = storage.CommandCreate(appName, name, containerPath, *size, *accessMode, *storageClass, *processTypes)
+	case "destroy":
+		args := flag.NewFlagSet("storage:destroy", flag.ExitOnError)
+		force := args.Bool("force", false, "--force: force destroy without confirmation")
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		name := args.Arg(1)
+		err = storage.CommandDestroy(appName, name, *force)
 	case "ensure-directory":
 		args := flag.NewFlagSet("storage:ensure-directory", flag.ExitOnError)
 		chown := args.String("chown", "herokuish", "--chown: chown option (herokuish, heroku, paketo, root, false)")
@@ -19,6 +19,17 @@ func main() {
 	switch trigger {
 	case "install":
 		err = storage.TriggerInstall()
+	case "post-app-clone-setup":
+		oldAppName := flag.Arg(0)
+		newAppName := flag.Arg(1)
+		err = storage.TriggerPostAppCloneSetup(oldAppName, newAppName)
+	case "post-app-rename-setup":
+		oldAppName := flag.Arg(0)
+		newAppName := flag.Arg(1)
+		err = storage.TriggerPostAppRenameSetup(oldAppName, newAppName)
+	case "post-delete":
+		appName := flag.Arg(0)
+		err = storage.TriggerPostDelete(appName)
 	case "storage-list":
 		appName := flag.Arg(0)
 		phase := flag.Arg(1)
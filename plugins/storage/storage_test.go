@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"strings"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -88,3 +89,58 @@ func TestGetStorageDirectory(t *testing.T) {
 	dir := GetStorageDirectory()
 	Expect(dir).To(ContainSubstring("data/storage"))
 }
+
+func TestValidateVolumeName(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(ValidateVolumeName("data")).To(Succeed())
+	Expect(ValidateVolumeName("uploads-1")).To(Succeed())
+	Expect(ValidateVolumeName("a")).To(Succeed())
+
+	Expect(ValidateVolumeName("")).NotTo(Succeed())
+	Expect(ValidateVolumeName("Data")).NotTo(Succeed())
+	Expect(ValidateVolumeName("-data")).NotTo(Succeed())
+	Expect(ValidateVolumeName("data-")).NotTo(Succeed())
+	Expect(ValidateVolumeName("my_data")).NotTo(Succeed())
+	Expect(ValidateVolumeName(strings.Repeat("a", 41))).NotTo(Succeed())
+}
+
+func TestValidateVolumeSize(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(ValidateVolumeSize("1Gi")).To(Succeed())
+	Expect(ValidateVolumeSize("512Mi")).To(Succeed())
+	Expect(ValidateVolumeSize("10G")).To(Succeed())
+	Expect(ValidateVolumeSize("1073741824")).To(Succeed())
+
+	Expect(ValidateVolumeSize("")).NotTo(Succeed())
+	Expect(ValidateVolumeSize("0Gi")).NotTo(Succeed())
+	Expect(ValidateVolumeSize("1.5Gi")).NotTo(Succeed())
+	Expect(ValidateVolumeSize("1GB")).NotTo(Succeed())
+}
+
+func TestValidateVolumeAccessMode(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(ValidateVolumeAccessMode("ReadWriteOnce")).To(Succeed())
+	Expect(ValidateVolumeAccessMode("ReadWriteMany")).To(Succeed())
+
+	err := ValidateVolumeAccessMode("ReadWrite")
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("supported access modes"))
+}
+
+func TestParseVolumeProcessTypes(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(ParseVolumeProcessTypes("")).To(Equal([]string{}))
+	Expect(ParseVolumeProcessTypes("worker, web,web")).To(Equal([]string{"web", "worker"}))
+}
+
+func TestVolumeMountedInto(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(Volume{}.MountedInto("web")).To(BeTrue())
+	Expect(Volume{ProcessTypes: []string{"worker"}}.MountedInto("worker")).To(BeTrue())
+	Expect(Volume{ProcessTypes: []string{"worker"}}.MountedInto("web")).To(BeFalse())
+}
@@ -19,6 +19,8 @@ Manage mounted volumes
 Additional commands:`
 
 	helpContent = `
+    storage:create <app> <name> <container-path> [--size <size>] [--access-mode <mode>] [--storage-class <class>] [--process-type <types>], Create a persistent volume for an app
+    storage:destroy <app> <name> [--force], Destroy a persistent volume for an app
     storage:ensure-directory [--chown option] <directory>, Creates a persistent storage directory in the recommended storage path
     storage:list <app> [--format text|json], List bind mounts for app's container(s) (host:container)
     storage:mount <app> <host-dir:container-dir>, Create a new bind mount
@@ -32,6 +34,118 @@ func CommandHelp() error {
 	return nil
 }
 
+// CommandCreate creates a persistent volume for an app
+func CommandCreate(appName string, name string, containerPath string, size string, accessMode string, storageClass string, processTypes string) error {
+	if err := common.VerifyAppName(appName); err != nil {
+		return err
+	}
+
+	if err := ValidateVolumeName(name); err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(containerPath, "/") || strings.Contains(containerPath, ":") {
+		return errors.New("Container path must be an absolute path")
+	}
+
+	if size == "" {
+		size = DefaultVolumeSize
+	}
+	if err := ValidateVolumeSize(size); err != nil {
+		return err
+	}
+
+	if accessMode == "" {
+		accessMode = DefaultVolumeAccessMode
+	}
+	if err := ValidateVolumeAccessMode(accessMode); err != nil {
+		return err
+	}
+
+	volumes, err := FetchVolumes(appName)
+	if err != nil {
+		return err
+	}
+
+	for _, volume := range volumes {
+		if volume.Name == name {
+			return fmt.Errorf("Volume %s already exists", name)
+		}
+		if volume.ContainerPath == containerPath {
+			return fmt.Errorf("Volume %s is already mounted at %s", volume.Name, containerPath)
+		}
+	}
+
+	volume := Volume{
+		Name:          name,
+		ContainerPath: containerPath,
+		Size:          size,
+		AccessMode:    accessMode,
+		StorageClass:  storageClass,
+		ProcessTypes:  ParseVolumeProcessTypes(processTypes),
+	}
+	if err := WriteVolume(appName, volume); err != nil {
+		return err
+	}
+
+	common.LogInfo1(fmt.Sprintf("Created volume %s", name))
+	common.LogVerbose(fmt.Sprintf("Mounted at %s with %s of %s storage", containerPath, size, accessMode))
+	if scheduler := common.GetAppScheduler(appName); scheduler != "k3s" {
+		common.LogWarn(fmt.Sprintf("Persistent volumes are only supported by the k3s scheduler, and will not be mounted by the %s scheduler", scheduler))
+	} else {
+		common.LogVerbose("The volume will be provisioned on the next deploy")
+	}
+
+	return nil
+}
+
+// CommandDestroy destroys a persistent volume for an app
+func CommandDestroy(appName string, name string, forceDestroy bool) error {
+	if err := common.VerifyAppName(appName); err != nil {
+		return err
+	}
+
+	if err := ValidateVolumeName(name); err != nil {
+		return err
+	}
+
+	if _, ok, err := FetchVolume(appName, name); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("Volume %s does not exist", name)
+	}
+
+	if os.Getenv("DOKKU_APPS_FORCE_DELETE") == "1" {
+		forceDestroy = true
+	}
+
+	if !forceDestroy {
+		if err := common.AskForDestructiveConfirmation(name, "volume"); err != nil {
+			return err
+		}
+	}
+
+	_, err := common.CallPlugnTrigger(common.PlugnTriggerInput{
+		Trigger:     "scheduler-storage-volume-destroy",
+		Args:        []string{common.GetAppScheduler(appName), appName, name},
+		StreamStdio: true,
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to destroy volume data: %w", err)
+	}
+
+	if err := DeleteVolume(appName, name); err != nil {
+		return fmt.Errorf("Unable to destroy volume: %w", err)
+	}
+
+	common.LogInfo1(fmt.Sprintf("Destroyed volume %s", name))
+	if common.GetAppScheduler(appName) == "k3s" {
+		common.LogVerbose("The volume will be unmounted on the next deploy")
+	}
+
+	return nil
+}
+
 // CommandEnsureDirectory creates a persistent storage directory
 func CommandEnsureDirectory(directory string, chownFlag string) error {
 	if err := ValidateDirectoryName(directory); err != nil {
@@ -25,6 +25,10 @@ func TriggerInstall() error {
 		return fmt.Errorf("Unable to set storage directory permissions: %s", err.Error())
 	}
 
+	if err := common.PropertySetup("storage"); err != nil {
+		return fmt.Errorf("Unable to install the storage plugin: %s", err.Error())
+	}
+
 	distro := detectDistro()
 	if distro == "" {
 		return nil
@@ -65,6 +69,25 @@ func detectDistro() string {
 	return ""
 }
 
+// TriggerPostAppCloneSetup copies persistent volumes to the new app
+func TriggerPostAppCloneSetup(oldAppName string, newAppName string) error {
+	return common.PropertyClone("storage", oldAppName, newAppName)
+}
+
+// TriggerPostAppRenameSetup moves persistent volumes to the renamed app
+func TriggerPostAppRenameSetup(oldAppName string, newAppName string) error {
+	if err := common.PropertyClone("storage", oldAppName, newAppName); err != nil {
+		return err
+	}
+
+	return common.PropertyDestroy("storage", oldAppName)
+}
+
+// TriggerPostDelete destroys the persistent volume settings for an app
+func TriggerPostDelete(appName string) error {
+	return common.PropertyDestroy("storage", appName)
+}
+
 // TriggerStorageList outputs storage mounts for an app
 func TriggerStorageList(appName string, phase string, format string) error {
 	mounts, err := GetBindMounts(appName, phase)
@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dokku/dokku/plugins/common"
+)
+
+// DefaultVolumeAccessMode is the access mode used when none is specified
+const DefaultVolumeAccessMode = "ReadWriteOnce"
+
+// DefaultVolumeSize is the size used when none is specified
+const DefaultVolumeSize = "1Gi"
+
+// VolumeAccessModes are the supported persistent volume access modes
+var VolumeAccessModes = []string{"ReadOnlyMany", "ReadWriteMany", "ReadWriteOnce", "ReadWriteOncePod"}
+
+// Volume is a persistent volume that is mounted into the containers of an app
+type Volume struct {
+	// Name is the name of the volume
+	Name string `json:"name"`
+
+	// ContainerPath is the path the volume is mounted at in the container
+	ContainerPath string `json:"container_path"`
+
+	// Size is the requested size of the volume, such as 10Gi
+	Size string `json:"size"`
+
+	// AccessMode is the access mode of the volume
+	AccessMode string `json:"access_mode"`
+
+	// StorageClass is the storage class used to provision the volume, defaulting to the scheduler's storage class
+	StorageClass string `json:"storage_class,omitempty"`
+
+	// ProcessTypes is the list of process types the volume is mounted into, with an empty list meaning all process types
+	ProcessTypes []string `json:"process_types,omitempty"`
+}
+
+// MountedInto returns whether the volume is mounted into a process type
+func (v Volume) MountedInto(processType string) bool {
+	if len(v.ProcessTypes) == 0 {
+		return true
+	}
+
+	for _, p := range v.ProcessTypes {
+		if p == processType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getVolumeProperty(name string) string {
+	return fmt.Sprintf("volume-%s.json", name)
+}
+
+// ValidateVolumeName validates a persistent volume name
+func ValidateVolumeName(name string) error {
+	if name == "" {
+		return errors.New("Please specify a volume name")
+	}
+
+	if len(name) > 40 {
+		return errors.New("Volume name must be 40 characters or less")
+	}
+
+	matched, err := regexp.MatchString(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`, name)
+	if err != nil {
+		return err
+	}
+	if !matched {
+		return errors.New("Volume name may only contain lowercase alphanumeric characters or '-', and must start and end with an alphanumeric character")
+	}
+	return nil
+}
+
+// ValidateVolumeSize validates a persistent volume size
+func ValidateVolumeSize(size string) error {
+	matched, err := regexp.MatchString(`^[1-9][0-9]*(Ki|Mi|Gi|Ti|Pi|Ei|k|M|G|T|P|E)?$`, size)
+	if err != nil {
+		return err
+	}
+	if !matched {
+		return fmt.Errorf("Invalid volume size %s, must be a quantity such as 512Mi or 10Gi", size)
+	}
+	return nil
+}
+
+// ValidateVolumeAccessMode validates a persistent volume access mode
+func ValidateVolumeAccessMode(accessMode string) error {
+	for _, mode := range VolumeAccessModes {
+		if mode == accessMode {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Invalid access mode %s, supported access modes: %s", accessMode, strings.Join(VolumeAccessModes, ", "))
+}
+
+// ParseVolumeProcessTypes parses a comma-separated list of process types
+func ParseVolumeProcessTypes(value string) []string {
+	processTypes := []string{}
+	found := map[string]bool{}
+	for _, processType := range strings.Split(value, ",") {
+		processType = strings.TrimSpace(processType)
+		if processType == "" || found[processType] {
+			continue
+		}
+
+		found[processType] = true
+		processTypes = append(processTypes, processType)
+	}
+
+	sort.Strings(processTypes)
+	return processTypes
+}
+
+// FetchVolume returns a persistent volume for an app
+func FetchVolume(appName string, name string) (Volume, bool, error) {
+	property := getVolumeProperty(name)
+	if !common.PropertyExists("storage", appName, property) {
+		return Volume{}, false, nil
+	}
+
+	var volume Volume
+	data := common.PropertyGet("storage", appName, property)
+	if err := json.Unmarshal([]byte(data), &volume); err != nil {
+		return Volume{}, false, fmt.Errorf("Unable to unmarshal volume %s: %w", name, err)
+	}
+
+	return volume, true, nil
+}
+
+// FetchVolumes returns the persistent volumes for an app, sorted by name
+func FetchVolumes(appName string) ([]Volume, error) {
+	volumes := []Volume{}
+	properties, err := common.PropertyGetAllByPrefix("storage", appName, "volume-")
+	if err != nil {
+		return volumes, fmt.Errorf("Unable to get volumes: %w", err)
+	}
+
+	for property, data := range properties {
+		if !strings.HasSuffix(property, ".json") {
+			continue
+		}
+
+		var volume Volume
+		if err := json.Unmarshal([]byte(data), &volume); err != nil {
+			return volumes, fmt.Errorf("Unable to unmarshal volume %s: %w", property, err)
+		}
+
+		volumes = append(volumes, volume)
+	}
+
+	sort.SliceStable(volumes, func(i, j int) bool {
+		return volumes[i].Name < volumes[j].Name
+	})
+
+	return volumes, nil
+}
+
+// WriteVolume stores a persistent volume for an app
+func WriteVolume(appName string, volume Volume) error {
+	b, err := json.Marshal(volume)
+	if err != nil {
+		return fmt.Errorf("Unable to marshal volume to json: %w", err)
+	}
+
+	if err := common.PropertyWrite("storage", appName, getVolumeProperty(volume.Name), string(b)); err != nil {
+		return fmt.Errorf("Unable to write volume: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteVolume removes a persistent volume from an app
+func DeleteVolume(appName string, name string) error {
+	return common.PropertyDelete("storage", appName, getVolumeProperty(name))
+}
+
+// GetVolumesForDisplay returns the persistent volumes formatted for display
+func GetVolumesForDisplay(appName string) string {
+	volumes, err := FetchVolumes(appName)
+	if err != nil {
+		return ""
+	}
+
+	result := []string{}
+	for _, volume := range volumes {
+		result = append(result, fmt.Sprintf("%s:%s:%s:%s", volume.Name, volume.ContainerPath, volume.Size, volume.AccessMode))
+	}
+	return strings.Join(result, " ")
+}
+
+// GetVolumeStatusForDisplay returns the status of the persistent volumes as reported by the app's scheduler
+func GetVolumeStatusForDisplay(appName string) string {
+	volumes, err := FetchVolumes(appName)
+	if err != nil || len(volumes) == 0 {
+		return ""
+	}
+
+	results, err := common.CallPlugnTrigger(common.PlugnTriggerInput{
+		Trigger: "scheduler-storage-volume-status",
+		Args:    []string{common.GetAppScheduler(appName), appName},
+	})
+	if err != nil {
+		return ""
+	}
+
+	statuses := map[string]string{}
+	for _, line := range strings.Split(results.StdoutContents(), "\n") {
+		parts := strings.Fields(line)
+		if len(parts) == 2 {
+			statuses[parts[0]] = parts[1]
+		}
+	}
+
+	result := []string{}
+	for _, volume := range volumes {
+		status, ok := statuses[volume.Name]
+		if !ok {
+			status = "Unknown"
+		}
+		result = append(result, fmt.Sprintf("%s:%s", volume.Name, status))
+	}
+	return strings.Join(result, " ")
+}
@@ -0,0 +1,305 @@
+package webhooks
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/dokku/dokku/plugins/common"
+)
+
+// maxDeliveryRecords is the number of delivery attempts kept in the delivery log
+const maxDeliveryRecords = 1000
+
+// Payload is the json body posted to a webhook
+type Payload struct {
+	// ID uniquely identifies the payload, and is shared by all delivery attempts for it
+	ID string `json:"id"`
+
+	// Event is the name of the event, such as deploy.succeeded
+	Event string `json:"event"`
+
+	// App is the app the event occurred for
+	App string `json:"app"`
+
+	// ImageTag is the tag of the image being deployed
+	ImageTag string `json:"image_tag,omitempty"`
+
+	// GitRev is the git revision of the app, as returned by the git-revision trigger
+	GitRev string `json:"git_rev,omitempty"`
+
+	// DeploySource is the source of the last deploy, such as git-push or docker-image
+	DeploySource string `json:"deploy_source,omitempty"`
+
+	// DeploySourceMetadata contains details about the deploy source, such as the git sha or image name
+	DeploySourceMetadata string `json:"deploy_source_metadata,omitempty"`
+
+	// Phase is the phase a deploy failed in, either build or deploy
+	Phase string `json:"phase,omitempty"`
+
+	// Timestamp is the time the event occurred
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Delivery is a single attempt to deliver a payload to a webhook
+type Delivery struct {
+	// PayloadID is the id of the delivered payload
+	PayloadID string `json:"payload_id"`
+
+	// Scope is the app the webhook is configured for, or --global
+	Scope string `json:"scope"`
+
+	// WebhookID is the id of the webhook
+	WebhookID string `json:"webhook_id"`
+
+	// URL is the url of the webhook
+	URL string `json:"url"`
+
+	// Event is the name of the delivered event
+	Event string `json:"event"`
+
+	// App is the app the event occurred for
+	App string `json:"app"`
+
+	// Attempt is the attempt number, starting at 1
+	Attempt int `json:"attempt"`
+
+	// StatusCode is the http status code returned by the webhook, if any
+	StatusCode int `json:"status_code,omitempty"`
+
+	// Error is the reason the attempt failed, if it did
+	Error string `json:"error,omitempty"`
+
+	// Duration is how long the attempt took
+	Duration time.Duration `json:"duration"`
+
+	// Timestamp is the time the attempt was made
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Succeeded returns whether the delivery attempt succeeded
+func (d Delivery) Succeeded() bool {
+	return d.Error == ""
+}
+
+func getDeliveryLogPath() string {
+	return filepath.Join(common.GetDataDirectory("webhooks"), "deliveries.jsonl")
+}
+
+// getDeliveryQueue returns the queue of payloads waiting to be redelivered, recording every attempt in the delivery log
+func getDeliveryQueue() common.DeliveryQueue {
+	return common.DeliveryQueue{
+		Directory: filepath.Join(common.GetDataDirectory("webhooks"), "queue"),
+		OnAttempt: recordAttempt,
+	}
+}
+
+// newPayload creates a payload for an event, filling in the git revision and deploy source of the app
+func newPayload(event string, appName string, imageTag string) Payload {
+	now := time.Now().UTC()
+	id := strconv.FormatInt(now.UnixNano(), 10)
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err == nil {
+		id = fmt.Sprintf("%s-%s", id, hex.EncodeToString(b))
+	}
+
+	payload := Payload{
+		ID:        id,
+		Event:     event,
+		App:       appName,
+		ImageTag:  imageTag,
+		Timestamp: now,
+	}
+
+	if common.IsValidAppName(appName) != nil || !common.DirectoryExists(common.AppRoot(appName)) {
+		return payload
+	}
+
+	results, _ := common.CallPlugnTrigger(common.PlugnTriggerInput{
+		Trigger: "git-revision",
+		Args:    []string{appName},
+	})
+	payload.GitRev = results.StdoutContents()
+	payload.DeploySource = common.PropertyGet("apps", appName, "deploy-source")
+	payload.DeploySourceMetadata = common.PropertyGet("apps", appName, "deploy-source-metadata")
+	return payload
+}
+
+// signPayload returns the hex-encoded hmac-sha256 signature of a payload body
+func signPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newDelivery creates a signed delivery of a payload to a webhook
+func newDelivery(scope string, webhook Webhook, payload Payload) (common.QueuedDelivery, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return common.QueuedDelivery{}, err
+	}
+
+	return common.QueuedDelivery{
+		ID:          fmt.Sprintf("%s-%s", payload.ID, webhook.ID),
+		Description: fmt.Sprintf("%s webhook to %s (%s)", payload.Event, webhook.ID, scope),
+		Request: common.DeliveryRequest{
+			URL: webhook.URL,
+			Headers: map[string]string{
+				"User-Agent":        "dokku-webhooks",
+				"X-Dokku-Event":     payload.Event,
+				"X-Dokku-Delivery":  payload.ID,
+				"X-Dokku-Signature": "sha256=" + signPayload(webhook.Secret, body),
+			},
+			Body: body,
+		},
+		Metadata: map[string]string{
+			"app":        payload.App,
+			"event":      payload.Event,
+			"payload_id": payload.ID,
+			"scope":      scope,
+			"webhook_id": webhook.ID,
+		},
+	}, nil
+}
+
+// recordAttempt records a delivery attempt in the delivery log
+func recordAttempt(delivery common.QueuedDelivery, result common.DeliveryResult) {
+	record := Delivery{
+		PayloadID:  delivery.Metadata["payload_id"],
+		Scope:      delivery.Metadata["scope"],
+		WebhookID:  delivery.Metadata["webhook_id"],
+		URL:        delivery.Request.URL,
+		Event:      delivery.Metadata["event"],
+		App:        delivery.Metadata["app"],
+		Attempt:    delivery.Attempts + 1,
+		StatusCode: result.StatusCode,
+		Duration:   result.Duration,
+		Timestamp:  result.Timestamp,
+	}
+	if result.Error != nil {
+		record.Error = result.Error.Error()
+	}
+
+	if err := recordDelivery(record); err != nil {
+		common.LogWarn(fmt.Sprintf("Unable to record webhook delivery: %s", err.Error()))
+	}
+}
+
+// dispatchEvent delivers an event to the app and global webhooks subscribed to it, queueing failed deliveries for a retry
+func dispatchEvent(event string, appName string, imageTag string, phase string) error {
+	type target struct {
+		scope   string
+		webhook Webhook
+	}
+
+	targets := []target{}
+	for _, scope := range []string{appName, "--global"} {
+		webhooks, err := FetchWebhooks(scope)
+		if err != nil {
+			return err
+		}
+
+		for _, webhook := range webhooks {
+			if webhook.Subscribes(event) {
+				targets = append(targets, target{scope: scope, webhook: webhook})
+			}
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil
+	}
+
+	payload := newPayload(event, appName, imageTag)
+	payload.Phase = phase
+	queue := getDeliveryQueue()
+	for _, t := range targets {
+		delivery, err := newDelivery(t.scope, t.webhook, payload)
+		if err != nil {
+			return err
+		}
+
+		if err := queue.Send(delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeQueuedDeliveries removes the queued deliveries for a webhook
+func removeQueuedDeliveries(scope string, webhookID string) error {
+	return getDeliveryQueue().Remove(func(delivery common.QueuedDelivery) bool {
+		return delivery.Metadata["scope"] == scope && delivery.Metadata["webhook_id"] == webhookID
+	})
+}
+
+// DeliverQueuedPayloads retries the queued deliveries that are due
+func DeliverQueuedPayloads() error {
+	return getDeliveryQueue().Flush()
+}
+
+// readDeliveries returns every delivery attempt in the delivery log, oldest first
+func readDeliveries() ([]Delivery, error) {
+	deliveries := []Delivery{}
+	f, err := os.Open(getDeliveryLogPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return deliveries, nil
+	}
+	if err != nil {
+		return deliveries, fmt.Errorf("Unable to open webhook delivery log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var delivery Delivery
+		if err := json.Unmarshal(scanner.Bytes(), &delivery); err != nil {
+			continue
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, scanner.Err()
+}
+
+// recordDelivery appends a delivery attempt to the delivery log, discarding the oldest attempts past the limit
+func recordDelivery(delivery Delivery) error {
+	if err := common.CreateDataDirectory("webhooks"); err != nil {
+		return err
+	}
+
+	return common.AppendJSONLine(getDeliveryLogPath(), delivery, maxDeliveryRecords)
+}
+
+// FetchDeliveries returns the most recent delivery attempts for webhooks configured for an app, or globally via --global, newest first
+func FetchDeliveries(appName string, limit int) ([]Delivery, error) {
+	deliveries, err := readDeliveries()
+	if err != nil {
+		return deliveries, err
+	}
+
+	matched := []Delivery{}
+	for i := len(deliveries) - 1; i >= 0; i-- {
+		if deliveries[i].Scope != appName {
+			continue
+		}
+
+		matched = append(matched, deliveries[i])
+		if limit > 0 && len(matched) >= limit {
+			break
+		}
+	}
+
+	return matched, nil
+}
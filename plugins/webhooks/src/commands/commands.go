@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dokku/dokku/plugins/common"
+)
+
+const (
+	helpHeader = `Usage: dokku webhooks[:COMMAND]
+
+Manage outgoing webhooks for app events
+
+Additional commands:`
+
+	helpContent = `
+    webhooks:add <app>|--global <url> [--events <events>] [--secret <secret>], Add a webhook for an app or globally
+    webhooks:deliveries <app>|--global [--num <num>] [--format json|stdout], Display recent webhook delivery attempts
+    webhooks:list <app>|--global [--format json|stdout], List webhooks for an app or globally
+    webhooks:remove <app>|--global <id>|<url>, Remove a webhook for an app or globally`
+)
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	cmd := flag.Arg(0)
+	switch cmd {
+	case "webhooks", "webhooks:help":
+		usage()
+	case "help":
+		result, err := common.CallExecCommand(common.ExecCommandInput{
+			Command: "ps",
+			Args:    []string{"-o", "command=", strconv.Itoa(os.Getppid())},
+		})
+		if err == nil && strings.Contains(result.StdoutContents(), "--all") {
+			fmt.Println(helpContent)
+		} else {
+			fmt.Print("\n    webhooks, Manage outgoing webhooks for app events\n")
+		}
+	default:
+		dokkuNotImplementExitCode, err := strconv.Atoi(os.Getenv("DOKKU_NOT_IMPLEMENTED_EXIT"))
+		if err != nil {
+			fmt.Println("failed to retrieve DOKKU_NOT_IMPLEMENTED_EXIT environment variable")
+			dokkuNotImplementExitCode = 10
+		}
+		os.Exit(dokkuNotImplementExitCode)
+	}
+}
+
+func usage() {
+	common.CommandUsage(helpHeader, helpContent)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dokku/dokku/plugins/common"
+	"github.com/dokku/dokku/plugins/webhooks"
+
+	flag "github.com/spf13/pflag"
+)
+
+// main entrypoint to all subcommands
+func main() {
+	parts := strings.Split(os.Args[0], "/")
+	subcommand := parts[len(parts)-1]
+
+	var err error
+	switch subcommand {
+	case "add":
+		args := flag.NewFlagSet("webhooks:add", flag.ExitOnError)
+		global := args.Bool("global", false, "--global: add a global webhook")
+		events := args.String("events", "", "--events: comma-separated list of events to send to the webhook")
+		secret := args.String("secret", "", "--secret: the secret used to sign payloads, generated if not specified")
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		webhookURL := args.Arg(1)
+		if *global {
+			appName = "--global"
+			webhookURL = args.Arg(0)
+		}
+		err = webhooks.CommandAdd(appName, webhookURL, *events, *secret)
+	case "deliver":
+		args := flag.NewFlagSet("webhooks:deliver", flag.ExitOnError)
+		args.Parse(os.Args[2:])
+		err = webhooks.CommandDeliver()
+	case "deliveries":
+		args := flag.NewFlagSet("webhooks:deliveries", flag.ExitOnError)
+		global := args.Bool("global", false, "--global: list deliveries for global webhooks")
+		num := args.Int("num", 20, "--num: the number of deliveries to display")
+		format := args.String("format", "stdout", "format: [ stdout | json ]")
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		if *global {
+			appName = "--global"
+		}
+		err = webhooks.CommandDeliveries(appName, *num, *format)
+	case "list":
+		args := flag.NewFlagSet("webhooks:list", flag.ExitOnError)
+		global := args.Bool("global", false, "--global: list global webhooks")
+		format := args.String("format", "stdout", "format: [ stdout | json ]")
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		if *global {
+			appName = "--global"
+		}
+		err = webhooks.CommandList(appName, *format)
+	case "remove":
+		args := flag.NewFlagSet("webhooks:remove", flag.ExitOnError)
+		global := args.Bool("global", false, "--global: remove a global webhook")
+		args.Parse(os.Args[2:])
+		appName := args.Arg(0)
+		idOrURL := args.Arg(1)
+		if *global {
+			appName = "--global"
+			idOrURL = args.Arg(0)
+		}
+		err = webhooks.CommandRemove(appName, idOrURL)
+	default:
+		err = fmt.Errorf("Invalid plugin subcommand call: %s", subcommand)
+	}
+
+	if err != nil {
+		common.LogFailWithError(err)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dokku/dokku/plugins/common"
+	"github.com/dokku/dokku/plugins/webhooks"
+)
+
+// main entrypoint to all triggers
+func main() {
+	parts := strings.Split(os.Args[0], "/")
+	trigger := parts[len(parts)-1]
+	flag.Parse()
+
+	var err error
+	switch trigger {
+	case "cron-entries":
+		scheduler := flag.Arg(0)
+		err = webhooks.TriggerCronEntries(scheduler)
+	case "deploy-failed":
+		appName := flag.Arg(0)
+		imageTag := flag.Arg(1)
+		phase := flag.Arg(2)
+		err = webhooks.TriggerDeployFailed(appName, imageTag, phase)
+	case "install":
+		err = webhooks.TriggerInstall()
+	case "post-app-clone-setup":
+		oldAppName := flag.Arg(0)
+		newAppName := flag.Arg(1)
+		err = webhooks.TriggerPostAppCloneSetup(oldAppName, newAppName)
+	case "post-app-rename-setup":
+		oldAppName := flag.Arg(0)
+		newAppName := flag.Arg(1)
+		err = webhooks.TriggerPostAppRenameSetup(oldAppName, newAppName)
+	case "post-create":
+		appName := flag.Arg(0)
+		err = webhooks.TriggerPostCreate(appName)
+	case "post-delete":
+		appName := flag.Arg(0)
+		err = webhooks.TriggerPostDelete(appName)
+	case "post-deploy":
+		appName := flag.Arg(0)
+		imageTag := flag.Arg(3)
+		err = webhooks.TriggerPostDeploy(appName, imageTag)
+	case "scheduler-deploy":
+		scheduler := flag.Arg(0)
+		appName := flag.Arg(1)
+		imageTag := flag.Arg(2)
+		err = webhooks.TriggerSchedulerDeploy(scheduler, appName, imageTag)
+	default:
+		err = fmt.Errorf("Invalid plugin trigger call: %s", trigger)
+	}
+
+	if err != nil {
+		common.LogFailWithError(err)
+	}
+}
@@ -0,0 +1,213 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dokku/dokku/plugins/common"
+	"github.com/ryanuber/columnize"
+)
+
+// CommandAdd adds a webhook for an app or globally
+func CommandAdd(appName string, webhookURL string, events string, secret string) error {
+	if appName != "--global" {
+		if err := common.VerifyAppName(appName); err != nil {
+			return err
+		}
+	}
+
+	if err := validateWebhookURL(webhookURL); err != nil {
+		return err
+	}
+
+	subscribedEvents, err := parseEvents(events)
+	if err != nil {
+		return err
+	}
+
+	webhookID := getWebhookID(webhookURL)
+	if common.PropertyExists("webhooks", appName, getWebhookProperty(webhookID)) {
+		return fmt.Errorf("Webhook for %s already exists, remove it before adding it again", webhookURL)
+	}
+
+	generatedSecret := secret == ""
+	if generatedSecret {
+		secret, err = generateSecret()
+		if err != nil {
+			return err
+		}
+	}
+
+	webhook := Webhook{
+		ID:     webhookID,
+		URL:    webhookURL,
+		Events: subscribedEvents,
+		Secret: secret,
+	}
+
+	b, err := json.Marshal(webhook)
+	if err != nil {
+		return fmt.Errorf("Unable to marshal webhook to json: %w", err)
+	}
+
+	if err := common.PropertyWrite("webhooks", appName, getWebhookProperty(webhookID), string(b)); err != nil {
+		return fmt.Errorf("Unable to write webhook: %w", err)
+	}
+
+	common.LogInfo1(fmt.Sprintf("Webhook %s added", webhookID))
+	common.LogVerbose(fmt.Sprintf("Events: %s", strings.Join(subscribedEvents, ", ")))
+	if generatedSecret {
+		common.LogVerbose(fmt.Sprintf("Signing secret: %s", secret))
+	}
+
+	return writeCronEntries()
+}
+
+// CommandDeliver retries the queued webhook deliveries that are due
+func CommandDeliver() error {
+	return DeliverQueuedPayloads()
+}
+
+// CommandDeliveries displays the most recent delivery attempts for the webhooks of an app or global webhooks
+func CommandDeliveries(appName string, limit int, format string) error {
+	if format == "" {
+		format = "stdout"
+	}
+
+	if format != "stdout" && format != "json" {
+		return fmt.Errorf("Invalid format specified, supported formats: json, stdout")
+	}
+
+	if appName != "--global" {
+		if err := common.VerifyAppName(appName); err != nil {
+			return err
+		}
+	}
+
+	deliveries, err := FetchDeliveries(appName, limit)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		b, err := json.Marshal(deliveries)
+		if err != nil {
+			return fmt.Errorf("Unable to marshal json: %w", err)
+		}
+
+		fmt.Println(string(b))
+		return nil
+	}
+
+	lines := []string{"Timestamp | Webhook | Event | App | Attempt | Status | Duration | Error"}
+	for _, delivery := range deliveries {
+		status := ""
+		if delivery.StatusCode > 0 {
+			status = strconv.Itoa(delivery.StatusCode)
+		}
+
+		lines = append(lines, fmt.Sprintf("%s | %s | %s | %s | %d | %s | %s | %s",
+			delivery.Timestamp.Local().Format(time.RFC3339),
+			delivery.WebhookID,
+			delivery.Event,
+			delivery.App,
+			delivery.Attempt,
+			status,
+			delivery.Duration.Round(time.Millisecond),
+			delivery.Error,
+		))
+	}
+
+	fmt.Println(columnize.SimpleFormat(lines))
+	return nil
+}
+
+// CommandList lists the webhooks for an app or globally
+func CommandList(appName string, format string) error {
+	if format == "" {
+		format = "stdout"
+	}
+
+	if format != "stdout" && format != "json" {
+		return fmt.Errorf("Invalid format specified, supported formats: json, stdout")
+	}
+
+	if appName != "--global" {
+		if err := common.VerifyAppName(appName); err != nil {
+			return err
+		}
+	}
+
+	webhooks, err := FetchWebhooks(appName)
+	if err != nil {
+		return err
+	}
+
+	// never output the signing secret
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	if format == "json" {
+		b, err := json.Marshal(webhooks)
+		if err != nil {
+			return fmt.Errorf("Unable to marshal json: %w", err)
+		}
+
+		fmt.Println(string(b))
+		return nil
+	}
+
+	lines := []string{"ID | URL | Events"}
+	for _, webhook := range webhooks {
+		lines = append(lines, fmt.Sprintf("%s | %s | %s", webhook.ID, webhook.URL, strings.Join(webhook.Events, ", ")))
+	}
+
+	fmt.Println(columnize.SimpleFormat(lines))
+	return nil
+}
+
+// CommandRemove removes a webhook by id or url for an app or globally
+func CommandRemove(appName string, idOrURL string) error {
+	if appName != "--global" {
+		if err := common.VerifyAppName(appName); err != nil {
+			return err
+		}
+	}
+
+	if idOrURL == "" {
+		return fmt.Errorf("Missing webhook id or url")
+	}
+
+	webhook, found, err := findWebhook(appName, idOrURL)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("Webhook %s does not exist", idOrURL)
+	}
+
+	if err := common.PropertyDelete("webhooks", appName, getWebhookProperty(webhook.ID)); err != nil {
+		return fmt.Errorf("Unable to delete webhook: %w", err)
+	}
+
+	if err := removeQueuedDeliveries(appName, webhook.ID); err != nil {
+		return fmt.Errorf("Unable to remove queued deliveries: %w", err)
+	}
+
+	common.LogInfo1(fmt.Sprintf("Webhook %s removed", webhook.ID))
+	return writeCronEntries()
+}
+
+// writeCronEntries updates the cron task that retries queued deliveries
+func writeCronEntries() error {
+	_, err := common.CallPlugnTrigger(common.PlugnTriggerInput{
+		Trigger:     "scheduler-cron-write",
+		Args:        []string{"docker-local"},
+		StreamStdio: true,
+	})
+	return err
+}
@@ -0,0 +1,84 @@
+package webhooks
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dokku/dokku/plugins/common"
+)
+
+// TriggerCronEntries outputs the cron task that retries queued webhook deliveries
+func TriggerCronEntries(scheduler string) error {
+	if scheduler != "docker-local" {
+		return nil
+	}
+
+	if !hasWebhooks() {
+		return nil
+	}
+
+	logFile := fmt.Sprintf("%s/webhooks-deliver.log", os.Getenv("DOKKU_LOGS_DIR"))
+	fmt.Printf("* * * * *;dokku webhooks:deliver;%s\n", logFile)
+	return nil
+}
+
+// TriggerDeployFailed notifies webhooks that a deploy failed
+func TriggerDeployFailed(appName string, imageTag string, phase string) error {
+	return triggerDispatch("deploy.failed", appName, imageTag, phase)
+}
+
+// TriggerInstall runs the install step for the webhooks plugin
+func TriggerInstall() error {
+	if err := common.PropertySetup("webhooks"); err != nil {
+		return fmt.Errorf("Unable to install the webhooks plugin: %s", err.Error())
+	}
+
+	return common.CreateDataDirectory("webhooks")
+}
+
+// TriggerPostAppCloneSetup copies webhooks to the new app
+func TriggerPostAppCloneSetup(oldAppName string, newAppName string) error {
+	return common.PropertyClone("webhooks", oldAppName, newAppName)
+}
+
+// TriggerPostAppRenameSetup moves webhooks to the renamed app
+func TriggerPostAppRenameSetup(oldAppName string, newAppName string) error {
+	if err := common.PropertyClone("webhooks", oldAppName, newAppName); err != nil {
+		return err
+	}
+
+	return common.PropertyDestroy("webhooks", oldAppName)
+}
+
+// TriggerPostCreate creates the webhooks config directory for a new app
+func TriggerPostCreate(appName string) error {
+	return common.PropertySetupApp("webhooks", appName)
+}
+
+// TriggerPostDelete notifies webhooks that an app was destroyed, then removes its webhooks
+func TriggerPostDelete(appName string) error {
+	if err := triggerDispatch("app.destroyed", appName, "", ""); err != nil {
+		return err
+	}
+
+	return common.PropertyDestroy("webhooks", appName)
+}
+
+// TriggerPostDeploy notifies webhooks that a deploy succeeded
+func TriggerPostDeploy(appName string, imageTag string) error {
+	return triggerDispatch("deploy.succeeded", appName, imageTag, "")
+}
+
+// TriggerSchedulerDeploy notifies webhooks that a deploy started
+func TriggerSchedulerDeploy(scheduler string, appName string, imageTag string) error {
+	return triggerDispatch("deploy.started", appName, imageTag, "")
+}
+
+// triggerDispatch dispatches an event from a plugin trigger, as webhook failures should never fail the triggering command
+func triggerDispatch(event string, appName string, imageTag string, phase string) error {
+	if err := dispatchEvent(event, appName, imageTag, phase); err != nil {
+		common.LogWarn(fmt.Sprintf("Unable to dispatch %s webhooks: %s", event, err.Error()))
+	}
+
+	return nil
+}
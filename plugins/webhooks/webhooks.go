@@ -0,0 +1,178 @@
+package webhooks
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/dokku/dokku/plugins/common"
+)
+
+// SupportedEvents is a list of the events webhooks can be subscribed to
+var SupportedEvents = []string{
+	"app.destroyed",
+	"deploy.failed",
+	"deploy.started",
+	"deploy.succeeded",
+}
+
+// Webhook is an http endpoint that is notified of events for an app, or for all apps via --global
+type Webhook struct {
+	// ID identifies the webhook, and is derived from the url
+	ID string `json:"id"`
+
+	// URL is the url payloads are posted to
+	URL string `json:"url"`
+
+	// Events is the list of events the webhook is subscribed to
+	Events []string `json:"events"`
+
+	// Secret is the key used to sign payloads
+	Secret string `json:"secret"`
+}
+
+// Subscribes returns whether the webhook is subscribed to an event
+func (w Webhook) Subscribes(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getWebhookID returns the id for a webhook url
+func getWebhookID(webhookURL string) string {
+	sum := sha256.Sum256([]byte(webhookURL))
+	return hex.EncodeToString(sum[:])[0:12]
+}
+
+func getWebhookProperty(webhookID string) string {
+	return fmt.Sprintf("webhook-%s.json", webhookID)
+}
+
+// generateSecret returns a random secret for signing payloads
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("Unable to generate webhook secret: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// parseEvents parses a comma-separated list of events, defaulting to all supported events
+func parseEvents(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return SupportedEvents, nil
+	}
+
+	supported := map[string]bool{}
+	for _, event := range SupportedEvents {
+		supported[event] = true
+	}
+
+	found := map[string]bool{}
+	events := []string{}
+	for _, event := range strings.Split(value, ",") {
+		event = strings.TrimSpace(event)
+		if event == "" || found[event] {
+			continue
+		}
+
+		if !supported[event] {
+			return events, fmt.Errorf("Invalid event %s, supported events: %s", event, strings.Join(SupportedEvents, ", "))
+		}
+
+		found[event] = true
+		events = append(events, event)
+	}
+
+	sort.Strings(events)
+	return events, nil
+}
+
+// validateWebhookURL ensures a webhook url is an absolute http or https url
+func validateWebhookURL(webhookURL string) error {
+	if webhookURL == "" {
+		return errors.New("Missing webhook url")
+	}
+
+	u, err := url.Parse(webhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("Invalid webhook url, must be an http or https url: %s", webhookURL)
+	}
+
+	return nil
+}
+
+// FetchWebhooks returns the webhooks configured for an app, or globally via --global
+func FetchWebhooks(appName string) ([]Webhook, error) {
+	webhooks := []Webhook{}
+	properties, err := common.PropertyGetAllByPrefix("webhooks", appName, "webhook-")
+	if err != nil {
+		return webhooks, fmt.Errorf("Unable to get webhooks: %w", err)
+	}
+
+	for property, data := range properties {
+		if !strings.HasSuffix(property, ".json") {
+			continue
+		}
+
+		var webhook Webhook
+		if err := json.Unmarshal([]byte(data), &webhook); err != nil {
+			return webhooks, fmt.Errorf("Unable to unmarshal webhook %s: %w", property, err)
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	sort.SliceStable(webhooks, func(i, j int) bool {
+		return webhooks[i].URL < webhooks[j].URL
+	})
+
+	return webhooks, nil
+}
+
+// findWebhook returns the webhook matching either an id or a url
+func findWebhook(appName string, idOrURL string) (Webhook, bool, error) {
+	webhooks, err := FetchWebhooks(appName)
+	if err != nil {
+		return Webhook{}, false, err
+	}
+
+	for _, webhook := range webhooks {
+		if webhook.ID == idOrURL || webhook.URL == idOrURL {
+			return webhook, true, nil
+		}
+	}
+
+	return Webhook{}, false, nil
+}
+
+// hasWebhooks returns whether any webhooks are configured, either globally or for any app
+func hasWebhooks() bool {
+	if webhooks, err := FetchWebhooks("--global"); err == nil && len(webhooks) > 0 {
+		return true
+	}
+
+	apps, err := common.UnfilteredDokkuApps()
+	if err != nil {
+		return false
+	}
+
+	for _, appName := range apps {
+		if webhooks, err := FetchWebhooks(appName); err == nil && len(webhooks) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
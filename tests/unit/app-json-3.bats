@@ -58,6 +58,72 @@ teardown() {
   assert_output_contains "cron: 1" 1
 }
 
+@test "(app-json:validate)" {
+  local APP_JSON_FILE="$(mktemp "/tmp/${DOKKU_DOMAIN}.XXXXX")"
+  trap 'rm -f "$APP_JSON_FILE"' INT TERM
+
+  cat >"$APP_JSON_FILE" <<EOF
+{
+  "formaton": {},
+  "cron": [
+    {
+      "command": "echo hi",
+      "schedule": "@daily",
+      "concurrency_policy": "forbidd"
+    }
+  ]
+}
+EOF
+
+  run /bin/bash -c "dokku app-json:validate $TEST_APP --file $APP_JSON_FILE"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "has 1 problem(s) and 1 warning(s)"
+  assert_output_contains '$.formaton (line 2): unknown property "formaton", did you mean "formation"?'
+  assert_output_contains '$.cron[0].concurrency_policy (line 7): invalid value "forbidd"'
+
+  cat >"$APP_JSON_FILE" <<EOF
+{
+  "cron": [
+    {
+      "command": "echo hi",
+      "schedule": "@daily",
+      "concurrency_policy": "forbid"
+    }
+  ]
+}
+EOF
+
+  run /bin/bash -c "dokku app-json:validate $TEST_APP --file $APP_JSON_FILE"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "app.json for $TEST_APP is valid"
+
+  cat >"$APP_JSON_FILE" <<EOF
+{
+  "name": "heroku-app",
+  "formation": {
+    "web": {
+      "quantity": 1,
+      "size": "standard-1x"
+    }
+  },
+  "unknown_key": true
+}
+EOF
+
+  run /bin/bash -c "dokku app-json:validate $TEST_APP --file $APP_JSON_FILE"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains '$.unknown_key (line 9): unknown property "unknown_key"'
+  assert_output_contains "app.json for $TEST_APP is valid"
+
+  rm -f "$APP_JSON_FILE"
+}
+
 persist_scale_callback_a() {
   local APP="$1"
   local APP_REPO_DIR="$2"
@@ -67,6 +67,150 @@ teardown() {
   assert_failure
 }
 
+@test "(app-json) app.json env" {
+  run /bin/bash -c "dokku builder-herokuish:set $TEST_APP allowed true"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:set --no-restart $TEST_APP EXISTING_KEY=original"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run deploy_app python dokku@$DOKKU_DOMAIN:$TEST_APP add_required_env
+  echo "output: $output"
+  echo "status: $status"
+  assert_output_contains "Missing required config vars: REQUIRED_KEY"
+  assert_failure
+
+  run /bin/bash -c "dokku config:get $TEST_APP DEFAULT_KEY"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "default-value"
+
+  run /bin/bash -c "dokku config:get $TEST_APP EXISTING_KEY"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "original"
+
+  run /bin/bash -c "dokku config:get $TEST_APP SECRET_KEY | wc -c"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "65"
+
+  run /bin/bash -c "dokku config:set --no-restart $TEST_APP REQUIRED_KEY=value"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku ps:rebuild $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:unset --no-restart $TEST_APP DEFAULT_KEY"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku ps:rebuild $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:get $TEST_APP DEFAULT_KEY"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+}
+
+@test "(app-json) app.json named deployment tasks" {
+  run /bin/bash -c "dokku builder-herokuish:set $TEST_APP allowed true"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run deploy_app python dokku@$DOKKU_DOMAIN:$TEST_APP add_named_deployment_tasks
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "Executing prebuild (generate) task from app.json: touch /app/prebuild.test"
+  assert_output_contains "Executing postbuild task from app.json: touch /app/postbuild.test"
+  assert_output_contains "Executing prerelease task from app.json: touch /app/prerelease.test"
+  assert_output_contains "Executing predeploy (first) task from app.json: touch /app/predeploy-1.test"
+  assert_output_contains "Executing predeploy (second) task from app.json: touch /app/predeploy-2.test"
+
+  run /bin/bash -c "dokku run $TEST_APP ls /app/prebuild.test /app/postbuild.test /app/prerelease.test /app/predeploy-1.test /app/predeploy-2.test"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku app-json:report $TEST_APP --app-json-script-result-predeploy-second"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "exit code 0 in"
+
+  run /bin/bash -c "dokku app-json:report $TEST_APP --app-json-script-result-postbuild-postbuild"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "exit code 0 in"
+}
+
+@test "(app-json) app.json deployment task timeout" {
+  run /bin/bash -c "dokku builder-herokuish:set $TEST_APP allowed true"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run deploy_app python dokku@$DOKKU_DOMAIN:$TEST_APP add_timeout_dokku_predeploy
+  echo "output: $output"
+  echo "status: $status"
+  assert_output_contains "Execution of predeploy (slow) task failed: Timed out after 2 seconds"
+  assert_failure
+}
+
+@test "(app-json) app.json deployment task retries" {
+  run /bin/bash -c "dokku builder-herokuish:set $TEST_APP allowed true"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku app-json:set $TEST_APP script-retries invalid"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "dokku app-json:set $TEST_APP script-retries 1"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku app-json:report $TEST_APP --app-json-computed-script-retries"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "1"
+
+  run deploy_app python dokku@$DOKKU_DOMAIN:$TEST_APP add_failing_dokku_predeploy
+  echo "output: $output"
+  echo "status: $status"
+  assert_output_contains "Retrying predeploy task, attempt 2 of 2"
+  assert_output_contains "Execution of predeploy task failed"
+  assert_failure
+
+  run /bin/bash -c "docker container ls -a --filter label=dokku_phase_script=predeploy --filter label=com.dokku.app-name=$TEST_APP --quiet | wc -l"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "0"
+}
+
 add_failing_dokku_predeploy() {
   local APP="$1"
   local APP_REPO_DIR="$2"
@@ -123,3 +267,82 @@ add_failing_release() {
   release: exit 1
 EOF
 }
+
+add_required_env() {
+  local APP="$1"
+  local APP_REPO_DIR="$2"
+  [[ -z "$APP" ]] && local APP="$TEST_APP"
+
+  cat >"$APP_REPO_DIR/app.json" <<EOF
+  {
+    "env": {
+      "DEFAULT_KEY": "default-value",
+      "EXISTING_KEY": "overwritten",
+      "REQUIRED_KEY": {
+        "required": true
+      },
+      "SECRET_KEY": {
+        "generator": "secret"
+      }
+    }
+  }
+EOF
+}
+
+add_named_deployment_tasks() {
+  local APP="$1"
+  local APP_REPO_DIR="$2"
+  [[ -z "$APP" ]] && local APP="$TEST_APP"
+
+  cat >"$APP_REPO_DIR/app.json" <<EOF
+  {
+    "scripts": {
+      "dokku": {
+        "prebuild": {
+          "name": "generate",
+          "command": "touch /app/prebuild.test"
+        },
+        "postbuild": "touch /app/postbuild.test",
+        "prerelease": [
+          {
+            "command": "touch /app/prerelease.test",
+            "docker_options_phase": "build"
+          }
+        ],
+        "predeploy": [
+          {
+            "name": "first",
+            "command": "touch /app/predeploy-1.test",
+            "process_type": "web"
+          },
+          {
+            "name": "second",
+            "command": "touch /app/predeploy-2.test",
+            "timeout": 60
+          }
+        ]
+      }
+    }
+  }
+EOF
+}
+
+add_timeout_dokku_predeploy() {
+  local APP="$1"
+  local APP_REPO_DIR="$2"
+  [[ -z "$APP" ]] && local APP="$TEST_APP"
+
+  cat >"$APP_REPO_DIR/app.json" <<EOF
+  {
+    "scripts": {
+      "dokku": {
+        "predeploy": {
+          "name": "slow",
+          "command": "sleep 30",
+          "timeout": 2
+        }
+      }
+    }
+  }
+EOF
+}
@@ -10,6 +10,7 @@ setup() {
 }
 
 teardown() {
+  rm -f "$DOKKU_ROOT/.dokkurc/DOKKU_CONFIG_ENCRYPTION" /tmp/dokku-config.key
   destroy_app
   ls -la ${DOKKU_ROOT}
   if [[ -f ${DOKKU_ROOT}/ENV.bak ]]; then
@@ -352,6 +353,73 @@ teardown() {
   assert_success
 }
 
+@test "(config) config:import and config:export yaml, toml and kubernetes formats" {
+  run /bin/bash -c "printf 'database:\\n  host: db.internal\\n  port: 5432\\n' | dokku config:import --format yaml $TEST_APP -"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:get $TEST_APP database_host"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "db.internal"
+
+  run /bin/bash -c "printf '[cache]\\nttl = 60\\n' | dokku config:import --format toml $TEST_APP -"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:get $TEST_APP cache_ttl"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "60"
+
+  run /bin/bash -c "printf 'HOSTS:\\n  - a\\n' | dokku config:import --format yaml $TEST_APP -"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Unsupported list value for key 'HOSTS'"
+
+  run /bin/bash -c "dokku config:export --format k8s-secret $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "kind: Secret"
+  assert_output_contains "name: env-$TEST_APP"
+  assert_output_contains "database_host: ZGIuaW50ZXJuYWw="
+
+  run /bin/bash -c "dokku config:export --format k8s-secret $TEST_APP | dokku config:import --format k8s-configmap $TEST_APP -"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Expected a ConfigMap manifest, got kind 'Secret'"
+
+  run /bin/bash -c "dokku config:export --format k8s-secret $TEST_APP | dokku config:import --replace --format k8s-secret $TEST_APP -"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:get $TEST_APP database_host"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "db.internal"
+
+  run /bin/bash -c "dokku config:export --format yaml $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains 'database_port: "5432"'
+
+  run /bin/bash -c "dokku config:export --format toml $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains 'cache_ttl = "60"'
+}
+
 @test "(config) config:set/get" {
   run ssh "dokku@$DOKKU_DOMAIN" config:set $TEST_APP test_var1=true test_var2=\"hello world\" test_var3='double\"quotes'
   echo "output: $output"
@@ -535,3 +603,321 @@ teardown() {
   echo "status: $status"
   assert_output '[{"name":"BKEY","value":"true"},{"name":"aKey","value":"true"},{"name":"bKey","value":"true"},{"name":"zKey","value":"true"}]'
 }
+
+@test "(config) config:history, config:diff and config:rollback" {
+  run /bin/bash -c "dokku config:set --no-restart $TEST_APP test_var=one"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:set --no-restart $TEST_APP test_var=two test_var2=added"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:history $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "test_var,test_var2"
+  assert_output_contains "two" 0
+
+  run /bin/bash -c "dokku config:history --format json $TEST_APP | jq -r '.[1].changes[0].action'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "changed"
+
+  run /bin/bash -c "dokku config:diff $TEST_APP 1 2"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "~ test_var sha256:"
+  assert_output_contains "+ test_var2 sha256:"
+
+  run /bin/bash -c "grep -l two $DOKKU_ROOT/$TEST_APP/ENV.history/*.json | wc -l"
+  echo "output: $output"
+  echo "status: $status"
+  assert_output "0"
+
+  run /bin/bash -c "stat -c '%a' $DOKKU_ROOT/$TEST_APP/ENV.history/2.env"
+  echo "output: $output"
+  echo "status: $status"
+  assert_output "600"
+
+  run /bin/bash -c "dokku config:rollback --no-restart $TEST_APP 1"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:get $TEST_APP test_var"
+  echo "output: $output"
+  echo "status: $status"
+  assert_output "one"
+
+  run /bin/bash -c "dokku config:get $TEST_APP test_var2"
+  echo "output: $output"
+  echo "status: $status"
+  assert_output_not_exists
+
+  run /bin/bash -c "dokku config:history --format json $TEST_APP | jq -r '.[2].operation'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "rollback:1"
+
+  run /bin/bash -c "rm $DOKKU_ROOT/$TEST_APP/ENV.history/1.env"
+  run /bin/bash -c "dokku config:rollback --no-restart $TEST_APP 1"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Revision 1 cannot be restored"
+
+  run /bin/bash -c "dokku config:rollback --no-restart $TEST_APP 100"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Revision 100 does not exist"
+}
+
+@test "(config) encrypted config values" {
+  sudo -H -u dokku /bin/bash -c "openssl rand -hex 32 > /tmp/dokku-config.key"
+  echo "export DOKKU_CONFIG_ENCRYPTION=keyfile DOKKU_CONFIG_KEYFILE=/tmp/dokku-config.key" >"$DOKKU_ROOT/.dokkurc/DOKKU_CONFIG_ENCRYPTION"
+
+  run /bin/bash -c "dokku config:set --no-restart $TEST_APP SECRET_KEY=hunter2"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "grep -c hunter2 $DOKKU_ROOT/$TEST_APP/ENV"
+  echo "output: $output"
+  echo "status: $status"
+  assert_output "0"
+
+  run /bin/bash -c "grep -c dokku:enc:v1:keyfile $DOKKU_ROOT/$TEST_APP/ENV"
+  echo "output: $output"
+  echo "status: $status"
+  assert_output "1"
+
+  run /bin/bash -c "dokku config:get $TEST_APP SECRET_KEY"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "hunter2"
+
+  run /bin/bash -c "dokku config:export --format docker-args $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "--env=SECRET_KEY='hunter2'"
+
+  run /bin/bash -c "dokku config:show $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "hunter2" 0
+
+  run /bin/bash -c "dokku config:show --reveal $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "hunter2"
+}
+
+@test "(config) config references" {
+  run create_app "$TEST_APP-source"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:set --no-restart $TEST_APP-source DATABASE_URL=postgres://db/source"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:set --no-restart $TEST_APP 'DATABASE_URL=\${app:$TEST_APP-source:DATABASE_URL}?sslmode=disable' 'GLOBAL_REF=\${global:global_test}'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:get $TEST_APP DATABASE_URL"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "\${app:$TEST_APP-source:DATABASE_URL}?sslmode=disable"
+
+  run /bin/bash -c "dokku config:export --merged --format json $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains '"DATABASE_URL":"postgres://db/source?sslmode=disable"'
+  assert_output_contains '"GLOBAL_REF":"true"'
+
+  run /bin/bash -c "dokku config:refs $TEST_APP-source"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "DATABASE_URL  $TEST_APP"
+
+  run /bin/bash -c "dokku config:set --no-restart $TEST_APP-source 'DATABASE_URL=\${app:$TEST_APP:DATABASE_URL}'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:export --merged $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Config reference cycle detected"
+
+  run destroy_app 0 "$TEST_APP-source"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+}
+
+@test "(config) config:schema" {
+  run /bin/bash -c "dokku config:schema:set --type int --default 2 $TEST_APP WEB_CONCURRENCY"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:schema:set --type enum --values debug,info $TEST_APP LOG_LEVEL"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:schema:set --type enum $TEST_APP LOG_LEVEL"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "The enum type requires at least one value"
+
+  run /bin/bash -c "dokku config:set --no-restart $TEST_APP WEB_CONCURRENCY=abc"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "WEB_CONCURRENCY: expected an int, got 'abc'"
+
+  run /bin/bash -c "dokku config:set --no-restart $TEST_APP LOG_LEVEL=warn"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "LOG_LEVEL: expected one of debug, info, got 'warn'"
+
+  run /bin/bash -c "dokku config:set --no-restart $TEST_APP WEB_CONCURRENCY=4 LOG_LEVEL=info"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:schema:show --format json $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains '"WEB_CONCURRENCY":{"type":"int","default":"2","source":"config:schema:set"}'
+
+  run /bin/bash -c "dokku config:schema:unset $TEST_APP LOG_LEVEL"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:set --no-restart $TEST_APP LOG_LEVEL=warn"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+}
+
+@test "(config) config:schema required keys fail the deploy" {
+  run /bin/bash -c "dokku config:schema:set --required $TEST_APP SECRET_KEY_BASE"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run deploy_app
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Missing required config vars: SECRET_KEY_BASE"
+
+  run /bin/bash -c "dokku config:set --no-restart $TEST_APP SECRET_KEY_BASE=secret"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run deploy_app
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+}
+
+@test "(config) config:set --process" {
+  run /bin/bash -c "dokku config:set --no-restart $TEST_APP WEB_CONCURRENCY=4"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:set --no-restart --process worker $TEST_APP WEB_CONCURRENCY=1 MALLOC_ARENA_MAX=2"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:set --no-restart --process worker --global MALLOC_ARENA_MAX=2"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "The --process flag cannot be used with --global"
+
+  run /bin/bash -c "dokku config:get --process worker $TEST_APP WEB_CONCURRENCY"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "1"
+
+  run /bin/bash -c "dokku config:get $TEST_APP WEB_CONCURRENCY"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "4"
+
+  run /bin/bash -c "dokku config:export --format shell --process worker --merged $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "WEB_CONCURRENCY='1'"
+  assert_output_contains "global_test='true'"
+
+  run /bin/bash -c "dokku ps:scale --skip-deploy $TEST_APP web=1 worker=1"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run deploy_app
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku enter $TEST_APP web env"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "WEB_CONCURRENCY=4"
+  assert_output_not_contains "MALLOC_ARENA_MAX"
+
+  run /bin/bash -c "dokku enter $TEST_APP worker env"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "WEB_CONCURRENCY=1"
+  assert_output_contains "MALLOC_ARENA_MAX=2"
+
+  run /bin/bash -c "dokku config:unset --no-restart --process worker $TEST_APP WEB_CONCURRENCY MALLOC_ARENA_MAX"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:show --process worker $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_not_contains "WEB_CONCURRENCY"
+}
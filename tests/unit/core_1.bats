@@ -91,3 +91,46 @@ teardown() {
   echo "status: $status"
   assert_output "false"
 }
+
+@test "(core) json output format" {
+  run deploy_app
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku --output-format=json ps:rebuild $TEST_APP 2>&1 | grep '^{' | jq -r 'select(.event == \"phase.end\") | .phase' | sort -u | xargs"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "build checks container-start predeploy proxy-reload release retire"
+
+  run /bin/bash -c "dokku --output-format=json ps:rebuild $TEST_APP 2>&1 | grep '^{' | jq -e 'select(.event == \"phase.end\") | .duration_ms >= 0 and .app == \"$TEST_APP\"' | sort -u | xargs"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "true"
+
+  run /bin/bash -c "DOKKU_OUTPUT_FORMAT=json dokku ps:rebuild $TEST_APP 2>&1 | grep '^{' | jq -r 'select(.message == \"Releasing $TEST_APP...\") | .level'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "info"
+
+  run /bin/bash -c "dokku --output-format=json ps:rebuild $TEST_APP 2>&1 | grep -v '^{' | grep -v '^$'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "DOKKU_OUTPUT_FORMAT=json dokku apps:report $TEST_APP --format json | jq -r 'has(\"timestamp\")'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "false"
+
+  run /bin/bash -c "DOKKU_OUTPUT_FORMAT=json dokku --quiet apps:list"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "$TEST_APP"
+  assert_output_contains "timestamp" 0
+}
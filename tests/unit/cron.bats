@@ -17,6 +17,7 @@ EOF
 }
 
 teardown() {
+  dokku cron:set --global runner || true
   rm -rf /var/lib/dokku/plugins/available/cron-entries /var/lib/dokku/plugins/enabled/cron-entries
   destroy_app
   global_teardown
@@ -235,6 +236,215 @@ teardown() {
   assert_output "['task.py', 'schedule', 'now']"
 }
 
+@test "(cron) cron:set timezone" {
+  run deploy_app python dokku@$DOKKU_DOMAIN:$TEST_APP template_cron_file_valid
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku cron:list $TEST_APP --format json | jq -r '.[0].next_run'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_exists
+
+  run /bin/bash -c "dokku cron:set $TEST_APP timezone Invalid/Timezone"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "dokku cron:set $TEST_APP timezone Asia/Tokyo"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku cron:list $TEST_APP --format json | jq -r '.[0].timezone'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "Asia/Tokyo"
+
+  run /bin/bash -c "dokku cron:list $TEST_APP --format json | jq -r '.[0].next_run'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "+09:00"
+
+  run /bin/bash -c "cat /var/spool/cron/crontabs/dokku"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "dokku cron:run --scheduled --if-due $TEST_APP"
+  assert_output_contains "(5 5 5 5 5 in Asia/Tokyo)"
+
+  run /bin/bash -c "dokku cron:set $TEST_APP timezone"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "cat /var/spool/cron/crontabs/dokku"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "--if-due" 0
+}
+
+@test "(cron) cron:set runner" {
+  run deploy_app python dokku@$DOKKU_DOMAIN:$TEST_APP template_cron_file_valid
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku cron:set $TEST_APP runner daemon"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "dokku cron:set --global runner invalid"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "dokku cron:set --global runner daemon"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku cron:report $TEST_APP --cron-global-runner"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "daemon"
+
+  run /bin/bash -c "crontab -l -u dokku"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "dokku cron:set --global runner"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "cat /var/spool/cron/crontabs/dokku"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "python3 task.py schedule"
+}
+
+@test "(cron) cron:history" {
+  run deploy_app python dokku@$DOKKU_DOMAIN:$TEST_APP template_cron_file_valid
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "cat /var/spool/cron/crontabs/dokku"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "dokku cron:run --scheduled $TEST_APP"
+
+  cron_id="$(dokku cron:list $TEST_APP --format json | jq -r '.[0].id')"
+  run /bin/bash -c "dokku cron:history $TEST_APP --format json | jq -r 'length'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "0"
+
+  run /bin/bash -c "dokku cron:run $TEST_APP $cron_id"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku cron:history $TEST_APP --cron-id $cron_id --format json | jq -r '.[0].exit_code'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "0"
+
+  run /bin/bash -c "dokku cron:history $TEST_APP --cron-id $cron_id --format json | jq -r '.[0].output'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "['task.py', 'schedule']"
+
+  run /bin/bash -c "dokku cron:history $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "manual"
+  assert_output_contains "succeeded"
+
+  run /bin/bash -c "dokku cron:set $TEST_APP history-limit invalid"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "dokku cron:set $TEST_APP history-limit 1"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku cron:run $TEST_APP $cron_id"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku cron:history $TEST_APP --format json | jq -r 'length'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "1"
+}
+
+@test "(cron) cron:pipelines" {
+  run deploy_app python dokku@$DOKKU_DOMAIN:$TEST_APP template_cron_file_pipeline
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "grep -c 'dokku cron:run --scheduled $TEST_APP' /var/spool/cron/crontabs/dokku"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "1"
+
+  run /bin/bash -c "dokku cron:pipelines $TEST_APP --format json | jq -r '.[0].status'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "never-run"
+
+  run /bin/bash -c "dokku cron:run $TEST_APP export"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "['task.py', 'export']"
+  assert_output_contains "['task.py', 'transform']"
+  assert_output_contains "skipping the 1 cron tasks that run after it"
+  assert_output_not_contains "['task.py', 'cleanup']"
+
+  run /bin/bash -c "dokku cron:pipelines $TEST_APP --format json | jq -r '.[0].status'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "failed"
+
+  run /bin/bash -c "dokku cron:pipelines $TEST_APP --format json | jq -r '.[0].steps[].status' | xargs"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "succeeded succeeded failed skipped"
+
+  run /bin/bash -c "dokku cron:pipelines $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "transform"
+  assert_output_contains "skipped"
+}
+
 @test "(cron) cron:run concurrency_policy forbid" {
   run deploy_app dockerfile dokku@$DOKKU_DOMAIN:$TEST_APP template_cron_file_concurrency_forbid
   echo "output: $output"
@@ -476,3 +686,35 @@ template_cron_file_concurrency_forbid() {
 }
 EOF
 }
+
+template_cron_file_pipeline() {
+  local APP="$1"
+  local APP_REPO_DIR="$2"
+  [[ -z "$APP" ]] && local APP="$TEST_APP"
+  echo "injecting valid cron app.json -> $APP_REPO_DIR/app.json"
+  cat <<EOF >"$APP_REPO_DIR/app.json"
+{
+  "cron": [
+    {
+      "command": "python3 task.py export",
+      "name": "export",
+      "schedule": "5 5 5 5 5"
+    },
+    {
+      "command": "python3 task.py transform",
+      "name": "transform",
+      "after": "export"
+    },
+    {
+      "command": "false",
+      "name": "load",
+      "after": "transform"
+    },
+    {
+      "command": "python3 task.py cleanup",
+      "after": "load"
+    }
+  ]
+}
+EOF
+}
@@ -8,6 +8,7 @@ setup() {
 }
 
 teardown() {
+  dokku events:off >/dev/null || true
   destroy_app
   global_teardown
 }
@@ -62,3 +63,94 @@ teardown() {
   echo "status: $status"
   assert_success
 }
+
+@test "(events) events:list" {
+  run /bin/bash -c "dokku config:set --no-restart $TEST_APP UNRECORDED=VALUE"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku events:on"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:set --no-restart $TEST_APP KEY=VALUE"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku events:list --app $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "action=set keys=KEY"
+  assert_output_not_contains "UNRECORDED"
+
+  run /bin/bash -c "dokku events:list --app $TEST_APP --format json | jq -r '.[].name'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "config.updated"
+
+  run /bin/bash -c "dokku events:list --app $TEST_APP --format json | jq -r '.[].params.KEY // empty'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_not_exists
+
+  run /bin/bash -c "dokku events:list --since invalid"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "dokku events:list --format invalid"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+}
+
+@test "(events) events:set webhook-url" {
+  run /bin/bash -c "dokku events:set $TEST_APP webhook-url http://127.0.0.1:9999"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "dokku events:set --global webhook-url invalid"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "dokku events:set --global webhook-url http://127.0.0.1:9999"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku config:set --no-restart $TEST_APP KEY=VALUE"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "ls /var/lib/dokku/data/events/queue | wc -l"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "1"
+
+  run /bin/bash -c "dokku cron:list --global"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "events:deliver"
+
+  run /bin/bash -c "dokku events:set --global webhook-url"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "ls /var/lib/dokku/data/events/queue | wc -l"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "0"
+}
@@ -54,7 +54,7 @@ teardown() {
   echo "status: $status"
   assert_failure
   assert_output_contains "$TEST_APP logs information" 0
-  assert_output_contains "Invalid flag passed, valid flags: --logs-app-label-alias, --logs-computed-app-label-alias, --logs-computed-max-size, --logs-global-app-label-alias, --logs-global-max-size, --logs-global-vector-sink, --logs-max-size, --logs-vector-global-image, --logs-vector-sink"
+  assert_output_contains "Invalid flag passed, valid flags: --logs-app-label-alias, --logs-computed-app-label-alias, --logs-computed-log-format, --logs-computed-max-size, --logs-computed-store, --logs-computed-store-retention, --logs-global-app-label-alias, --logs-global-max-size, --logs-global-store, --logs-global-store-retention, --logs-global-vector-sink, --logs-log-format, --logs-max-size, --logs-store, --logs-store-retention, --logs-vector-global-image, --logs-vector-sink"
 
   run /bin/bash -c "dokku logs:report $TEST_APP --logs-vector-sink 2>&1"
   echo "output: $output"
@@ -458,6 +458,258 @@ teardown() {
   assert_output "10m"
 }
 
+@test "(logs) logs:search" {
+  run create_app
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku logs:set $TEST_APP store invalid"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "dokku logs:set $TEST_APP store-retention 0"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "dokku logs:set $TEST_APP store true"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "Writing updated vector config to /var/lib/dokku/data/logs/vector.json"
+
+  run /bin/bash -c "jq -r '.sinks[\"docker-store-sink:$TEST_APP\"].path' /var/lib/dokku/data/logs/vector.json"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "/var/log/dokku/apps/$TEST_APP/store/%Y-%m-%d.log"
+
+  run /bin/bash -c "cat /var/spool/cron/crontabs/dokku"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "dokku logs:rotate --all"
+
+  today="$(date -u +%Y-%m-%d)"
+  cat <<EOF >"/var/log/dokku/apps/$TEST_APP/store/$today.log"
+{"timestamp":"${today}T01:00:00Z","message":"GET / 200","stream":"stdout","container_name":"$TEST_APP.web.1","label":{"com.dokku.process-type":"web","com.dokku.dyno":"web.1"}}
+{"timestamp":"${today}T02:00:00Z","message":"job failed","stream":"stderr","container_name":"$TEST_APP.worker.1","label":{"com.dokku.process-type":"worker","com.dokku.dyno":"worker.1"}}
+EOF
+
+  run /bin/bash -c "dokku logs:search $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "$TEST_APP[web.1]: GET / 200"
+  assert_output_contains "$TEST_APP[worker.1]: job failed"
+
+  run /bin/bash -c "dokku logs:search $TEST_APP --process worker --quiet"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "job failed"
+
+  run /bin/bash -c "dokku logs:search $TEST_APP --grep 'GET.*200' --until ${today}T01:30:00Z --quiet"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "GET / 200"
+
+  run /bin/bash -c "dokku logs:search $TEST_APP --since invalid"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "dokku logs:export $TEST_APP --since ${today}T01:30:00Z | jq -r '.dyno'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "worker.1"
+
+  run /bin/bash -c "dokku logs:rotate $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "test -f /var/log/dokku/apps/$TEST_APP/store/$today.log"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku logs:set $TEST_APP store"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "jq -r '.sinks | keys[]' /var/lib/dokku/data/logs/vector.json"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_not_contains "docker-store-sink:$TEST_APP"
+}
+
+@test "(logs) logs:sink" {
+  run create_app
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku logs:sink:add $TEST_APP Invalid_Name console://?encoding[codec]=json"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "dokku logs:sink:add $TEST_APP errors console://?encoding[codec]=json --level '(invalid'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "dokku logs:sink:add $TEST_APP errors console://?encoding[codec]=json --sample-rate 0"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Invalid sample rate"
+
+  run /bin/bash -c "dokku logs:sink:add $TEST_APP errors 'console://?encoding[codec]=json' --process web --level '(?i)error' --sample-rate 10 --redact 'password=\S+'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "Sink errors added"
+  assert_output_contains "Writing updated vector config to /var/lib/dokku/data/logs/vector.json"
+
+  run /bin/bash -c "dokku logs:sink:add $TEST_APP errors 'console://?encoding[codec]=json'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Sink errors already exists"
+
+  run /bin/bash -c "dokku logs:sink:add $TEST_APP everything 'blackhole://?print_interval_secs=1'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku logs:sink:list $TEST_APP --format json | jq -r '.[].name' | xargs"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "errors everything"
+
+  run /bin/bash -c "dokku logs:sink:list $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "console"
+  assert_output_contains "blackhole"
+
+  run /bin/bash -c "jq -r '.transforms | keys[]' /var/lib/dokku/data/logs/vector.json | xargs"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "docker-sink:$TEST_APP:errors:filter docker-sink:$TEST_APP:errors:redact docker-sink:$TEST_APP:errors:sample"
+
+  run /bin/bash -c "jq -r '.sinks[\"docker-sink:$TEST_APP:errors\"].inputs[0]' /var/lib/dokku/data/logs/vector.json"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "docker-sink:$TEST_APP:errors:redact"
+
+  run /bin/bash -c "jq -r '.sinks[\"docker-sink:$TEST_APP:everything\"].inputs[0]' /var/lib/dokku/data/logs/vector.json"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "docker-source:$TEST_APP"
+
+  run /bin/bash -c "dokku logs:sink:remove $TEST_APP errors"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "Sink errors removed"
+
+  run /bin/bash -c "dokku logs:sink:remove $TEST_APP errors"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "jq -r '.transforms // {} | length' /var/lib/dokku/data/logs/vector.json"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "0"
+}
+
+@test "(logs) logs:set log-format" {
+  run create_app
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku logs:set $TEST_APP log-format invalid"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Invalid log-format value"
+
+  run /bin/bash -c "dokku logs:report $TEST_APP --logs-computed-log-format"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "text"
+
+  run /bin/bash -c "dokku logs:set $TEST_APP vector-sink 'console://?encoding[codec]=json'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "jq -r '.sinks[\"docker-sink:$TEST_APP\"].inputs[0]' /var/lib/dokku/data/logs/vector.json"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "docker-source:$TEST_APP"
+
+  run /bin/bash -c "dokku logs:set $TEST_APP log-format logfmt"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "Writing updated vector config to /var/lib/dokku/data/logs/vector.json"
+
+  run /bin/bash -c "dokku logs:report $TEST_APP --logs-log-format"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "logfmt"
+
+  run /bin/bash -c "jq -r '.sinks[\"docker-sink:$TEST_APP\"].inputs[0]' /var/lib/dokku/data/logs/vector.json"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "docker-parse:$TEST_APP"
+
+  run /bin/bash -c "jq -r '.transforms[\"docker-parse:$TEST_APP\"].source' /var/lib/dokku/data/logs/vector.json"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "parse_logfmt"
+  assert_output_contains ".app = .label.\"com.dokku.app-name\""
+
+  run /bin/bash -c "dokku logs:set $TEST_APP log-format"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "jq -r '.transforms // {} | length' /var/lib/dokku/data/logs/vector.json"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "0"
+
+  run /bin/bash -c "dokku logs:set $TEST_APP vector-sink"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+}
+
 @test "(logs) logs:set app-label-alias" {
   run create_app
   echo "output: $output"
@@ -283,3 +283,86 @@ teardown() {
   assert_success
   assert_output "secret"
 }
+
+@test "(scheduler-k3s) deploy scheduling" {
+  run /bin/bash -c "dokku apps:create $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku scheduler-k3s:scheduling:set $TEST_APP invalid value"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Invalid scheduling property specified"
+
+  run /bin/bash -c "dokku scheduler-k3s:scheduling:set $TEST_APP tolerations key=value:Invalid"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Invalid toleration effect Invalid"
+
+  run /bin/bash -c "dokku scheduler-k3s:scheduling:set $TEST_APP topology-spread kubernetes.io/hostname:0"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Invalid topology spread max skew 0"
+
+  if [[ -z "$DOCKERHUB_USERNAME" ]] || [[ -z "$DOCKERHUB_TOKEN" ]]; then
+    skip "skipping due to missing docker.io credentials DOCKERHUB_USERNAME:DOCKERHUB_TOKEN"
+  fi
+
+  install_k3s
+
+  run /bin/bash -c "dokku domains:set $TEST_APP $TEST_APP.dokku.me"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku scheduler-k3s:scheduling:set $TEST_APP node-selector node-role.kubernetes.io/control-plane=true --process-type web"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku scheduler-k3s:scheduling:set $TEST_APP topology-spread kubernetes.io/hostname:1:DoNotSchedule"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku git:sync --build $TEST_APP https://github.com/dokku/smoke-test-app.git"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  assert_http_localhost_response "http" "$TEST_APP.dokku.me" "80" "" "python/http.server"
+
+  run /bin/bash -c "kubectl get deployment $TEST_APP-web -o json | jq -r '.spec.template.spec.nodeSelector.\"node-role.kubernetes.io/control-plane\"'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "true"
+
+  run /bin/bash -c "kubectl get deployment $TEST_APP-web -o json | jq -r '.spec.template.spec.tolerations[0].key'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "CriticalAddonsOnly"
+
+  run /bin/bash -c "kubectl get deployment $TEST_APP-web -o json | jq -r '.spec.template.spec.topologySpreadConstraints[0].whenUnsatisfiable'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "DoNotSchedule"
+
+  run /bin/bash -c "kubectl get cronjob -o json | jq -r '.items[0].spec.jobTemplate.spec.template.spec.nodeSelector'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "null"
+
+  run /bin/bash -c "kubectl get cronjob -o json | jq -r '.items[0].spec.jobTemplate.spec.template.spec.topologySpreadConstraints[0].topologyKey'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "kubernetes.io/hostname"
+}
@@ -158,3 +158,257 @@ patches:
     name: $APP-web
 EOF
 }
+
+@test "(scheduler-k3s) releases and rollback" {
+  if [[ -z "$DOCKERHUB_USERNAME" ]] || [[ -z "$DOCKERHUB_TOKEN" ]]; then
+    skip "skipping due to missing docker.io credentials DOCKERHUB_USERNAME:DOCKERHUB_TOKEN"
+  fi
+
+  INGRESS_CLASS=nginx install_k3s
+
+  run /bin/bash -c "dokku apps:create $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku scheduler-k3s:rollback $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run deploy_app python "dokku@$DOKKU_DOMAIN:$TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku ps:rebuild $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku scheduler-k3s:releases $TEST_APP --format json | jq -r '. | length'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "2"
+
+  run /bin/bash -c "dokku scheduler-k3s:releases $TEST_APP --format json | jq -r '.[0].image_tag'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "2"
+
+  run /bin/bash -c "dokku scheduler-k3s:rollback $TEST_APP 2"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Revision 2 is already deployed"
+
+  run /bin/bash -c "dokku scheduler-k3s:rollback $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "Rolled back $TEST_APP to revision 1"
+
+  run /bin/bash -c "dokku scheduler-k3s:releases $TEST_APP --format json | jq -r '.[0].image_tag'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "1"
+
+  run /bin/bash -c "kubectl get deployment $TEST_APP-web -o json | jq -r '.spec.template.spec.containers[0].image'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains ":1"
+
+  run /bin/bash -c "dokku registry:report $TEST_APP --registry-tag-version"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "1"
+
+  run /bin/bash -c "dokku ps:rebuild $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku registry:report $TEST_APP --registry-tag-version"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "3"
+}
+
+@test "(scheduler-k3s) rollout strategies" {
+  run /bin/bash -c "dokku scheduler-k3s:set --global rollout-strategy invalid"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Invalid rollout strategy invalid"
+
+  run /bin/bash -c "dokku scheduler-k3s:set --global canary-steps 50:1m,10:1m"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Canary step weights must be increasing"
+
+  run /bin/bash -c "dokku scheduler-k3s:set --global canary-steps 100:1m"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Invalid canary step weight 100"
+
+  if [[ -z "$DOCKERHUB_USERNAME" ]] || [[ -z "$DOCKERHUB_TOKEN" ]]; then
+    skip "skipping due to missing docker.io credentials DOCKERHUB_USERNAME:DOCKERHUB_TOKEN"
+  fi
+
+  INGRESS_CLASS=nginx install_k3s
+
+  run /bin/bash -c "dokku apps:create $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku scheduler-k3s:set $TEST_APP rollout-strategy blue-green"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run deploy_app python "dokku@$DOKKU_DOMAIN:$TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_not_contains "Starting blue-green rollout"
+
+  run /bin/bash -c "dokku scheduler-k3s:promote $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "No rollout in progress for $TEST_APP"
+
+  run /bin/bash -c "dokku ps:rebuild $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "Starting blue-green rollout"
+  assert_output_contains "Rollout paused with 0% of traffic routed to the new release"
+
+  run /bin/bash -c "kubectl get deployment $TEST_APP-web-canary -o json | jq -r '.spec.template.spec.containers[0].image'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains ":2"
+
+  run /bin/bash -c "kubectl get deployment $TEST_APP-web -o json | jq -r '.spec.template.spec.containers[0].image'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains ":1"
+
+  run /bin/bash -c "dokku ps:restart $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "dokku scheduler-k3s:abort $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "Rollout aborted"
+
+  run /bin/bash -c "kubectl get deployment $TEST_APP-web-canary"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "dokku registry:report $TEST_APP --registry-tag-version"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "1"
+
+  run /bin/bash -c "dokku ps:rebuild $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "Rollout paused with 0% of traffic routed to the new release"
+
+  run /bin/bash -c "dokku scheduler-k3s:promote $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "Rollout complete"
+
+  run /bin/bash -c "kubectl get deployment $TEST_APP-web-canary"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "kubectl get deployment $TEST_APP-web -o json | jq -r '.spec.template.spec.containers[0].image'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains ":3"
+}
+
+@test "(scheduler-k3s) pod disruption budgets" {
+  run /bin/bash -c "dokku scheduler-k3s:set --global min-available invalid"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Invalid min-available value invalid"
+
+  run /bin/bash -c "dokku scheduler-k3s:set --global min-available 150%"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Invalid min-available percentage 150%"
+
+  if [[ -z "$DOCKERHUB_USERNAME" ]] || [[ -z "$DOCKERHUB_TOKEN" ]]; then
+    skip "skipping due to missing docker.io credentials DOCKERHUB_USERNAME:DOCKERHUB_TOKEN"
+  fi
+
+  INGRESS_CLASS=nginx install_k3s
+
+  run /bin/bash -c "dokku apps:create $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku scheduler-k3s:set $TEST_APP min-available 50%"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku ps:scale --skip-deploy $TEST_APP web=2"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run deploy_app python "dokku@$DOKKU_DOMAIN:$TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "kubectl get poddisruptionbudget $TEST_APP-web -o json | jq -r '.spec.minAvailable'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "50%"
+
+  run /bin/bash -c "dokku scheduler-k3s:set $TEST_APP min-available 0"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku ps:rebuild $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "kubectl get poddisruptionbudget $TEST_APP-web"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+}
@@ -183,3 +183,64 @@ teardown() {
   assert_success
   assert_output "mount_volume:/mount"
 }
+
+@test "(storage) storage:create, storage:destroy" {
+  run /bin/bash -c "dokku storage:create $TEST_APP uploads /app/uploads --size 10Gi --process-type web,worker"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "Created volume uploads"
+  assert_output_contains "Persistent volumes are only supported by the k3s scheduler"
+
+  run /bin/bash -c "dokku storage:report $TEST_APP --storage-volumes"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "uploads:/app/uploads:10Gi:ReadWriteOnce"
+
+  run /bin/bash -c "dokku storage:create $TEST_APP uploads /app/other"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Volume uploads already exists"
+
+  run /bin/bash -c "dokku storage:create $TEST_APP other /app/uploads"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Volume uploads is already mounted at /app/uploads"
+
+  run /bin/bash -c "dokku storage:create $TEST_APP Invalid_Name /app/invalid"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "dokku storage:create $TEST_APP cache /app/cache --access-mode Invalid"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Invalid access mode Invalid"
+
+  run /bin/bash -c "dokku storage:create $TEST_APP cache /app/cache --size ten"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Invalid volume size ten"
+
+  run /bin/bash -c "dokku storage:destroy $TEST_APP uploads --force"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku storage:report $TEST_APP --storage-volumes"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_not_exists
+
+  run /bin/bash -c "dokku storage:destroy $TEST_APP uploads --force"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Volume uploads does not exist"
+}
@@ -0,0 +1,250 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+WEBHOOK_STUB_PORT=9876
+WEBHOOK_STUB_DIR=/tmp/webhooks-stub
+
+setup() {
+  global_setup
+  create_app
+  start_webhook_stub
+}
+
+teardown() {
+  stop_webhook_stub
+  dokku webhooks:remove --global "http://127.0.0.1:$WEBHOOK_STUB_PORT/global" >/dev/null 2>&1 || true
+  destroy_app
+  global_teardown
+}
+
+# starts a local http server that records each request, responding with a 500 for paths starting with /fail
+start_webhook_stub() {
+  rm -rf "$WEBHOOK_STUB_DIR"
+  mkdir -p "$WEBHOOK_STUB_DIR"
+  cat >"$WEBHOOK_STUB_DIR/server.py" <<PYTHON
+import http.server
+import json
+
+class Handler(http.server.BaseHTTPRequestHandler):
+    def do_POST(self):
+        body = self.rfile.read(int(self.headers.get("Content-Length", 0)))
+        with open("$WEBHOOK_STUB_DIR/requests.jsonl", "a") as f:
+            f.write(json.dumps({
+                "path": self.path,
+                "event": self.headers.get("X-Dokku-Event"),
+                "signature": self.headers.get("X-Dokku-Signature"),
+                "body": body.decode(),
+            }) + "\n")
+        self.send_response(500 if self.path.startswith("/fail") else 200)
+        self.end_headers()
+
+    def log_message(self, format, *args):
+        pass
+
+http.server.HTTPServer(("127.0.0.1", $WEBHOOK_STUB_PORT), Handler).serve_forever()
+PYTHON
+  python3 "$WEBHOOK_STUB_DIR/server.py" &>/dev/null &
+  echo "$!" >"$WEBHOOK_STUB_DIR/pid"
+  sleep 1
+}
+
+stop_webhook_stub() {
+  kill "$(cat "$WEBHOOK_STUB_DIR/pid")" &>/dev/null || true
+  rm -rf "$WEBHOOK_STUB_DIR"
+}
+
+@test "(webhooks) webhooks:help" {
+  run /bin/bash -c "dokku webhooks"
+  echo "output: $output"
+  echo "status: $status"
+  assert_output_contains "Manage outgoing webhooks for app events"
+  help_output="$output"
+
+  run /bin/bash -c "dokku webhooks:help"
+  echo "output: $output"
+  echo "status: $status"
+  assert_output_contains "Manage outgoing webhooks for app events"
+  assert_output "$help_output"
+}
+
+@test "(webhooks) webhooks:add, webhooks:list and webhooks:remove" {
+  run /bin/bash -c "dokku webhooks:add $TEST_APP invalid-url"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "dokku webhooks:add $TEST_APP http://127.0.0.1:$WEBHOOK_STUB_PORT/hook --events deploy.invalid"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "Invalid event deploy.invalid"
+
+  run /bin/bash -c "dokku webhooks:add $TEST_APP http://127.0.0.1:$WEBHOOK_STUB_PORT/hook --events deploy.succeeded,app.destroyed"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "Signing secret"
+
+  run /bin/bash -c "dokku webhooks:add $TEST_APP http://127.0.0.1:$WEBHOOK_STUB_PORT/hook"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+  assert_output_contains "already exists"
+
+  run /bin/bash -c "dokku webhooks:list $TEST_APP --format json | jq -r '.[0].events | join(\",\")'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "app.destroyed,deploy.succeeded"
+
+  run /bin/bash -c "dokku webhooks:list $TEST_APP --format json | jq -r '.[0].secret'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output ""
+
+  run /bin/bash -c "dokku cron:list --global"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "webhooks:deliver"
+
+  run /bin/bash -c "dokku webhooks:remove $TEST_APP http://127.0.0.1:$WEBHOOK_STUB_PORT/missing"
+  echo "output: $output"
+  echo "status: $status"
+  assert_failure
+
+  run /bin/bash -c "dokku webhooks:remove $TEST_APP http://127.0.0.1:$WEBHOOK_STUB_PORT/hook"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku webhooks:list $TEST_APP --format json"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "[]"
+}
+
+@test "(webhooks) deploy events are delivered with a signature" {
+  run /bin/bash -c "dokku webhooks:add $TEST_APP http://127.0.0.1:$WEBHOOK_STUB_PORT/hook --events deploy.started,deploy.succeeded --secret s3cret"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run deploy_app
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "jq -r '.event' $WEBHOOK_STUB_DIR/requests.jsonl | sort -u | xargs"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "deploy.started deploy.succeeded"
+
+  run /bin/bash -c "jq -r 'select(.event == \"deploy.succeeded\") | .body' $WEBHOOK_STUB_DIR/requests.jsonl | jq -r '.app, .deploy_source'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "$TEST_APP
+git-push"
+
+  run /bin/bash -c "jq -r 'select(.event == \"deploy.succeeded\") | .body' $WEBHOOK_STUB_DIR/requests.jsonl | jq -r '.git_rev'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "$(dokku config:get $TEST_APP GIT_REV)"
+
+  run /bin/bash -c "jq -r 'select(.event == \"deploy.succeeded\") | .body' $WEBHOOK_STUB_DIR/requests.jsonl | tr -d '\n' | openssl dgst -sha256 -hmac s3cret | awk '{print \"sha256=\" \$NF}'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  signature="$output"
+
+  run /bin/bash -c "jq -r 'select(.event == \"deploy.succeeded\") | .signature' $WEBHOOK_STUB_DIR/requests.jsonl"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "$signature"
+
+  run /bin/bash -c "dokku webhooks:deliveries $TEST_APP --format json | jq -r '.[0].event, .[0].status_code'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "deploy.succeeded
+200"
+}
+
+@test "(webhooks) failed deliveries are retried" {
+  run /bin/bash -c "dokku webhooks:add --global http://127.0.0.1:$WEBHOOK_STUB_PORT/global --events app.destroyed"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "dokku webhooks:add $TEST_APP http://127.0.0.1:$WEBHOOK_STUB_PORT/fail --events deploy.succeeded"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run deploy_app
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output_contains "Unable to deliver deploy.succeeded webhook"
+
+  run /bin/bash -c "dokku webhooks:deliveries $TEST_APP --format json | jq -r '.[0].attempt, .[0].status_code'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "1
+500"
+
+  run /bin/bash -c "ls /var/lib/dokku/data/webhooks/queue | wc -l"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "1"
+
+  run /bin/bash -c "dokku webhooks:deliver"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "ls /var/lib/dokku/data/webhooks/queue | wc -l"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "1"
+
+  run /bin/bash -c "dokku webhooks:remove $TEST_APP http://127.0.0.1:$WEBHOOK_STUB_PORT/fail"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "ls /var/lib/dokku/data/webhooks/queue | wc -l"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "0"
+
+  run /bin/bash -c "dokku --force apps:destroy $TEST_APP"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+
+  run /bin/bash -c "jq -r '.path + \" \" + .event' $WEBHOOK_STUB_DIR/requests.jsonl | tail -n 1"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "/global app.destroyed"
+
+  run /bin/bash -c "dokku webhooks:deliveries --global --format json | jq -r '.[0].app'"
+  echo "output: $output"
+  echo "status: $status"
+  assert_success
+  assert_output "$TEST_APP"
+
+  create_app
+}